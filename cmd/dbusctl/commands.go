@@ -0,0 +1,467 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mesbrj/dbus-controller/pkg/client"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+func newListCommand(opts *options) *cobra.Command {
+	var activatable bool
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the names on the bus",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.context(cmd)
+			defer cancel()
+
+			if activatable {
+				return listNames(ctx, cmd, opts, c)
+			}
+
+			services, err := c.ListServices(ctx, opts.bus)
+			if err != nil {
+				return err
+			}
+			sort.Strings(services)
+
+			if opts.output == "json" {
+				return printJSON(cmd.OutOrStdout(), services)
+			}
+			rows := make([][]string, len(services))
+			for i, service := range services {
+				rows[i] = []string{service}
+			}
+			return printTable(cmd.OutOrStdout(), []string{"NAME"}, rows)
+		},
+	}
+	cmd.Flags().BoolVar(&activatable, "activatable", false, "Also list the activatable names that are not running")
+	return cmd
+}
+
+// listNames prints the running and activatable names with their state
+func listNames(ctx context.Context, cmd *cobra.Command, opts *options, c *client.Client) error {
+	names, err := c.ListNames(ctx, opts.bus)
+	if err != nil {
+		return err
+	}
+
+	if opts.output == "json" {
+		return printJSON(cmd.OutOrStdout(), names)
+	}
+	rows := make([][]string, len(names))
+	for i, name := range names {
+		state := "running"
+		if !name.Running {
+			state = "activatable"
+		}
+		rows[i] = []string{name.Name, state}
+	}
+	return printTable(cmd.OutOrStdout(), []string{"NAME", "STATE"}, rows)
+}
+
+func newTreeCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "tree SERVICE",
+		Short:             "Show the object tree of a service",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeArgs(opts, completeService),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.context(cmd)
+			defer cancel()
+
+			paths, err := objectTree(ctx, c, opts.bus, args[0])
+			if err != nil {
+				return err
+			}
+
+			if opts.output == "json" {
+				return printJSON(cmd.OutOrStdout(), paths)
+			}
+			for _, line := range treeLines(paths) {
+				fmt.Fprintln(cmd.OutOrStdout(), line)
+			}
+			return nil
+		},
+	}
+}
+
+func newIntrospectCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "introspect SERVICE PATH [INTERFACE]",
+		Short:             "Show the interfaces, methods, properties and signals of an object",
+		Args:              cobra.RangeArgs(2, 3),
+		ValidArgsFunction: completeArgs(opts, completeService, completePath, completeInterface),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.context(cmd)
+			defer cancel()
+
+			result, err := c.IntrospectObject(ctx, opts.bus, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			if result.ParsedData == nil {
+				return fmt.Errorf("no introspection data for %s", args[1])
+			}
+
+			interfaces := make([]model.InterfaceInfo, 0)
+			for _, iface := range result.ParsedData.Interfaces {
+				if len(args) < 3 || iface.Name == args[2] {
+					interfaces = append(interfaces, iface)
+				}
+			}
+			if len(args) == 3 && len(interfaces) == 0 {
+				return fmt.Errorf("interface %s not found on %s", args[2], args[1])
+			}
+
+			if opts.output == "json" {
+				return printJSON(cmd.OutOrStdout(), interfaces)
+			}
+			return printTable(cmd.OutOrStdout(), []string{"NAME", "TYPE", "SIGNATURE", "RESULT/VALUE", "FLAGS"},
+				introspectRows(ctx, c, opts.bus, args[0], args[1], interfaces))
+		},
+	}
+}
+
+func newCallCommand(opts *options) *cobra.Command {
+	var expectReply, autoStart, interactive bool
+	cmd := &cobra.Command{
+		Use:               "call SERVICE PATH INTERFACE METHOD [SIGNATURE [ARGUMENT...]]",
+		Short:             "Call a method",
+		Args:              cobra.MinimumNArgs(4),
+		ValidArgsFunction: completeArgs(opts, completeService, completePath, completeInterface, completeMethod),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var signature string
+			if len(args) > 4 {
+				signature = args[4]
+			}
+
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.context(cmd)
+			defer cancel()
+
+			// The controller converts the arguments following the signature
+			flags := model.CallFlags{NoReply: !expectReply, NoAutoStart: !autoStart, AllowInteractiveAuthorization: interactive}
+			result, err := c.CallMethodTextWithFlags(ctx, opts.bus, args[0], args[1], args[2], args[3], signature, flags, args[min(len(args), 5):])
+			if err != nil {
+				return err
+			}
+
+			if opts.output == "json" {
+				return printJSON(cmd.OutOrStdout(), result.ReturnValues)
+			}
+			if len(result.ReturnValues) > 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), formatValues(result.ReturnValues))
+			}
+			return nil
+		},
+	}
+
+	// Named after the busctl options
+	cmd.Flags().BoolVar(&expectReply, "expect-reply", true, "Wait for the reply of the method call")
+	cmd.Flags().BoolVar(&autoStart, "auto-start", true, "Activate the service if it is not running")
+	cmd.Flags().BoolVar(&interactive, "allow-interactive-authorization", false, "Let the service prompt for authorization")
+
+	return cmd
+}
+
+func newGetPropertyCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "get-property SERVICE PATH INTERFACE PROPERTY...",
+		Short:             "Get the value of properties",
+		Args:              cobra.MinimumNArgs(4),
+		ValidArgsFunction: completeArgs(opts, completeService, completePath, completeInterface, completeProperty),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.context(cmd)
+			defer cancel()
+
+			values := make([]*model.PropertyValue, 0, len(args)-3)
+			for _, name := range args[3:] {
+				value, err := c.GetProperty(ctx, opts.bus, args[0], args[1], args[2], name)
+				if err != nil {
+					return err
+				}
+				values = append(values, value)
+			}
+
+			if opts.output == "json" {
+				return printJSON(cmd.OutOrStdout(), values)
+			}
+			for _, value := range values {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s\n", value.Type, formatValue(value.Value))
+			}
+			return nil
+		},
+	}
+}
+
+func newSetPropertyCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:               "set-property SERVICE PATH INTERFACE PROPERTY SIGNATURE ARGUMENT...",
+		Short:             "Set the value of a property",
+		Args:              cobra.MinimumNArgs(6),
+		ValidArgsFunction: completeArgs(opts, completeService, completePath, completeInterface, completeProperty),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+			ctx, cancel := opts.context(cmd)
+			defer cancel()
+
+			value, err := c.SetPropertyText(ctx, opts.bus, args[0], args[1], args[2], args[3], args[4], args[5:])
+			if err != nil {
+				return err
+			}
+
+			if opts.output == "json" {
+				return printJSON(cmd.OutOrStdout(), value)
+			}
+			return nil
+		},
+	}
+}
+
+func newMonitorCommand(opts *options) *cobra.Command {
+	var matches []string
+	cmd := &cobra.Command{
+		Use:   "monitor [SERVICE...]",
+		Short: "Print the messages on the bus as they are sent",
+		Long: "Print the method calls, returns, errors and signals on the bus, like busctl monitor.\n" +
+			"Only the messages sent from or to the given services are printed, or those selected by\n" +
+			"--match rules; without either, every message is printed.",
+		Args: cobra.ArbitraryArgs,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return completeArgs(opts, completeService)(cmd, nil, toComplete)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.client()
+			if err != nil {
+				return err
+			}
+
+			// The stream runs until interrupted or the server shuts down
+			messages, err := c.Monitor(cmd.Context(), opts.bus, monitorRules(args, matches))
+			if err != nil {
+				return err
+			}
+
+			encoder := json.NewEncoder(cmd.OutOrStdout())
+			for message := range messages {
+				if opts.output == "json" {
+					// One message per line
+					if err := encoder.Encode(message); err != nil {
+						return err
+					}
+					continue
+				}
+				if message.Dropped > 0 {
+					fmt.Fprintf(cmd.ErrOrStderr(), "%d messages dropped\n", message.Dropped)
+				}
+				fmt.Fprintln(cmd.OutOrStdout(), formatMessage(message))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringArrayVar(&matches, "match", nil, "Match rule selecting the messages to print (repeatable)")
+	return cmd
+}
+
+// monitorRules returns the match rules selecting the messages sent from or to
+// the services, followed by the extra rules
+func monitorRules(services, matches []string) []string {
+	rules := make([]string, 0, 2*len(services)+len(matches))
+	for _, service := range services {
+		rules = append(rules, fmt.Sprintf("sender='%s'", service), fmt.Sprintf("destination='%s'", service))
+	}
+	return append(rules, matches...)
+}
+
+// formatMessage renders a monitored message on one line
+func formatMessage(message *model.MonitorMessage) string {
+	parts := []string{message.ReceivedAt.Format(time.RFC3339Nano), message.Type, fmt.Sprintf("#%d", message.Serial)}
+	if message.ReplySerial != 0 {
+		parts = append(parts, fmt.Sprintf("reply to #%d", message.ReplySerial))
+	}
+	parts = append(parts, orDash(message.Sender)+" -> "+orDash(message.Destination))
+	if message.Path != "" {
+		parts = append(parts, message.Path)
+	}
+	switch {
+	case message.ErrorName != "":
+		parts = append(parts, message.ErrorName)
+	case message.Interface != "":
+		parts = append(parts, message.Interface+"."+message.Member)
+	case message.Member != "":
+		parts = append(parts, message.Member)
+	}
+	if len(message.Body) > 0 {
+		parts = append(parts, formatValues(message.Body))
+	}
+	return strings.Join(parts, " ")
+}
+
+// orDash returns s, or "-" when s is empty
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// objectTree returns the object paths of a service, walking its introspection data from the root
+func objectTree(ctx context.Context, c *client.Client, busType, serviceName string) ([]string, error) {
+	paths := make([]string, 0)
+	queue := []string{"/"}
+
+	for len(queue) > 0 {
+		objectPath := queue[0]
+		queue = queue[1:]
+		paths = append(paths, objectPath)
+
+		result, err := c.IntrospectObject(ctx, busType, serviceName, objectPath)
+		if err != nil {
+			if objectPath == "/" {
+				return nil, err
+			}
+			continue
+		}
+		if result.ParsedData == nil {
+			continue
+		}
+		for _, node := range result.ParsedData.Nodes {
+			queue = append(queue, node.Path)
+		}
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// treeLines renders sorted object paths as a tree
+func treeLines(paths []string) []string {
+	lines := make([]string, 0, len(paths))
+	for i, objectPath := range paths {
+		if objectPath == "/" {
+			lines = append(lines, "/")
+			continue
+		}
+
+		// The branch of an ancestor continues down to its next sibling
+		depth := strings.Count(objectPath, "/")
+		var prefix strings.Builder
+		for level := 1; level < depth; level++ {
+			if isLastChild(paths, i, ancestorPath(objectPath, level)) {
+				prefix.WriteString("  ")
+			} else {
+				prefix.WriteString("│ ")
+			}
+		}
+		branch := "├─ "
+		if isLastChild(paths, i, objectPath) {
+			branch = "└─ "
+		}
+		lines = append(lines, prefix.String()+branch+objectPath)
+	}
+	return lines
+}
+
+// isLastChild reports whether no path after paths[i] is a later sibling of
+// node or one of its descendants
+func isLastChild(paths []string, i int, node string) bool {
+	parent := path.Dir(node)
+	level := strings.Count(node, "/")
+	for _, other := range paths[i+1:] {
+		sibling := ancestorPath(other, level)
+		if sibling != "" && sibling != node && path.Dir(sibling) == parent {
+			return false
+		}
+	}
+	return true
+}
+
+// ancestorPath returns the first level elements of an object path,
+// or "" if the path is shallower
+func ancestorPath(objectPath string, level int) string {
+	elements := strings.SplitN(strings.TrimPrefix(objectPath, "/"), "/", level+1)
+	if len(elements) < level {
+		return ""
+	}
+	return "/" + strings.Join(elements[:level], "/")
+}
+
+// introspectRows lists the members of the interfaces in busctl introspect layout
+func introspectRows(ctx context.Context, c *client.Client, busType, serviceName, objectPath string, interfaces []model.InterfaceInfo) [][]string {
+	rows := make([][]string, 0)
+
+	for _, iface := range interfaces {
+		rows = append(rows, []string{iface.Name, "interface", "-", "-", "-"})
+
+		for _, method := range iface.Methods {
+			rows = append(rows, []string{"." + method.Name, "method", argTypes(method.InArgs), argTypes(method.OutArgs), "-"})
+		}
+
+		values := make(map[string]interface{})
+		if len(iface.Properties) > 0 {
+			if properties, err := c.ListProperties(ctx, busType, serviceName, objectPath, iface.Name); err == nil {
+				for _, property := range properties {
+					values[property.Name] = property.Value
+				}
+			}
+		}
+		for _, property := range iface.Properties {
+			flags := "-"
+			if strings.Contains(property.Access, "write") {
+				flags = "writable"
+			}
+			rows = append(rows, []string{"." + property.Name, "property", property.Type, formatValue(values[property.Name]), flags})
+		}
+
+		for _, signal := range iface.Signals {
+			rows = append(rows, []string{"." + signal.Name, "signal", argTypes(signal.Args), "-", "-"})
+		}
+	}
+
+	return rows
+}
+
+// argTypes concatenates the signatures of the arguments
+func argTypes(args []model.ArgumentInfo) string {
+	if len(args) == 0 {
+		return "-"
+	}
+	var signature strings.Builder
+	for _, arg := range args {
+		signature.WriteString(arg.Type)
+	}
+	return signature.String()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+func TestTreeLines(t *testing.T) {
+	paths := []string{"/", "/com", "/com/example", "/com/example/A", "/com/example/A/X", "/com/example/B", "/org", "/org/example"}
+
+	assert.Equal(t, []string{
+		"/",
+		"├─ /com",
+		"│ └─ /com/example",
+		"│   ├─ /com/example/A",
+		"│   │ └─ /com/example/A/X",
+		"│   └─ /com/example/B",
+		"└─ /org",
+		"  └─ /org/example",
+	}, treeLines(paths))
+}
+
+func TestAncestorPath(t *testing.T) {
+	assert.Equal(t, "/com", ancestorPath("/com/example/A", 1))
+	assert.Equal(t, "/com/example", ancestorPath("/com/example/A", 2))
+	assert.Equal(t, "/com/example/A", ancestorPath("/com/example/A", 3))
+	assert.Equal(t, "", ancestorPath("/com", 2))
+}
+
+func TestMonitorRules(t *testing.T) {
+	assert.Equal(t, []string{}, monitorRules(nil, nil))
+	assert.Equal(t, []string{
+		"sender='com.example.HelloWorld'",
+		"destination='com.example.HelloWorld'",
+		"type='error'",
+	}, monitorRules([]string{"com.example.HelloWorld"}, []string{"type='error'"}))
+}
+
+func TestFormatMessage(t *testing.T) {
+	receivedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	assert.Equal(t, `2024-01-02T03:04:05Z method_call #7 :1.5 -> com.example.HelloWorld /com/example/HelloWorld com.example.HelloWorld.Hello "world"`,
+		formatMessage(&model.MonitorMessage{
+			Type: "method_call", Serial: 7, Sender: ":1.5", Destination: "com.example.HelloWorld",
+			Path: "/com/example/HelloWorld", Interface: "com.example.HelloWorld", Member: "Hello",
+			Body: []interface{}{"world"}, ReceivedAt: receivedAt,
+		}))
+	assert.Equal(t, `2024-01-02T03:04:05Z error #9 reply to #7 :1.6 -> :1.5 org.freedesktop.DBus.Error.Failed "failed"`,
+		formatMessage(&model.MonitorMessage{
+			Type: "error", Serial: 9, ReplySerial: 7, Sender: ":1.6", Destination: ":1.5",
+			ErrorName: "org.freedesktop.DBus.Error.Failed", Body: []interface{}{"failed"}, ReceivedAt: receivedAt,
+		}))
+	assert.Equal(t, `2024-01-02T03:04:05Z signal #3 :1.6 -> - /com/example/HelloWorld com.example.HelloWorld.Changed`,
+		formatMessage(&model.MonitorMessage{
+			Type: "signal", Serial: 3, Sender: ":1.6", Path: "/com/example/HelloWorld",
+			Interface: "com.example.HelloWorld", Member: "Changed", ReceivedAt: receivedAt,
+		}))
+}
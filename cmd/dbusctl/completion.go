@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mesbrj/dbus-controller/pkg/client"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// completionTimeout bounds the requests made while completing an argument
+const completionTimeout = 5 * time.Second
+
+// completer returns the candidates of one positional argument given the previous ones
+type completer func(ctx context.Context, c *client.Client, busType string, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+// completeArgs completes each positional argument with the completer at its position
+func completeArgs(opts *options, completers ...completer) func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) >= len(completers) {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		if opts.user {
+			opts.bus = "session"
+		}
+
+		c, err := opts.client()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+		defer cancel()
+
+		return completers[len(args)](ctx, c, opts.bus, args, toComplete)
+	}
+}
+
+func completeService(ctx context.Context, c *client.Client, busType string, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	services, err := c.ListServices(ctx, busType)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return withPrefix(services, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePath completes one level of the object tree at a time
+func completePath(ctx context.Context, c *client.Client, busType string, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	parent := "/"
+	if i := strings.LastIndex(toComplete, "/"); i > 0 {
+		parent = toComplete[:i]
+	}
+
+	result, err := c.IntrospectObject(ctx, busType, args[0], parent)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	candidates := []string{parent}
+	if result.ParsedData != nil {
+		for _, node := range result.ParsedData.Nodes {
+			candidates = append(candidates, node.Path, node.Path+"/")
+		}
+	}
+
+	return withPrefix(candidates, toComplete), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+func completeInterface(ctx context.Context, c *client.Client, busType string, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	interfaces, err := c.ListInterfaces(ctx, busType, args[0], args[1])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return withPrefix(interfaces, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+func completeMethod(ctx context.Context, c *client.Client, busType string, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeMember(ctx, c, busType, args, toComplete, func(iface *model.InterfaceInfo) []string {
+		names := make([]string, len(iface.Methods))
+		for i, method := range iface.Methods {
+			names[i] = method.Name
+		}
+		return names
+	})
+}
+
+func completeProperty(ctx context.Context, c *client.Client, busType string, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return completeMember(ctx, c, busType, args, toComplete, func(iface *model.InterfaceInfo) []string {
+		names := make([]string, len(iface.Properties))
+		for i, property := range iface.Properties {
+			names[i] = property.Name
+		}
+		return names
+	})
+}
+
+func completeMember(ctx context.Context, c *client.Client, busType string, args []string, toComplete string, members func(*model.InterfaceInfo) []string) ([]string, cobra.ShellCompDirective) {
+	iface, err := c.GetInterfaceInfo(ctx, busType, args[0], args[1], args[2])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return withPrefix(members(iface), toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTreeInterface completes the interfaces found anywhere in the object tree
+func completeTreeInterface(ctx context.Context, c *client.Client, busType string, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	interfaces, err := treeInterfaces(ctx, c, busType, args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	names := make([]string, 0, len(interfaces))
+	for name := range interfaces {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return withPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTreeSignal completes the signals of an interface found in the object tree
+func completeTreeSignal(ctx context.Context, c *client.Client, busType string, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	interfaces, err := treeInterfaces(ctx, c, busType, args[0])
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	names := make([]string, 0)
+	if iface, ok := interfaces[args[1]]; ok {
+		for _, signal := range iface.Signals {
+			names = append(names, signal.Name)
+		}
+	}
+
+	return withPrefix(names, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// treeInterfaces returns the interfaces implemented by the objects of a service
+func treeInterfaces(ctx context.Context, c *client.Client, busType, serviceName string) (map[string]model.InterfaceInfo, error) {
+	paths, err := objectTree(ctx, c, busType, serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	interfaces := make(map[string]model.InterfaceInfo)
+	for _, objectPath := range paths {
+		result, err := c.IntrospectObject(ctx, busType, serviceName, objectPath)
+		if err != nil || result.ParsedData == nil {
+			continue
+		}
+		for _, iface := range result.ParsedData.Interfaces {
+			interfaces[iface.Name] = iface
+		}
+	}
+
+	return interfaces, nil
+}
+
+// withPrefix returns the candidates starting with prefix
+func withPrefix(candidates []string, prefix string) []string {
+	matches := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, prefix) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
@@ -0,0 +1,92 @@
+// Command dbusctl is a busctl-style command-line client for the D-Bus Controller API.
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mesbrj/dbus-controller/pkg/client"
+)
+
+// options holds the global flags
+type options struct {
+	url     string
+	bus     string
+	user    bool
+	output  string
+	timeout time.Duration
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := newRootCommand().ExecuteContext(ctx); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	opts := &options{}
+
+	root := &cobra.Command{
+		Use:          "dbusctl",
+		Short:        "Introspect and control D-Bus through a D-Bus Controller",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if opts.user {
+				opts.bus = "session"
+			}
+			if opts.bus != "system" && opts.bus != "session" {
+				return fmt.Errorf("invalid bus type: %s", opts.bus)
+			}
+			if opts.output != "table" && opts.output != "json" {
+				return fmt.Errorf("output must be 'table' or 'json'")
+			}
+			return nil
+		},
+	}
+
+	defaultURL := os.Getenv("DBUSCTL_URL")
+	if defaultURL == "" {
+		defaultURL = "http://localhost:8080"
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&opts.url, "url", defaultURL, "Controller URL (or DBUSCTL_URL)")
+	flags.StringVar(&opts.bus, "bus", "system", "Bus to connect to: system or session")
+	flags.BoolVar(&opts.user, "user", false, "Connect to the session bus (same as --bus session)")
+	flags.StringVarP(&opts.output, "output", "o", "table", "Output format: table or json")
+	flags.DurationVar(&opts.timeout, "timeout", 30*time.Second, "Request timeout")
+
+	_ = root.RegisterFlagCompletionFunc("bus", cobra.FixedCompletions([]string{"system", "session"}, cobra.ShellCompDirectiveNoFileComp))
+	_ = root.RegisterFlagCompletionFunc("output", cobra.FixedCompletions([]string{"table", "json"}, cobra.ShellCompDirectiveNoFileComp))
+
+	root.AddCommand(
+		newListCommand(opts),
+		newTreeCommand(opts),
+		newIntrospectCommand(opts),
+		newCallCommand(opts),
+		newGetPropertyCommand(opts),
+		newSetPropertyCommand(opts),
+		newMonitorCommand(opts),
+	)
+
+	return root
+}
+
+// client creates the API client for the configured controller
+func (o *options) client() (*client.Client, error) {
+	return client.New(o.url, client.WithRetries(1, 200*time.Millisecond))
+}
+
+// context returns the request context bounded by the configured timeout
+func (o *options) context(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(cmd.Context(), o.timeout)
+}
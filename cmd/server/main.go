@@ -1,29 +1,194 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/go-fuego/fuego"
 	"github.com/mesbrj/dbus-controller/internal/api"
+	"github.com/mesbrj/dbus-controller/internal/bridge"
+	"github.com/mesbrj/dbus-controller/internal/config"
+	"github.com/mesbrj/dbus-controller/internal/grpcserver"
+	"github.com/mesbrj/dbus-controller/internal/handler"
+	"github.com/mesbrj/dbus-controller/internal/logging"
+	"github.com/mesbrj/dbus-controller/internal/metrics"
 	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/internal/tracing"
+	"google.golang.org/grpc"
 )
 
 func main() {
+	configPath := flag.String("config", os.Getenv("DBUS_CONTROLLER_CONFIG"), "Path to the YAML configuration file")
+	flag.Parse()
+
+	// Load configuration
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		slog.Error("Failed to load configuration", "error", err)
+		os.Exit(1)
+	}
+
+	// Setup structured logging
+	logger := logging.New(cfg.Log, os.Stderr)
+	slog.SetDefault(logger)
+
+	// Setup OpenTelemetry tracing
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+	if err != nil {
+		slog.Error("Failed to setup tracing", "error", err)
+		os.Exit(1)
+	}
+
+	// Stop on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Create D-Bus service
 	dbusService := service.NewDBusService()
-	defer dbusService.Close()
+	dbusService.SetJobLimits(service.JobLimits{
+		MaxRunning: cfg.Jobs.MaxRunning,
+		MaxQueued:  cfg.Jobs.MaxQueued,
+		Timeout:    cfg.Jobs.Timeout,
+		Retention:  cfg.Jobs.Retention,
+	})
+	dbusService.SetMonitorLimits(service.MonitorLimits{
+		MaxSessions: cfg.Monitor.MaxSessions,
+		Buffer:      cfg.Monitor.Buffer,
+	})
 
 	// Create Fuego server
 	s := fuego.NewServer(
-		fuego.WithAddr(":8080"),
+		fuego.WithAddr(cfg.Server.Addr),
+		fuego.WithLogHandler(logger.Handler()),
+		fuego.WithErrorHandler(handler.ErrorHandler),
 	)
 
+	// Trace context, request IDs and access logs; must be registered before the routes
+	fuego.Use(s, tracing.Middleware, logging.Middleware)
+
 	// Setup routes
 	api.SetupRoutes(s, dbusService)
+	api.SetupHealthRoutes(s, dbusService, cfg.Health.RequiredBuses)
+	if err := api.SetupVirtualRoutes(s, dbusService, cfg.Routes); err != nil {
+		slog.Error("Failed to setup routes", "error", err)
+		dbusService.Close()
+		os.Exit(1)
+	}
+
+	// Emit signals from the identity of the controller only when allowed
+	if cfg.Signals.Emit {
+		api.SetupSignalRoutes(s, dbusService, cfg.Signals.Allow)
+	}
+
+	// Export D-Bus properties as Prometheus metrics
+	if cfg.Metrics.Enabled {
+		exporter := metrics.NewExporter(dbusService, cfg.Metrics)
+		go exporter.Run(ctx)
+		api.SetupMetricsRoutes(s, cfg.Metrics.Path, exporter.Handler())
+	}
+
+	// Claim the exported names and serve their objects from the HTTP backends
+	exports, err := bridge.New(cfg.Exports)
+	if err == nil {
+		err = exports.Start()
+	}
+	if err != nil {
+		slog.Error("Failed to export D-Bus objects", "error", err)
+		dbusService.Close()
+		os.Exit(1)
+	}
+
+	// End signal streams once the server stops accepting requests,
+	// otherwise they would hold the drain open until the deadline
+	s.Server.RegisterOnShutdown(dbusService.CloseStreams)
+
+	// Failures of the servers shut the others down like SIGTERM, then exit with 1
+	exitCode := 0
 
 	// Start server
-	log.Println("Starting D-Bus Controller API on :8080")
-	if err := s.Run(); err != nil {
-		log.Fatal("Server failed to start:", err)
+	slog.Info("Starting D-Bus Controller API", "addr", cfg.Server.Addr)
+	serverErr := make(chan error, 2)
+	go func() {
+		serverErr <- s.Run()
+	}()
+
+	// Serve the same D-Bus service over gRPC on its own port
+	var grpcServer *grpc.Server
+	if cfg.GRPC.Enabled {
+		listener, err := net.Listen("tcp", cfg.GRPC.Addr)
+		if err != nil {
+			slog.Error("gRPC server failed to start", "error", err)
+			exitCode = 1
+			stop()
+		} else {
+			grpcServer = grpcserver.NewGRPCServer(dbusService)
+			slog.Info("Starting D-Bus Controller gRPC API", "addr", listener.Addr().String())
+			go func() {
+				serverErr <- grpcServer.Serve(listener)
+			}()
+		}
+	}
+
+	select {
+	case err := <-serverErr:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("Server failed", "error", err)
+			exitCode = 1
+		}
+	case <-ctx.Done():
+	}
+	stop()
+
+	// Stop accepting requests and drain the in-flight ones
+	slog.Info("Shutting down D-Bus Controller API", "timeout", cfg.Server.ShutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+	defer cancel()
+
+	if err := s.Server.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Requests still in flight at shutdown deadline", "error", err)
+	}
+	if grpcServer != nil {
+		stopGRPC(shutdownCtx, grpcServer)
+	}
+
+	// Release the exported names and cancel the backend requests in flight
+	exports.Close()
+
+	// Abandon the unfinished jobs, remove signal subscriptions and close the buses
+	dbusService.Close()
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		slog.Warn("Failed to flush traces", "error", err)
+	}
+
+	slog.Info("D-Bus Controller API stopped")
+	if exitCode != 0 {
+		cancel()
+		os.Exit(exitCode)
+	}
+}
+
+// stopGRPC drains the calls in flight on the gRPC server, cancelling the
+// remaining ones at the shutdown deadline. Signal streams have already ended
+// with the HTTP server shutdown.
+func stopGRPC(ctx context.Context, grpcServer *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		slog.Warn("gRPC calls still in flight at shutdown deadline")
+		grpcServer.Stop()
 	}
 }
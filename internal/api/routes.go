@@ -1,13 +1,24 @@
 package api
 
 import (
+	"fmt"
+	"net/http"
+
 	"github.com/go-fuego/fuego"
+	"github.com/go-fuego/fuego/option"
+	"github.com/go-fuego/fuego/param"
+	"github.com/mesbrj/dbus-controller/internal/config"
+	"github.com/mesbrj/dbus-controller/internal/graph"
 	"github.com/mesbrj/dbus-controller/internal/handler"
 	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
 )
 
+// objectPathParam declares the path query parameter of the object-scoped routes
+var objectPathParam = option.Query("path", "D-Bus object path", param.Default("/"))
+
 // SetupRoutes configures all API routes
-func SetupRoutes(s *fuego.Server, dbusService service.DBusServiceInterface) {
+func SetupRoutes(s *fuego.Server, dbusService graph.Service) {
 	h := handler.NewHandler(dbusService)
 
 	// Bus management routes
@@ -15,26 +26,209 @@ func SetupRoutes(s *fuego.Server, dbusService service.DBusServiceInterface) {
 	fuego.Get(s, "/buses/{busType}", h.GetBusInfo)
 
 	// Service routes
-	fuego.Get(s, "/buses/{busType}/services", h.ListServices)
+	fuego.Get(s, "/buses/{busType}/services", h.ListServices,
+		option.Query("wait", "Name to wait for before listing the services"),
+		option.Query("timeout", "Longest wait for the name, e.g. 30s; answers 408 once elapsed", param.Default("30s")))
 	fuego.Get(s, "/buses/{busType}/services/{serviceName}", h.GetService)
 
+	// Service activation routes
+	fuego.Post(s, "/buses/{busType}/services/{serviceName}/start", h.StartService,
+		option.Summary("Start an activatable service"),
+		option.Description("Starts the service with StartServiceByName and waits until it owns its name; the reply is success or already_running"))
+	fuego.Patch(s, "/buses/{busType}/activation/environment", h.UpdateActivationEnvironment,
+		option.Summary("Update the activation environment"),
+		option.Description("Adds or replaces the variables of environment in the environment of the services activated by the bus from now on"))
+
+	// Name routes: running and activatable names, and the names owned by the controller
+	fuego.Get(s, "/buses/{busType}/names", h.ListNames,
+		option.Summary("List the names on a bus"),
+		option.Description("Lists the owned names and the activatable names, started on demand by the bus, with their state"))
+	fuego.Post(s, "/buses/{busType}/names/{name}/request", h.RequestName,
+		option.Summary("Request a well-known name"),
+		option.Description("Requests the name for the connection of the controller with the RequestName flags of the body. "+
+			"The reply is primary_owner, in_queue, exists or already_owner; the name is held until released, taken over or the controller stops."))
+	fuego.Post(s, "/buses/{busType}/names/{name}/release", h.ReleaseName,
+		option.Summary("Release a well-known name"),
+		option.Description("Releases the name or leaves its queue; the reply is released, non_existent or not_owner"))
+	fuego.Get(s, "/buses/{busType}/names/{name}/owners", h.ListQueuedOwners,
+		option.Summary("List the queued owners of a name"),
+		option.Description("Lists the unique names of the connections queued for the name, the primary owner first"))
+	fuego.GetStd(s, "/buses/{busType}/names/events", h.StreamNameOwners,
+		option.Query("pattern", "Names to report, with shell wildcards, e.g. com.example.*", param.Default("*")),
+		option.QueryBool("unique", "Also report the unique names of connections", param.Default(false)),
+		option.Summary("Stream name owner changes"),
+		option.Description("Streams the names appearing, disappearing or changing owner on the bus, and the names acquired and lost by the controller, "+
+			"as Server-Sent Events named after the change"))
+
 	// Interface routes
-	fuego.Get(s, "/buses/{busType}/services/{serviceName}/interfaces", h.ListInterfaces)
-	fuego.Get(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}", h.GetInterface)
+	fuego.Get(s, "/buses/{busType}/services/{serviceName}/interfaces", h.ListInterfaces, objectPathParam)
+	fuego.Get(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}", h.GetInterface, objectPathParam)
 
 	// Method routes
-	fuego.Get(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/methods", h.ListMethods)
-	fuego.Post(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/methods/{methodName}/call", h.CallMethod)
+	fuego.Get(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/methods", h.ListMethods, objectPathParam)
+	fuego.Post(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/methods/{methodName}/call", h.CallMethod, objectPathParam,
+		option.Header("Prefer", "respond-async to start the call as a job, like async in the body"),
+		option.Summary("Call a method"),
+		option.Description("Answers the result of the call, or with async or Prefer: respond-async answers 202 with a job as soon as the call is started; "+
+			"its URL is in the Location header and its result is read from /jobs/{jobId}"),
+		// The handler returns any to answer a result or a job; declare both responses
+		option.AddError(http.StatusOK, "Method call result", model.MethodCallResult{}),
+		option.AddError(http.StatusAccepted, "Job of the asynchronous call", model.Job{}))
+	fuego.Post(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/methods/{methodName}/jobs", h.StartJob, objectPathParam,
+		option.Summary("Start an asynchronous method call"),
+		option.Description("Alias of the call route with async set: answers 202 with a job as soon as the call is started; its result is read from /jobs/{jobId}"))
 
 	// Property routes
-	fuego.Get(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/properties", h.ListProperties)
-	fuego.Get(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/properties/{propertyName}", h.GetProperty)
-	fuego.Put(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/properties/{propertyName}", h.SetProperty)
+	fuego.Get(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/properties", h.ListProperties, objectPathParam)
+	fuego.Get(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/properties/{propertyName}", h.GetProperty, objectPathParam)
+	fuego.Put(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/properties/{propertyName}", h.SetProperty, objectPathParam)
+
+	// Object routes with named arguments: /objects/{object path}/{interface}/{method}
+	fuego.Post(s, "/buses/{busType}/services/{serviceName}/objects/{objectRoute...}", h.CallObjectMethod,
+		option.Summary("Call a method with named arguments"),
+		option.Description("The route ends with the object path, the interface and the method, e.g. /objects/com/example/HelloWorld/com.example.HelloWorld/Hello. "+
+			"The body maps argument names to JSON values and the response maps the names of the return values to their values; unnamed arguments are named argN."))
+
+	// JSON-RPC 2.0 method calls
+	fuego.PostStd(s, "/jsonrpc", h.JSONRPC,
+		option.Summary("Call methods over JSON-RPC 2.0"),
+		option.Description("The method is bus/service/object/path/interface.Member with the arguments as params, positional in an array or named in an object, "+
+			"or call with params holding bus, service, path, interface, method and args. Batches are run in order; requests without an id are sent as NoReplyExpected calls."))
+
+	// Batches of method calls and property reads and writes
+	fuego.Post(s, "/batch", h.Batch,
+		option.Summary("Run a batch of operations"),
+		option.Description("Runs call, get and set operations in order, and consecutive operations marked independent in parallel. "+
+			"Path, args and value strings may reference the results of earlier operations as {results.ID} or {results.ID.N}. "+
+			"With stop_on_error the operations following the first failure are skipped; completed operations are not undone."))
+
+	// GraphQL queries and mutations, and subscriptions streamed as Server-Sent Events
+	gh, err := handler.NewGraphQLHandler(dbusService)
+	if err != nil {
+		// The schema is static, an error is a programming error
+		panic(err)
+	}
+	graphQLDescription := "Queries the buses, services, objects and introspection data with current property values, " +
+		"calls methods and sets properties with mutations, and streams signals with the signal subscription as Server-Sent Events"
+	fuego.GetStd(s, "/graphql", gh.Serve, option.Summary("Run a GraphQL query or subscription"), option.Description(graphQLDescription))
+	fuego.PostStd(s, "/graphql", gh.Serve, option.Summary("Run a GraphQL operation"), option.Description(graphQLDescription))
 
 	// Signal routes
-	fuego.Get(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/signals", h.ListSignals)
+	fuego.Get(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/signals", h.ListSignals, objectPathParam)
 	fuego.Post(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/signals/{signalName}/subscribe", h.SubscribeToSignal)
 
+	// Bus monitor and capture, like dbus-monitor and busctl capture
+	fuego.GetStd(s, "/buses/{busType}/monitor", h.StreamMonitor,
+		option.Query("match", "Match rule selecting messages, e.g. type='signal',interface='com.example.HelloWorld'; repeat for several rules"),
+		option.Summary("Monitor the messages of a bus"),
+		option.Description("Streams the method calls, returns, errors and signals on the bus as Server-Sent Events named after the message type, "+
+			"all of them or those selected by match rules. Messages are dropped while the client reads too slowly; the next message counts them."))
+
+	fuego.GetStd(s, "/buses/{busType}/capture", h.StreamCapture,
+		option.Query("match", "Match rule selecting messages; repeat for several rules"),
+		option.Query("duration", "Length of the capture, e.g. 30s, 10m at most", param.Default("30s")),
+		option.Summary("Capture the messages of a bus"),
+		option.Description("Records the messages of the bus, all of them or those selected by match rules, and sends them as they are captured "+
+			"as a pcap file with the DLT_DBUS link type, like busctl capture, to be analyzed with Wireshark. Messages are dropped while the client reads "+
+			"too slowly; the Capture-Messages and Capture-Dropped trailers count them."))
+
+	// Subscription routes
+	fuego.Get(s, "/subscriptions", h.ListSubscriptions)
+	fuego.Delete(s, "/subscriptions/{subscriptionId}", h.Unsubscribe)
+	fuego.GetStd(s, "/subscriptions/{subscriptionId}/events", h.StreamSubscription,
+		option.Summary("Stream subscription signals"),
+		option.Description("Streams the signals of a subscription as Server-Sent Events"))
+
+	// Job routes
+	fuego.Get(s, "/jobs", h.ListJobs)
+	fuego.Get(s, "/jobs/{jobId}", h.GetJob)
+	fuego.Delete(s, "/jobs/{jobId}", h.CancelJob,
+		option.Summary("Remove a job"),
+		option.Description("Removes a job, abandoning its call if it has not finished. The service may still carry out an abandoned call."))
+	fuego.GetStd(s, "/jobs/{jobId}/events", h.StreamJob,
+		option.Summary("Wait for a job"),
+		option.Description("Streams a done event carrying the job as Server-Sent Events once it finishes"))
+
 	// Introspection routes
-	fuego.Get(s, "/buses/{busType}/services/{serviceName}/introspect", h.IntrospectService)
+	fuego.Get(s, "/buses/{busType}/services/{serviceName}/introspect", h.IntrospectService, objectPathParam)
+
+	// Per-service OpenAPI documents
+	fuego.Get(s, "/swagger/services/{serviceName}/openapi.json", h.ServiceOpenAPI,
+		option.Query("bus", "Bus type: system or session", param.Default("system")),
+		option.Query("path", "Root of the documented object tree", param.Default("/")),
+		option.Summary("Get the OpenAPI document of a service"),
+		option.Description("Generates operations for the methods and properties of the interfaces found below the root object"))
+}
+
+// SetupVirtualRoutes registers the configured routes mapped onto D-Bus operations
+func SetupVirtualRoutes(s *fuego.Server, dbusService service.DBusServiceInterface, routes []config.RouteConfig) error {
+	for _, route := range routes {
+		h, err := handler.NewVirtualHandler(dbusService, route)
+		if err != nil {
+			return err
+		}
+
+		summary := fmt.Sprintf("Call %s.%s on %s", route.Interface, route.Call, route.Service)
+		if route.Property != "" {
+			summary = fmt.Sprintf("Get %s.%s on %s", route.Interface, route.Property, route.Service)
+		}
+		options := []func(*fuego.BaseRoute){option.Summary(summary), option.Description(route.Description), option.Tags("Routes")}
+		for _, name := range h.QueryParams() {
+			options = append(options, option.Query(name, "Route parameter"))
+		}
+
+		if err := registerVirtualRoute(s, route, h, options); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// registerVirtualRoute registers a virtual route, reporting the routes it
+// conflicts with as an error rather than the panic of the mux
+func registerVirtualRoute(s *fuego.Server, route config.RouteConfig, h *handler.VirtualHandler, options []func(*fuego.BaseRoute)) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("route %s %s: %v", route.Method, route.Path, r)
+		}
+	}()
+
+	switch route.Method {
+	case http.MethodGet:
+		fuego.Get(s, route.Path, h.Serve, options...)
+	case http.MethodPost:
+		fuego.Post(s, route.Path, h.Serve, options...)
+	case http.MethodPut:
+		fuego.Put(s, route.Path, h.Serve, options...)
+	case http.MethodPatch:
+		fuego.Patch(s, route.Path, h.Serve, options...)
+	case http.MethodDelete:
+		fuego.Delete(s, route.Path, h.Serve, options...)
+	default:
+		return fmt.Errorf("route %s: unsupported method %s", route.Path, route.Method)
+	}
+	return nil
+}
+
+// SetupSignalRoutes registers the signal emission endpoint, allowing the signals matching a rule
+func SetupSignalRoutes(s *fuego.Server, dbusService service.DBusServiceInterface, allow []config.SignalRule) {
+	h := handler.NewSignalEmitter(dbusService, allow)
+
+	fuego.Post(s, "/buses/{busType}/signals", h.EmitSignal,
+		option.Summary("Emit a signal"),
+		option.Description("Emits a signal from the connection of the controller, broadcast or unicast to a destination. "+
+			"Signals not allowed by the configuration are rejected with 403."))
+}
+
+// SetupMetricsRoutes registers the Prometheus metrics endpoint
+func SetupMetricsRoutes(s *fuego.Server, path string, metricsHandler http.Handler) {
+	fuego.GetStd(s, path, metricsHandler.ServeHTTP, option.Hide())
+}
+
+// SetupHealthRoutes registers the liveness and readiness probe endpoints
+func SetupHealthRoutes(s *fuego.Server, dbusService service.DBusServiceInterface, requiredBuses []string) {
+	h := handler.NewHealthHandler(dbusService, requiredBuses)
+
+	fuego.Get(s, "/healthz", h.Healthz, option.Tags("Health"))
+	fuego.Get(s, "/readyz", h.Readyz, option.Tags("Health"))
 }
@@ -3,24 +3,29 @@ package api
 import (
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/go-fuego/fuego"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
-	"github.com/mesbrj/dbus-controller/internal/handler"
+	"github.com/mesbrj/dbus-controller/internal/config"
+	"github.com/mesbrj/dbus-controller/internal/handler/handlertest"
+	"github.com/mesbrj/dbus-controller/pkg/model"
 )
 
 // APIIntegrationTestSuite defines integration tests for the API
 type APIIntegrationTestSuite struct {
 	suite.Suite
 	server      *fuego.Server
-	mockService *handler.MockDBusService
+	mockService *handlertest.MockDBusService
 }
 
 func (suite *APIIntegrationTestSuite) SetupTest() {
-	suite.mockService = new(handler.MockDBusService)
+	suite.mockService = new(handlertest.MockDBusService)
 	suite.server = fuego.NewServer()
 
 	// Setup routes with mock service
@@ -36,7 +41,7 @@ func (suite *APIIntegrationTestSuite) TestAPIRoutes_BusesEndpoint() {
 	req := httptest.NewRequest(http.MethodGet, "/buses", nil)
 	rec := httptest.NewRecorder()
 
-	suite.server.ServeHTTP(rec, req)
+	suite.server.Mux.ServeHTTP(rec, req)
 
 	// The exact status code depends on fuego's implementation
 	// This test ensures the route is registered
@@ -45,16 +50,28 @@ func (suite *APIIntegrationTestSuite) TestAPIRoutes_BusesEndpoint() {
 
 func (suite *APIIntegrationTestSuite) TestAPIRoutes_ServicesEndpoint() {
 	expectedServices := []string{"org.freedesktop.DBus", "org.freedesktop.NetworkManager"}
-	suite.mockService.On("ListServices", "system").Return(expectedServices, nil)
+	suite.mockService.On("ListServices", mock.Anything, "system").Return(expectedServices, nil)
 
 	req := httptest.NewRequest(http.MethodGet, "/buses/system/services", nil)
 	rec := httptest.NewRecorder()
 
-	suite.server.ServeHTTP(rec, req)
+	suite.server.Mux.ServeHTTP(rec, req)
 
 	assert.NotEqual(suite.T(), http.StatusNotFound, rec.Code)
 }
 
+func (suite *APIIntegrationTestSuite) TestAPIRoutes_HealthEndpoints() {
+	SetupHealthRoutes(suite.server, suite.mockService, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	rec := httptest.NewRecorder()
+
+	suite.server.Mux.ServeHTTP(rec, req)
+
+	assert.Equal(suite.T(), http.StatusOK, rec.Code)
+	assert.Contains(suite.T(), rec.Body.String(), `"status":"ok"`)
+}
+
 func TestAPIIntegrationSuite(t *testing.T) {
 	suite.Run(t, new(APIIntegrationTestSuite))
 }
@@ -62,7 +79,7 @@ func TestAPIIntegrationSuite(t *testing.T) {
 // Test route registration
 func TestSetupRoutes(t *testing.T) {
 	server := fuego.NewServer()
-	mockService := new(handler.MockDBusService)
+	mockService := new(handlertest.MockDBusService)
 
 	// This should not panic
 	assert.NotPanics(t, func() {
@@ -99,3 +116,72 @@ func TestRoutePatterns(t *testing.T) {
 		}
 	}
 }
+
+func TestSetupVirtualRoutes(t *testing.T) {
+	server := fuego.NewServer()
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("GetProperty", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld", "PID").
+		Return(&model.PropertyValue{Name: "PID", Value: int32(42)}, nil)
+
+	err := SetupVirtualRoutes(server, mockService, []config.RouteConfig{{
+		Method:    http.MethodGet,
+		Path:      "/api/greeter/pid",
+		Bus:       "session",
+		Service:   "com.example.HelloWorld",
+		Object:    "/",
+		Interface: "com.example.HelloWorld",
+		Property:  "PID",
+	}})
+	assert.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/greeter/pid", nil)
+	rec := httptest.NewRecorder()
+	server.Mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "42", strings.TrimSpace(rec.Body.String()))
+}
+
+func TestSetupVirtualRoutes_InvalidTemplate(t *testing.T) {
+	server := fuego.NewServer()
+
+	err := SetupVirtualRoutes(server, new(handlertest.MockDBusService), []config.RouteConfig{{
+		Method:    http.MethodGet,
+		Path:      "/api/greeter/hello",
+		Bus:       "session",
+		Service:   "com.example.HelloWorld",
+		Object:    "/",
+		Interface: "com.example.HelloWorld",
+		Call:      "Hello",
+		Signature: "s",
+		Args:      []string{"{header.name}"},
+	}})
+
+	assert.Error(t, err)
+}
+
+func TestSetupVirtualRoutes_Conflict(t *testing.T) {
+	server := fuego.NewServer()
+	route := config.RouteConfig{
+		Method:    http.MethodGet,
+		Path:      "/api/greeter/{name}",
+		Bus:       "session",
+		Service:   "com.example.HelloWorld",
+		Object:    "/",
+		Interface: "com.example.HelloWorld",
+		Call:      "Hello",
+		Signature: "s",
+		Args:      []string{"{path.name}"},
+	}
+	other := route
+	other.Path = "/api/greeter/{who}"
+	other.Args = []string{"{path.who}"}
+
+	var err error
+	assert.NotPanics(t, func() {
+		err = SetupVirtualRoutes(server, new(handlertest.MockDBusService), []config.RouteConfig{route, other})
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "route GET /api/greeter/{who}")
+}
@@ -0,0 +1,244 @@
+package bridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mesbrj/dbus-controller/internal/config"
+	"github.com/mesbrj/dbus-controller/internal/service"
+)
+
+const (
+	// SenderHeader carries the unique name of the caller to the backend
+	SenderHeader = "X-DBus-Sender"
+	// ErrorNameHeader lets a backend choose the D-Bus error name of a failure
+	ErrorNameHeader = "X-DBus-Error-Name"
+
+	// maxResponseSize bounds the backend responses read by the bridge
+	maxResponseSize = 4 << 20
+)
+
+// statusErrors maps backend statuses to D-Bus error names; others are Failed
+var statusErrors = map[int]string{
+	http.StatusBadRequest:          "org.freedesktop.DBus.Error.InvalidArgs",
+	http.StatusUnprocessableEntity: "org.freedesktop.DBus.Error.InvalidArgs",
+	http.StatusUnauthorized:        "org.freedesktop.DBus.Error.AccessDenied",
+	http.StatusForbidden:           "org.freedesktop.DBus.Error.AccessDenied",
+	http.StatusNotImplemented:      "org.freedesktop.DBus.Error.NotSupported",
+}
+
+// forwardedMethod returns a method posting its arguments to the backend URL.
+// The request body is a JSON object keyed by the input argument names, and
+// the response an object keyed by the output argument names.
+func (o *object) forwardedMethod(ifaceName string, cfg *config.ExportMethodConfig) *method {
+	m := &method{}
+	for _, arg := range cfg.In {
+		m.in += arg.Type
+	}
+	for _, arg := range cfg.Out {
+		m.out = append(m.out, arg.Type)
+	}
+
+	m.call = func(req *request, args []interface{}) ([]interface{}, error) {
+		ctx, span := o.export.startSpan(req, "bridge.CallMethod", o.path, ifaceName, cfg.Name)
+		defer span.End()
+
+		body := make(map[string]any, len(cfg.In))
+		for i, arg := range cfg.In {
+			body[argName(arg, i)] = service.ToJSONArg(args[i])
+		}
+
+		var response any
+		if err := o.export.do(ctx, http.MethodPost, cfg.URL, req.sender, body, &response); err != nil {
+			return nil, o.export.fail(span, err, "Forwarded method call failed", "path", o.path, "interface", ifaceName, "method", cfg.Name)
+		}
+
+		results := make([]interface{}, len(cfg.Out))
+		if len(cfg.Out) > 0 {
+			object, ok := response.(map[string]any)
+			if !ok {
+				return nil, o.export.fail(span, dbus.MakeFailedError(errors.New("backend response is not a JSON object")),
+					"Forwarded method call failed", "path", o.path, "interface", ifaceName, "method", cfg.Name)
+			}
+			for i, arg := range cfg.Out {
+				name := argName(arg, i)
+				value, err := service.ParseJSONArg(name, arg.Type, object[name])
+				if err != nil {
+					return nil, o.export.fail(span, dbus.MakeFailedError(fmt.Errorf("invalid backend response: %w", err)),
+						"Forwarded method call failed", "path", o.path, "interface", ifaceName, "method", cfg.Name)
+				}
+				results[i] = value
+			}
+		}
+
+		slog.Debug("Forwarded method call", "path", o.path, "interface", ifaceName, "method", cfg.Name, "sender", req.sender)
+		return results, nil
+	}
+
+	return m
+}
+
+// readProperty gets a property value from its backend URL
+func (e *export) readProperty(req *request, objectPath dbus.ObjectPath, ifaceName string, cfg *config.ExportPropertyConfig) (dbus.Variant, error) {
+	ctx, span := e.startSpan(req, "bridge.GetProperty", objectPath, ifaceName, cfg.Name)
+	defer span.End()
+
+	if cfg.Access == "write" {
+		return dbus.Variant{}, e.fail(span, dbus.NewError("org.freedesktop.DBus.Error.AccessDenied", []interface{}{fmt.Sprintf("property %s is write-only", cfg.Name)}),
+			"Property read failed", "path", objectPath, "interface", ifaceName, "property", cfg.Name)
+	}
+
+	var response any
+	if err := e.do(ctx, http.MethodGet, cfg.URL, req.sender, nil, &response); err != nil {
+		return dbus.Variant{}, e.fail(span, err, "Property read failed", "path", objectPath, "interface", ifaceName, "property", cfg.Name)
+	}
+	value, err := service.ParseJSONArg(cfg.Name, cfg.Type, response)
+	if err != nil {
+		return dbus.Variant{}, e.fail(span, dbus.MakeFailedError(fmt.Errorf("invalid backend response: %w", err)),
+			"Property read failed", "path", objectPath, "interface", ifaceName, "property", cfg.Name)
+	}
+	return dbus.MakeVariantWithSignature(value, dbus.ParseSignatureMust(cfg.Type)), nil
+}
+
+// writeProperty puts a property value to its backend URL
+func (e *export) writeProperty(req *request, objectPath dbus.ObjectPath, ifaceName string, cfg *config.ExportPropertyConfig, value dbus.Variant) error {
+	ctx, span := e.startSpan(req, "bridge.SetProperty", objectPath, ifaceName, cfg.Name)
+	defer span.End()
+
+	if err := e.do(ctx, http.MethodPut, cfg.URL, req.sender, service.ToJSONArg(value.Value()), nil); err != nil {
+		return e.fail(span, err, "Property write failed", "path", objectPath, "interface", ifaceName, "property", cfg.Name)
+	}
+	slog.Debug("Property written", "path", objectPath, "interface", ifaceName, "property", cfg.Name, "sender", req.sender)
+	return nil
+}
+
+// startSpan starts the span of a served call, bounded by the export timeout
+func (e *export) startSpan(req *request, name string, objectPath dbus.ObjectPath, ifaceName, member string) (context.Context, trace.Span) {
+	ctx, cancel := context.WithTimeout(e.ctx, e.config.Timeout)
+	ctx, span := tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(
+			attribute.String("dbus.bus", e.config.Bus),
+			attribute.String("dbus.name", e.config.Name),
+			attribute.String("dbus.sender", req.sender),
+			attribute.String("dbus.path", string(objectPath)),
+			attribute.String("dbus.interface", ifaceName),
+			attribute.String("dbus.member", member),
+		))
+	return ctx, cancelingSpan{Span: span, cancel: cancel}
+}
+
+// cancelingSpan releases the timeout of a served call when its span ends
+type cancelingSpan struct {
+	trace.Span
+	cancel context.CancelFunc
+}
+
+// End ends the span and cancels its context
+func (s cancelingSpan) End(options ...trace.SpanEndOption) {
+	s.Span.End(options...)
+	s.cancel()
+}
+
+// fail records a failed call on its span and in the log, returning the error
+func (e *export) fail(span trace.Span, err error, msg string, attrs ...any) error {
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	slog.Warn(msg, append(attrs, "bus", e.config.Bus, "name", e.config.Name, "error", err)...)
+	return err
+}
+
+// do sends a JSON request to a backend and decodes its JSON response into out.
+// Failures are returned as D-Bus errors for the caller.
+func (e *export) do(ctx context.Context, httpMethod, url, sender string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return dbus.MakeFailedError(err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, httpMethod, url, reader)
+	if err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set(SenderHeader, sender)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return dbus.NewError("org.freedesktop.DBus.Error.NoReply", []interface{}{fmt.Sprintf("backend did not reply within %s", e.config.Timeout)})
+		}
+		return dbus.MakeFailedError(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return dbus.MakeFailedError(err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return backendError(resp, data)
+	}
+
+	if out == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	// Numbers are kept as text, as 64-bit integers do not fit a float64
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(out); err != nil {
+		return dbus.MakeFailedError(fmt.Errorf("invalid backend response: %w", err))
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return dbus.MakeFailedError(errors.New("invalid backend response: data after the JSON value"))
+	}
+	return nil
+}
+
+// backendError converts a failed backend response to a D-Bus error. The name
+// comes from the X-DBus-Error-Name header or the status, and the message from
+// a problem detail or the response text.
+func backendError(resp *http.Response, data []byte) *dbus.Error {
+	name := resp.Header.Get(ErrorNameHeader)
+	if !validInterfaceName(name) {
+		var ok bool
+		if name, ok = statusErrors[resp.StatusCode]; !ok {
+			name = "org.freedesktop.DBus.Error.Failed"
+		}
+	}
+
+	message := fmt.Sprintf("backend returned %s", resp.Status)
+	var problem struct {
+		Title  string `json:"title"`
+		Detail string `json:"detail"`
+	}
+	if json.Unmarshal(data, &problem) == nil && (problem.Detail != "" || problem.Title != "") {
+		message = problem.Detail
+		if message == "" {
+			message = problem.Title
+		}
+	} else if text := strings.TrimSpace(string(data)); text != "" && !strings.HasPrefix(text, "{") {
+		message = text
+	}
+
+	return dbus.NewError(name, []interface{}{message})
+}
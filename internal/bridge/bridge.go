@@ -0,0 +1,201 @@
+// Package bridge exports D-Bus objects whose methods and properties are
+// implemented by HTTP backends, the inverse of the method call routes
+package bridge
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+
+	"github.com/mesbrj/dbus-controller/internal/config"
+	"github.com/mesbrj/dbus-controller/internal/service"
+)
+
+var tracer = otel.Tracer("github.com/mesbrj/dbus-controller/internal/bridge")
+
+// Bridge claims the configured well-known names and serves their objects
+type Bridge struct {
+	exports []*export
+	ctx     context.Context // Bounds the backend requests; cancelled by Close
+	cancel  context.CancelFunc
+}
+
+// export is a claimed name with its objects, served on a connection of its own
+type export struct {
+	config  config.ExportConfig
+	objects map[dbus.ObjectPath]*object // Exported objects and their ancestors
+	client  *http.Client
+	ctx     context.Context
+	conn    *dbus.Conn
+}
+
+// New creates a bridge for the configured exports, checking their D-Bus names
+// and types. Nothing is exported until Start.
+func New(exports []config.ExportConfig) (*Bridge, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &Bridge{ctx: ctx, cancel: cancel}
+	client := &http.Client{Transport: otelhttp.NewTransport(http.DefaultTransport)}
+
+	for i, cfg := range exports {
+		e := &export{config: cfg, objects: make(map[dbus.ObjectPath]*object), client: client, ctx: ctx}
+		if err := e.build(); err != nil {
+			cancel()
+			return nil, fmt.Errorf("exports[%d]: %w", i, err)
+		}
+		b.exports = append(b.exports, e)
+	}
+
+	return b, nil
+}
+
+// Start connects to the buses and claims the names. It fails when a name is
+// already owned, releasing the names claimed so far.
+func (b *Bridge) Start() error {
+	for _, e := range b.exports {
+		if err := e.start(); err != nil {
+			b.Close()
+			return fmt.Errorf("failed to export %s on the %s bus: %w", e.config.Name, e.config.Bus, err)
+		}
+		slog.Info("Exported D-Bus objects", "bus", e.config.Bus, "name", e.config.Name, "objects", len(e.config.Objects))
+	}
+	return nil
+}
+
+// Close releases the names, closes their connections and cancels the backend
+// requests in flight
+func (b *Bridge) Close() {
+	b.cancel()
+	for _, e := range b.exports {
+		if e.conn == nil {
+			continue
+		}
+		if _, err := e.conn.ReleaseName(e.config.Name); err != nil {
+			slog.Warn("Failed to release exported name", "bus", e.config.Bus, "name", e.config.Name, "error", err)
+		}
+		e.conn.Close()
+		e.conn = nil
+	}
+}
+
+// start connects to the bus with the export as handler and claims the name
+func (e *export) start() error {
+	connect := dbus.ConnectSessionBus
+	if e.config.Bus == "system" {
+		connect = dbus.ConnectSystemBus
+	}
+	conn, err := connect(dbus.WithHandler(e))
+	if err != nil {
+		return err
+	}
+
+	reply, err := conn.RequestName(e.config.Name, dbus.NameFlagDoNotQueue)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	if reply != dbus.RequestNameReplyPrimaryOwner {
+		conn.Close()
+		return errors.New("name already owned")
+	}
+
+	e.conn = conn
+	return nil
+}
+
+// LookupObject returns the exported object at path; implements dbus.Handler
+func (e *export) LookupObject(objectPath dbus.ObjectPath) (dbus.ServerObject, bool) {
+	o, ok := e.objects[objectPath]
+	return o, ok
+}
+
+// build creates the objects of the export and their ancestors
+func (e *export) build() error {
+	for i, cfg := range e.config.Objects {
+		objectPath := dbus.ObjectPath(cfg.Path)
+		if !objectPath.IsValid() {
+			return fmt.Errorf("objects[%d]: invalid object path: %s", i, cfg.Path)
+		}
+		if o, ok := e.objects[objectPath]; ok && o.config != nil {
+			return fmt.Errorf("objects[%d]: object %s is exported twice", i, cfg.Path)
+		}
+
+		o, err := newObject(e, objectPath, &e.config.Objects[i])
+		if err != nil {
+			return fmt.Errorf("objects[%d].%w", i, err)
+		}
+		if ancestor, ok := e.objects[objectPath]; ok {
+			o.children = ancestor.children
+		}
+		e.objects[objectPath] = o
+
+		// Ancestors list their children for introspection
+		for child := objectPath; child != "/"; {
+			parent := dbus.ObjectPath(path.Dir(string(child)))
+			ancestor, ok := e.objects[parent]
+			if !ok {
+				ancestor = &object{export: e, path: parent}
+				e.objects[parent] = ancestor
+			}
+			ancestor.addChild(path.Base(string(child)))
+			child = parent
+		}
+	}
+
+	for _, o := range e.objects {
+		o.buildIntrospection()
+	}
+	return nil
+}
+
+// addChild records the name of a child node, keeping them sorted
+func (o *object) addChild(name string) {
+	i := sort.SearchStrings(o.children, name)
+	if i < len(o.children) && o.children[i] == name {
+		return
+	}
+	o.children = append(o.children, "")
+	copy(o.children[i+1:], o.children[i:])
+	o.children[i] = name
+}
+
+// validInterfaceName reports whether name is a valid interface name
+func validInterfaceName(name string) bool {
+	elements := strings.Split(name, ".")
+	if len(name) > 255 || len(elements) < 2 {
+		return false
+	}
+	for _, element := range elements {
+		if !validMemberName(element) {
+			return false
+		}
+	}
+	return true
+}
+
+// validMemberName reports whether name is a valid method or property name
+func validMemberName(name string) bool {
+	if name == "" || len(name) > 255 || name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// validType reports whether typ is the signature of one complete type
+func validType(typ string) bool {
+	types, err := service.SplitSignature(typ)
+	return err == nil && len(types) == 1
+}
@@ -0,0 +1,290 @@
+package bridge
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbrj/dbus-controller/internal/config"
+)
+
+const testName = "com.example.BridgeTest"
+
+// backend records the requests of the bridge and answers like an HTTP service
+type backend struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   []string
+	mode     string
+}
+
+func (b *backend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	b.mu.Lock()
+	b.requests = append(b.requests, r)
+	b.bodies = append(b.bodies, string(body))
+	b.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/greet":
+		var args map[string]string
+		_ = json.Unmarshal(body, &args)
+		_ = json.NewEncoder(w).Encode(map[string]any{"greeting": "Hello " + args["name"], "count": 2})
+	case "/mode":
+		if r.Method == http.MethodPut {
+			b.mu.Lock()
+			_ = json.Unmarshal(body, &b.mode)
+			b.mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(b.mode)
+	case "/forbidden":
+		w.Header().Set("Content-Type", "application/problem+json")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"title":"Forbidden","detail":"not allowed for this caller"}`))
+	case "/custom":
+		w.Header().Set(ErrorNameHeader, "com.example.Error.Busy")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("try again later"))
+	case "/slow":
+		time.Sleep(time.Second)
+	case "/counters":
+		_, _ = w.Write([]byte(`{"total": 18446744073709551615, "offset": -9223372036854775807}`))
+	case "/total":
+		_, _ = w.Write([]byte(`18446744073709551614`))
+	case "/trailing":
+		_, _ = w.Write([]byte(`{"total": 1} {}`))
+	}
+}
+
+func (b *backend) last() (*http.Request, string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.requests[len(b.requests)-1], b.bodies[len(b.bodies)-1]
+}
+
+func testExport(url string) config.ExportConfig {
+	return config.ExportConfig{
+		Bus:     "session",
+		Name:    testName,
+		Timeout: 200 * time.Millisecond,
+		Objects: []config.ExportObjectConfig{{
+			Path: "/com/example/Bridge",
+			Interfaces: []config.ExportInterfaceConfig{{
+				Name: "com.example.Bridge",
+				Methods: []config.ExportMethodConfig{
+					{
+						Name: "Greet", URL: url + "/greet",
+						In:  []config.ExportArg{{Name: "name", Type: "s"}},
+						Out: []config.ExportArg{{Name: "greeting", Type: "s"}, {Name: "count", Type: "u"}},
+					},
+					{Name: "Forbidden", URL: url + "/forbidden"},
+					{Name: "Custom", URL: url + "/custom"},
+					{Name: "Slow", URL: url + "/slow"},
+					{
+						Name: "Counters", URL: url + "/counters",
+						Out: []config.ExportArg{{Name: "total", Type: "t"}, {Name: "offset", Type: "x"}},
+					},
+					{Name: "Trailing", URL: url + "/trailing", Out: []config.ExportArg{{Name: "total", Type: "t"}}},
+				},
+				Properties: []config.ExportPropertyConfig{
+					{Name: "Mode", Type: "s", Access: "readwrite", URL: url + "/mode"},
+					{Name: "Total", Type: "t", URL: url + "/total"},
+					{Name: "Secret", Type: "s", Access: "write", URL: url + "/mode"},
+				},
+			}},
+		}},
+	}
+}
+
+func TestNew_Introspection(t *testing.T) {
+	b, err := New([]config.ExportConfig{testExport("http://localhost")})
+	require.NoError(t, err)
+	defer b.Close()
+
+	objects := b.exports[0].objects
+	require.Contains(t, objects, dbus.ObjectPath("/"))
+	assert.Equal(t, []string{"com"}, objects["/"].children)
+	assert.Equal(t, []string{"Bridge"}, objects["/com/example"].children)
+	assert.NotContains(t, objects["/com/example"].xml, "org.freedesktop.DBus.Properties")
+
+	xml := objects["/com/example/Bridge"].xml
+	assert.Contains(t, xml, `<interface name="com.example.Bridge">`)
+	assert.Contains(t, xml, `<arg name="name" type="s" direction="in"></arg>`)
+	assert.Contains(t, xml, `<property name="Mode" type="s" access="readwrite"></property>`)
+	assert.Contains(t, xml, `<interface name="org.freedesktop.DBus.Properties">`)
+}
+
+func TestNew_Invalid(t *testing.T) {
+	tests := map[string]func(*config.ExportConfig){
+		"path":           func(e *config.ExportConfig) { e.Objects[0].Path = "/com/example/" },
+		"interface name": func(e *config.ExportConfig) { e.Objects[0].Interfaces[0].Name = "Bridge" },
+		"method name":    func(e *config.ExportConfig) { e.Objects[0].Interfaces[0].Methods[0].Name = "Greet-Me" },
+		"argument type":  func(e *config.ExportConfig) { e.Objects[0].Interfaces[0].Methods[0].In[0].Type = "ss" },
+		"property type":  func(e *config.ExportConfig) { e.Objects[0].Interfaces[0].Properties[0].Type = "a" },
+		"reserved interface": func(e *config.ExportConfig) {
+			e.Objects[0].Interfaces[0].Name = "org.freedesktop.DBus.Properties"
+		},
+		"duplicate object": func(e *config.ExportConfig) { e.Objects = append(e.Objects, e.Objects[0]) },
+	}
+
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			export := testExport("http://localhost")
+			modify(&export)
+
+			_, err := New([]config.ExportConfig{export})
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "exports[0]: objects[")
+		})
+	}
+}
+
+func TestExport_Uint64(t *testing.T) {
+	httpServer := httptest.NewServer(&backend{})
+	defer httpServer.Close()
+
+	b, err := New([]config.ExportConfig{testExport(httpServer.URL)})
+	require.NoError(t, err)
+	defer b.Close()
+
+	e := b.exports[0]
+	obj := e.objects["/com/example/Bridge"]
+	req := &request{sender: ":1.7"}
+
+	results, err := obj.interfaces["com.example.Bridge"]["Counters"].call(req, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{uint64(18446744073709551615), int64(-9223372036854775807)}, results)
+
+	total, err := e.readProperty(req, obj.path, "com.example.Bridge", obj.properties["com.example.Bridge"]["Total"])
+	require.NoError(t, err)
+	assert.Equal(t, uint64(18446744073709551614), total.Value())
+
+	_, err = obj.interfaces["com.example.Bridge"]["Trailing"].call(req, nil)
+	assert.ErrorContains(t, err, "invalid backend response")
+}
+
+func TestBridge_Integration(t *testing.T) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+	defer conn.Close()
+
+	server := &backend{mode: "auto"}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	b, err := New([]config.ExportConfig{testExport(httpServer.URL)})
+	require.NoError(t, err)
+	require.NoError(t, b.Start())
+	defer b.Close()
+
+	obj := conn.Object(testName, "/com/example/Bridge")
+
+	t.Run("method call", func(t *testing.T) {
+		var greeting string
+		var count uint32
+		err := obj.Call("com.example.Bridge.Greet", 0, "world").Store(&greeting, &count)
+
+		require.NoError(t, err)
+		assert.Equal(t, "Hello world", greeting)
+		assert.Equal(t, uint32(2), count)
+		req, body := server.last()
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, conn.Names()[0], req.Header.Get(SenderHeader))
+		assert.JSONEq(t, `{"name":"world"}`, body)
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		err := obj.Call("com.example.Bridge.Greet", 0, uint32(1)).Err
+
+		var dbusErr dbus.Error
+		require.True(t, errors.As(err, &dbusErr))
+		assert.Equal(t, "org.freedesktop.DBus.Error.InvalidArgs", dbusErr.Name)
+	})
+
+	t.Run("backend errors", func(t *testing.T) {
+		tests := map[string][2]string{
+			"com.example.Bridge.Forbidden": {"org.freedesktop.DBus.Error.AccessDenied", "not allowed for this caller"},
+			"com.example.Bridge.Custom":    {"com.example.Error.Busy", "try again later"},
+			"com.example.Bridge.Slow":      {"org.freedesktop.DBus.Error.NoReply", "backend did not reply"},
+		}
+		for method, expected := range tests {
+			err := obj.Call(method, 0).Err
+
+			var dbusErr dbus.Error
+			require.True(t, errors.As(err, &dbusErr), method)
+			assert.Equal(t, expected[0], dbusErr.Name, method)
+			assert.True(t, strings.HasPrefix(dbusErr.Error(), expected[1]), dbusErr.Error())
+		}
+	})
+
+	t.Run("introspection", func(t *testing.T) {
+		var xml string
+		require.NoError(t, conn.Object(testName, "/com").Call("org.freedesktop.DBus.Introspectable.Introspect", 0).Store(&xml))
+
+		assert.Contains(t, xml, `<node name="example"></node>`)
+	})
+
+	t.Run("properties", func(t *testing.T) {
+		mode, err := obj.GetProperty("com.example.Bridge.Mode")
+		require.NoError(t, err)
+		assert.Equal(t, "auto", mode.Value())
+
+		require.NoError(t, conn.AddMatchSignal(dbus.WithMatchObjectPath("/com/example/Bridge"), dbus.WithMatchInterface("org.freedesktop.DBus.Properties")))
+		signals := make(chan *dbus.Signal, 1)
+		conn.Signal(signals)
+		defer conn.RemoveSignal(signals)
+
+		require.NoError(t, obj.SetProperty("com.example.Bridge.Mode", dbus.MakeVariant("manual")))
+		req, body := server.last()
+		assert.Equal(t, http.MethodPut, req.Method)
+		assert.Equal(t, `"manual"`, body)
+
+		timeout := time.After(time.Second)
+		for received := false; !received; {
+			select {
+			case signal := <-signals:
+				if signal.Name != "org.freedesktop.DBus.Properties.PropertiesChanged" {
+					continue
+				}
+				assert.Equal(t, []interface{}{"com.example.Bridge", map[string]dbus.Variant{"Mode": dbus.MakeVariant("manual")}, []string{}}, signal.Body)
+				received = true
+			case <-timeout:
+				t.Fatal("PropertiesChanged not emitted")
+			}
+		}
+
+		var all map[string]dbus.Variant
+		require.NoError(t, obj.Call("org.freedesktop.DBus.Properties.GetAll", 0, "com.example.Bridge").Store(&all))
+		assert.Equal(t, map[string]dbus.Variant{"Mode": dbus.MakeVariant("manual"), "Total": dbus.MakeVariant(uint64(18446744073709551614))}, all)
+
+		err = obj.SetProperty("com.example.Bridge.Mode", dbus.MakeVariant(uint32(1)))
+		assert.ErrorContains(t, err, "expected a value of type s")
+		_, err = obj.GetProperty("com.example.Bridge.Secret")
+		assert.ErrorContains(t, err, "write-only")
+	})
+
+	t.Run("name already owned", func(t *testing.T) {
+		other, err := New([]config.ExportConfig{testExport(httpServer.URL)})
+		require.NoError(t, err)
+
+		assert.ErrorContains(t, other.Start(), "name already owned")
+	})
+}
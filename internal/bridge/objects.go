@@ -0,0 +1,343 @@
+package bridge
+
+import (
+	"encoding/xml"
+	"fmt"
+	"log/slog"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
+	"github.com/godbus/dbus/v5/prop"
+
+	"github.com/mesbrj/dbus-controller/internal/config"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+const (
+	introspectableInterface = "org.freedesktop.DBus.Introspectable"
+	propertiesInterface     = "org.freedesktop.DBus.Properties"
+)
+
+// peerData describes org.freedesktop.DBus.Peer, answered by godbus on every path
+var peerData = introspect.Interface{
+	Name: "org.freedesktop.DBus.Peer",
+	Methods: []introspect.Method{
+		{Name: "Ping"},
+		{Name: "GetMachineId", Args: []introspect.Arg{{Name: "machine_uuid", Type: "s", Direction: "out"}}},
+	},
+}
+
+// object is an exported object, or an ancestor of one with only child nodes
+type object struct {
+	export     *export
+	path       dbus.ObjectPath
+	config     *config.ExportObjectConfig // nil for ancestors
+	interfaces map[string]methodTable     // Configured interfaces and their forwarded methods
+	properties map[string]map[string]*config.ExportPropertyConfig
+	children   []string
+	xml        string // Introspection data
+}
+
+// methodTable is an interface served by the bridge; implements dbus.Interface
+type methodTable map[string]*method
+
+// LookupMethod returns the method of the interface by name
+func (t methodTable) LookupMethod(name string) (dbus.Method, bool) {
+	m, ok := t[name]
+	return m, ok
+}
+
+// method is a method served by the bridge. Its arguments are checked against
+// the input signature by DecodeArguments, and call receives them with the
+// request they arrived in.
+type method struct {
+	in   string
+	out  []string
+	call func(req *request, args []interface{}) ([]interface{}, error)
+}
+
+// request carries the connection and sender of a method call
+type request struct {
+	conn   *dbus.Conn
+	sender string
+}
+
+// DecodeArguments checks the signature of the call and prepends its request
+// to the arguments; implements dbus.ArgumentDecoder
+func (m *method) DecodeArguments(conn *dbus.Conn, sender string, msg *dbus.Message, args []interface{}) ([]interface{}, error) {
+	var signature string
+	if v, ok := msg.Headers[dbus.FieldSignature]; ok {
+		signature = v.Value().(dbus.Signature).String()
+	}
+	if signature != m.in {
+		return nil, invalidArgs(fmt.Sprintf("expected arguments of signature %q, got %q", m.in, signature))
+	}
+	return append([]interface{}{&request{conn: conn, sender: sender}}, args...), nil
+}
+
+// Call runs the method with the arguments from DecodeArguments
+func (m *method) Call(args ...interface{}) ([]interface{}, error) {
+	return m.call(args[0].(*request), args[1:])
+}
+
+// NumArguments returns the number of input arguments
+func (m *method) NumArguments() int {
+	types, _ := service.SplitSignature(m.in)
+	return len(types)
+}
+
+// NumReturns returns the number of output arguments
+func (m *method) NumReturns() int {
+	return len(m.out)
+}
+
+// ArgumentValue is unused, arguments being decoded by DecodeArguments
+func (m *method) ArgumentValue(int) interface{} {
+	return nil
+}
+
+// ReturnValue is unused, the reply signature being taken from the values
+func (m *method) ReturnValue(int) interface{} {
+	return nil
+}
+
+// newObject creates an exported object from its configuration
+func newObject(e *export, objectPath dbus.ObjectPath, cfg *config.ExportObjectConfig) (*object, error) {
+	o := &object{
+		export:     e,
+		path:       objectPath,
+		config:     cfg,
+		interfaces: make(map[string]methodTable),
+		properties: make(map[string]map[string]*config.ExportPropertyConfig),
+	}
+
+	for i := range cfg.Interfaces {
+		ifaceCfg := &cfg.Interfaces[i]
+		if !validInterfaceName(ifaceCfg.Name) {
+			return nil, fmt.Errorf("interfaces[%d]: invalid interface name: %s", i, ifaceCfg.Name)
+		}
+		if _, ok := o.interfaces[ifaceCfg.Name]; ok || ifaceCfg.Name == introspectableInterface || ifaceCfg.Name == propertiesInterface || ifaceCfg.Name == peerData.Name {
+			return nil, fmt.Errorf("interfaces[%d]: interface %s is already served", i, ifaceCfg.Name)
+		}
+
+		table := make(methodTable)
+		for j := range ifaceCfg.Methods {
+			methodCfg := &ifaceCfg.Methods[j]
+			if err := checkMethod(table, methodCfg); err != nil {
+				return nil, fmt.Errorf("interfaces[%d].methods[%d]: %w", i, j, err)
+			}
+			table[methodCfg.Name] = o.forwardedMethod(ifaceCfg.Name, methodCfg)
+		}
+
+		properties := make(map[string]*config.ExportPropertyConfig)
+		for j := range ifaceCfg.Properties {
+			propCfg := &ifaceCfg.Properties[j]
+			if !validMemberName(propCfg.Name) {
+				return nil, fmt.Errorf("interfaces[%d].properties[%d]: invalid property name: %s", i, j, propCfg.Name)
+			}
+			if _, ok := properties[propCfg.Name]; ok {
+				return nil, fmt.Errorf("interfaces[%d].properties[%d]: duplicate property: %s", i, j, propCfg.Name)
+			}
+			if !validType(propCfg.Type) {
+				return nil, fmt.Errorf("interfaces[%d].properties[%d]: invalid type: %s", i, j, propCfg.Type)
+			}
+			properties[propCfg.Name] = propCfg
+		}
+
+		o.interfaces[ifaceCfg.Name] = table
+		o.properties[ifaceCfg.Name] = properties
+	}
+
+	return o, nil
+}
+
+// checkMethod validates the name and argument types of a method
+func checkMethod(table methodTable, cfg *config.ExportMethodConfig) error {
+	if !validMemberName(cfg.Name) {
+		return fmt.Errorf("invalid method name: %s", cfg.Name)
+	}
+	if _, ok := table[cfg.Name]; ok {
+		return fmt.Errorf("duplicate method: %s", cfg.Name)
+	}
+	for _, args := range [][]config.ExportArg{cfg.In, cfg.Out} {
+		for _, arg := range args {
+			if !validType(arg.Type) {
+				return fmt.Errorf("invalid type of argument %s: %s", arg.Name, arg.Type)
+			}
+		}
+	}
+	return nil
+}
+
+// LookupInterface returns an interface of the object; implements dbus.ServerObject.
+// Calls without an interface look the method up in the configured interfaces.
+func (o *object) LookupInterface(name string) (dbus.Interface, bool) {
+	switch name {
+	case introspectableInterface:
+		return methodTable{"Introspect": {out: []string{"s"}, call: o.introspect}}, true
+	case propertiesInterface:
+		if o.config == nil {
+			return nil, false
+		}
+		return methodTable{
+			"Get":    {in: "ss", out: []string{"v"}, call: o.getProperty},
+			"GetAll": {in: "s", out: []string{"a{sv}"}, call: o.getAllProperties},
+			"Set":    {in: "ssv", call: o.setProperty},
+		}, true
+	case "":
+		merged := make(methodTable)
+		for _, ifaceCfg := range o.configInterfaces() {
+			for methodName, m := range o.interfaces[ifaceCfg.Name] {
+				if _, ok := merged[methodName]; !ok {
+					merged[methodName] = m
+				}
+			}
+		}
+		return merged, true
+	}
+
+	table, ok := o.interfaces[name]
+	return table, ok
+}
+
+// configInterfaces returns the configured interfaces of the object, in order
+func (o *object) configInterfaces() []config.ExportInterfaceConfig {
+	if o.config == nil {
+		return nil
+	}
+	return o.config.Interfaces
+}
+
+// buildIntrospection generates the introspection data from the configuration
+func (o *object) buildIntrospection() {
+	node := introspect.Node{Interfaces: []introspect.Interface{introspect.IntrospectData, peerData}}
+	if o.config != nil {
+		node.Interfaces = append(node.Interfaces, prop.IntrospectData)
+	}
+
+	for _, ifaceCfg := range o.configInterfaces() {
+		iface := introspect.Interface{Name: ifaceCfg.Name}
+		for _, methodCfg := range ifaceCfg.Methods {
+			m := introspect.Method{Name: methodCfg.Name}
+			for i, arg := range methodCfg.In {
+				m.Args = append(m.Args, introspect.Arg{Name: argName(arg, i), Type: arg.Type, Direction: "in"})
+			}
+			for i, arg := range methodCfg.Out {
+				m.Args = append(m.Args, introspect.Arg{Name: argName(arg, i), Type: arg.Type, Direction: "out"})
+			}
+			iface.Methods = append(iface.Methods, m)
+		}
+		for _, propCfg := range ifaceCfg.Properties {
+			iface.Properties = append(iface.Properties, introspect.Property{Name: propCfg.Name, Type: propCfg.Type, Access: propCfg.Access})
+		}
+		node.Interfaces = append(node.Interfaces, iface)
+	}
+
+	for _, child := range o.children {
+		node.Children = append(node.Children, introspect.Node{Name: child})
+	}
+
+	data, err := xml.MarshalIndent(node, "", "  ")
+	if err != nil {
+		// The node only holds strings, so marshalling cannot fail
+		panic(err)
+	}
+	o.xml = introspect.IntrospectDeclarationString + string(data)
+}
+
+// introspect returns the generated introspection data
+func (o *object) introspect(*request, []interface{}) ([]interface{}, error) {
+	return []interface{}{o.xml}, nil
+}
+
+// getProperty reads a property from its backend
+func (o *object) getProperty(req *request, args []interface{}) ([]interface{}, error) {
+	propCfg, err := o.lookupProperty(args[0].(string), args[1].(string))
+	if err != nil {
+		return nil, err
+	}
+	value, err := o.export.readProperty(req, o.path, args[0].(string), propCfg)
+	if err != nil {
+		return nil, err
+	}
+	return []interface{}{value}, nil
+}
+
+// getAllProperties reads the readable properties of an interface from their backends
+func (o *object) getAllProperties(req *request, args []interface{}) ([]interface{}, error) {
+	ifaceName := args[0].(string)
+	properties, ok := o.properties[ifaceName]
+	if !ok {
+		return nil, unknownInterface(ifaceName)
+	}
+
+	values := make(map[string]dbus.Variant, len(properties))
+	for name, propCfg := range properties {
+		if propCfg.Access == "write" {
+			continue
+		}
+		value, err := o.export.readProperty(req, o.path, ifaceName, propCfg)
+		if err != nil {
+			return nil, err
+		}
+		values[name] = value
+	}
+	return []interface{}{values}, nil
+}
+
+// setProperty writes a property to its backend and announces the change
+func (o *object) setProperty(req *request, args []interface{}) ([]interface{}, error) {
+	ifaceName, name, value := args[0].(string), args[1].(string), args[2].(dbus.Variant)
+	propCfg, err := o.lookupProperty(ifaceName, name)
+	if err != nil {
+		return nil, err
+	}
+	if propCfg.Access == "read" {
+		return nil, dbus.NewError("org.freedesktop.DBus.Error.PropertyReadOnly", []interface{}{fmt.Sprintf("property %s is read-only", name)})
+	}
+	if propCfg.Type == "v" {
+		// The variant is the value itself rather than its container
+		value = dbus.MakeVariant(value)
+	} else if value.Signature().String() != propCfg.Type {
+		return nil, invalidArgs(fmt.Sprintf("expected a value of type %s for property %s, got %s", propCfg.Type, name, value.Signature()))
+	}
+
+	if err := o.export.writeProperty(req, o.path, ifaceName, propCfg, value); err != nil {
+		return nil, err
+	}
+
+	changed := map[string]dbus.Variant{name: value}
+	if err := req.conn.Emit(o.path, propertiesInterface+".PropertiesChanged", ifaceName, changed, []string{}); err != nil {
+		slog.Warn("Failed to emit PropertiesChanged", "path", o.path, "interface", ifaceName, "property", name, "error", err)
+	}
+	return nil, nil
+}
+
+// lookupProperty returns the configuration of a property
+func (o *object) lookupProperty(ifaceName, name string) (*config.ExportPropertyConfig, error) {
+	properties, ok := o.properties[ifaceName]
+	if !ok {
+		return nil, unknownInterface(ifaceName)
+	}
+	propCfg, ok := properties[name]
+	if !ok {
+		return nil, dbus.NewError("org.freedesktop.DBus.Error.UnknownProperty", []interface{}{fmt.Sprintf("unknown property: %s", name)})
+	}
+	return propCfg, nil
+}
+
+// argName returns the name of a configured argument, argN for unnamed ones
+func argName(arg config.ExportArg, i int) string {
+	return service.ArgName(model.ArgumentInfo{Name: arg.Name, Type: arg.Type}, i)
+}
+
+// invalidArgs returns an InvalidArgs error with the message
+func invalidArgs(message string) *dbus.Error {
+	return dbus.NewError("org.freedesktop.DBus.Error.InvalidArgs", []interface{}{message})
+}
+
+// unknownInterface returns an UnknownInterface error for the interface
+func unknownInterface(name string) *dbus.Error {
+	return dbus.NewError("org.freedesktop.DBus.Error.UnknownInterface", []interface{}{fmt.Sprintf("unknown interface: %s", name)})
+}
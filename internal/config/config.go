@@ -0,0 +1,486 @@
+package config
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the controller configuration
+type Config struct {
+	Server  ServerConfig   `yaml:"server"`
+	GRPC    GRPCConfig     `yaml:"grpc"`
+	Jobs    JobsConfig     `yaml:"jobs"`
+	Monitor MonitorConfig  `yaml:"monitor"`
+	Log     LogConfig      `yaml:"log"`
+	Tracing TracingConfig  `yaml:"tracing"`
+	Health  HealthConfig   `yaml:"health"`
+	Metrics MetricsConfig  `yaml:"metrics"`
+	Signals SignalsConfig  `yaml:"signals"`
+	Routes  []RouteConfig  `yaml:"routes"`
+	Exports []ExportConfig `yaml:"exports"`
+}
+
+// ServerConfig holds the HTTP server settings
+type ServerConfig struct {
+	Addr            string        `yaml:"addr"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"` // Time allowed to drain in-flight requests
+}
+
+// GRPCConfig holds the gRPC server settings
+type GRPCConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Addr    string `yaml:"addr"` // Listen address, separate from the HTTP server
+}
+
+// JobsConfig holds the limits of the asynchronous method calls
+type JobsConfig struct {
+	MaxRunning int           `yaml:"max_running"` // Calls in flight at once; further jobs wait
+	MaxQueued  int           `yaml:"max_queued"`  // Jobs waiting to run; further jobs are rejected
+	Timeout    time.Duration `yaml:"timeout"`     // Time allowed to a call
+	Retention  time.Duration `yaml:"retention"`   // Time a finished job is kept for its result
+}
+
+// MonitorConfig holds the limits of the bus monitors
+type MonitorConfig struct {
+	MaxSessions int `yaml:"max_sessions"` // Monitors open at once; further monitors are rejected
+	Buffer      int `yaml:"buffer"`       // Messages queued per monitor for a slow client; further messages are dropped
+}
+
+// LogConfig holds the logging settings
+type LogConfig struct {
+	Level  string `yaml:"level"`  // debug, info, warn or error
+	Format string `yaml:"format"` // text or json
+}
+
+// TracingConfig holds the OpenTelemetry settings
+type TracingConfig struct {
+	Enabled     bool    `yaml:"enabled"`
+	Exporter    string  `yaml:"exporter"` // otlp, stdout or file
+	Endpoint    string  `yaml:"endpoint"` // OTLP/HTTP collector host:port
+	Insecure    bool    `yaml:"insecure"`
+	File        string  `yaml:"file"`
+	ServiceName string  `yaml:"service_name"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// HealthConfig holds the readiness probe settings.
+// Without required buses, the controller is ready when any bus responds.
+type HealthConfig struct {
+	RequiredBuses []string `yaml:"required_buses"`
+}
+
+// MetricsConfig holds the D-Bus property exporter settings
+type MetricsConfig struct {
+	Enabled  bool            `yaml:"enabled"`
+	Path     string          `yaml:"path"`
+	Interval time.Duration   `yaml:"interval"`
+	MaxDepth int             `yaml:"max_depth"` // Object tree depth walked when a path glob is used
+	Targets  []MetricsTarget `yaml:"targets"`
+}
+
+// MetricsTarget selects the D-Bus properties exported as gauges.
+// Service, Path, Interface and Property accept path.Match glob patterns.
+type MetricsTarget struct {
+	Bus       string `yaml:"bus"`
+	Service   string `yaml:"service"`
+	Path      string `yaml:"path"`
+	Interface string `yaml:"interface"`
+	Property  string `yaml:"property"`
+	Watch     bool   `yaml:"watch"` // Track PropertiesChanged between polls
+}
+
+// SignalsConfig gates the emission of signals through the REST API. Signals
+// are sent from the bus identity of the controller, which bus policies may
+// trust, so the route is only served when enabled, for the allowed signals.
+type SignalsConfig struct {
+	Emit  bool         `yaml:"emit"`  // Serve the signal emission route
+	Allow []SignalRule `yaml:"allow"` // Signals clients may emit
+}
+
+// SignalRule allows the signals matching all its fields, which accept
+// path.Match glob patterns. An empty destination only matches broadcasts.
+type SignalRule struct {
+	Interface   string `yaml:"interface"`
+	Member      string `yaml:"member"` // Any member when empty
+	Destination string `yaml:"destination"`
+}
+
+// RouteConfig maps a custom HTTP route onto a D-Bus method call or property read.
+// Object, Args and Response values are templates referencing {path.NAME},
+// {query.NAME} and {body.FIELD}; Response values may also reference {result}
+// or {result.N}. A value made of a single reference keeps its JSON type.
+type RouteConfig struct {
+	Method      string            `yaml:"method"` // HTTP method, GET by default
+	Path        string            `yaml:"path"`   // Route pattern, e.g. /api/greeter/{name}
+	Description string            `yaml:"description"`
+	Bus         string            `yaml:"bus"`
+	Service     string            `yaml:"service"`
+	Object      string            `yaml:"object"` // Object path, / by default
+	Interface   string            `yaml:"interface"`
+	Call        string            `yaml:"call"`      // Method to call
+	Property    string            `yaml:"property"`  // Property to read
+	Signature   string            `yaml:"signature"` // Types of the method arguments
+	Args        []string          `yaml:"args"`
+	Response    map[string]string `yaml:"response"` // Fields of the JSON response; the raw result by default
+}
+
+// ExportConfig publishes D-Bus objects under a well-known name claimed by the
+// controller. Their methods and properties are implemented by HTTP backends.
+type ExportConfig struct {
+	Bus     string               `yaml:"bus"`
+	Name    string               `yaml:"name"`    // Well-known name claimed on the bus
+	Timeout time.Duration        `yaml:"timeout"` // Time allowed to a backend request, 25s by default
+	Objects []ExportObjectConfig `yaml:"objects"`
+}
+
+// ExportObjectConfig is an exported object and its interfaces
+type ExportObjectConfig struct {
+	Path       string                  `yaml:"path"`
+	Interfaces []ExportInterfaceConfig `yaml:"interfaces"`
+}
+
+// ExportInterfaceConfig is an interface of an exported object
+type ExportInterfaceConfig struct {
+	Name       string                 `yaml:"name"`
+	Methods    []ExportMethodConfig   `yaml:"methods"`
+	Properties []ExportPropertyConfig `yaml:"properties"`
+}
+
+// ExportMethodConfig is a method forwarded to a backend: its arguments are
+// posted as a JSON object keyed by their names, and the backend answers with
+// the return values keyed the same way
+type ExportMethodConfig struct {
+	Name string      `yaml:"name"`
+	URL  string      `yaml:"url"`
+	In   []ExportArg `yaml:"in"`
+	Out  []ExportArg `yaml:"out"`
+}
+
+// ExportArg is an argument of an exported method
+type ExportArg struct {
+	Name string `yaml:"name"`
+	Type string `yaml:"type"` // D-Bus signature of one complete type
+}
+
+// ExportPropertyConfig is a property backed by a URL, read with GET and
+// written with PUT of its JSON value
+type ExportPropertyConfig struct {
+	Name   string `yaml:"name"`
+	Type   string `yaml:"type"`
+	Access string `yaml:"access"` // read, write or readwrite; read by default
+	URL    string `yaml:"url"`
+}
+
+// reservedPrefixes are the path prefixes of the built-in routes
+var reservedPrefixes = []string{"/buses", "/subscriptions", "/jobs", "/batch", "/jsonrpc", "/graphql", "/swagger", "/healthz", "/readyz"}
+
+// Default returns the configuration used when no file is given
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:            ":8080",
+			ShutdownTimeout: 15 * time.Second,
+		},
+		GRPC: GRPCConfig{
+			Addr: ":9090",
+		},
+		Jobs: JobsConfig{
+			MaxRunning: 4,
+			MaxQueued:  64,
+			Timeout:    time.Hour,
+			Retention:  15 * time.Minute,
+		},
+		Monitor: MonitorConfig{
+			MaxSessions: 4,
+			Buffer:      256,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+		Tracing: TracingConfig{
+			Exporter:    "otlp",
+			Endpoint:    "localhost:4318",
+			Insecure:    true,
+			ServiceName: "dbus-controller",
+			SampleRatio: 1,
+		},
+		Metrics: MetricsConfig{
+			Path:     "/metrics",
+			Interval: 15 * time.Second,
+			MaxDepth: 8,
+		},
+	}
+}
+
+// Load reads the configuration file at path on top of the defaults.
+// An empty path returns the defaults.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// Validate checks the configuration for invalid values
+func (c *Config) Validate() error {
+	if c.Server.Addr == "" {
+		return fmt.Errorf("server.addr must not be empty")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		return fmt.Errorf("server.shutdown_timeout must be positive")
+	}
+
+	if c.GRPC.Enabled {
+		if c.GRPC.Addr == "" {
+			return fmt.Errorf("grpc.addr must not be empty")
+		}
+		if c.GRPC.Addr == c.Server.Addr {
+			return fmt.Errorf("grpc.addr must differ from server.addr")
+		}
+	}
+
+	if c.Jobs.MaxRunning <= 0 {
+		return fmt.Errorf("jobs.max_running must be positive")
+	}
+	if c.Jobs.MaxQueued < 0 {
+		return fmt.Errorf("jobs.max_queued must not be negative")
+	}
+	if c.Jobs.Timeout <= 0 || c.Jobs.Retention <= 0 {
+		return fmt.Errorf("jobs.timeout and jobs.retention must be positive")
+	}
+
+	if c.Monitor.MaxSessions <= 0 || c.Monitor.Buffer <= 0 {
+		return fmt.Errorf("monitor.max_sessions and monitor.buffer must be positive")
+	}
+
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.Log.Level)); err != nil {
+		return fmt.Errorf("log.level: invalid level: %s", c.Log.Level)
+	}
+	if c.Log.Format != "text" && c.Log.Format != "json" {
+		return fmt.Errorf("log.format must be 'text' or 'json'")
+	}
+
+	if c.Tracing.Enabled {
+		switch c.Tracing.Exporter {
+		case "otlp", "stdout":
+		case "file":
+			if c.Tracing.File == "" {
+				return fmt.Errorf("tracing.file is required with the file exporter")
+			}
+		default:
+			return fmt.Errorf("tracing.exporter must be 'otlp', 'stdout' or 'file'")
+		}
+		if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+			return fmt.Errorf("tracing.sample_ratio must be between 0 and 1")
+		}
+	}
+
+	for _, busType := range c.Health.RequiredBuses {
+		if !validBusType(busType) {
+			return fmt.Errorf("health.required_buses: invalid bus type: %s", busType)
+		}
+	}
+
+	if c.Metrics.Enabled {
+		if c.Metrics.Interval <= 0 {
+			return fmt.Errorf("metrics.interval must be positive")
+		}
+		for i := range c.Metrics.Targets {
+			target := &c.Metrics.Targets[i]
+			if !validBusType(target.Bus) {
+				return fmt.Errorf("metrics.targets[%d]: invalid bus type: %s", i, target.Bus)
+			}
+			if target.Service == "" || target.Interface == "" {
+				return fmt.Errorf("metrics.targets[%d]: service and interface are required", i)
+			}
+			if target.Path == "" {
+				target.Path = "/"
+			}
+			if target.Property == "" {
+				target.Property = "*"
+			}
+		}
+	}
+
+	if c.Signals.Emit {
+		if len(c.Signals.Allow) == 0 {
+			return fmt.Errorf("signals.allow must not be empty when signals.emit is enabled")
+		}
+		for i, rule := range c.Signals.Allow {
+			if err := validateSignalRule(rule); err != nil {
+				return fmt.Errorf("signals.allow[%d]: %w", i, err)
+			}
+		}
+	}
+
+	routes := make(map[string]int)
+	for i := range c.Routes {
+		route := &c.Routes[i]
+		if err := c.validateRoute(route); err != nil {
+			return fmt.Errorf("routes[%d]: %w", i, err)
+		}
+		key := route.Method + " " + route.Path
+		if first, ok := routes[key]; ok {
+			return fmt.Errorf("routes[%d]: route %s is already defined by routes[%d]", i, key, first)
+		}
+		routes[key] = i
+	}
+
+	names := make(map[string]bool)
+	for i := range c.Exports {
+		export := &c.Exports[i]
+		if err := validateExport(export); err != nil {
+			return fmt.Errorf("exports[%d]: %w", i, err)
+		}
+		key := export.Bus + " " + export.Name
+		if names[key] {
+			return fmt.Errorf("exports[%d]: name %s is exported twice on the %s bus", i, export.Name, export.Bus)
+		}
+		names[key] = true
+	}
+
+	return nil
+}
+
+// validateRoute checks a virtual route and fills in its defaults
+func (c *Config) validateRoute(route *RouteConfig) error {
+	if route.Method == "" {
+		route.Method = http.MethodGet
+	}
+	route.Method = strings.ToUpper(route.Method)
+	switch route.Method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return fmt.Errorf("unsupported method: %s", route.Method)
+	}
+
+	if !strings.HasPrefix(route.Path, "/") {
+		return fmt.Errorf("path must start with /: %q", route.Path)
+	}
+	reserved := reservedPrefixes
+	if c.Metrics.Enabled {
+		reserved = append(reserved, c.Metrics.Path)
+	}
+	for _, prefix := range reserved {
+		if route.Path == prefix || strings.HasPrefix(route.Path, prefix+"/") {
+			return fmt.Errorf("path %s conflicts with the built-in %s routes", route.Path, prefix)
+		}
+	}
+
+	if !validBusType(route.Bus) {
+		return fmt.Errorf("invalid bus type: %s", route.Bus)
+	}
+	if route.Service == "" || route.Interface == "" {
+		return fmt.Errorf("service and interface are required")
+	}
+	if (route.Call == "") == (route.Property == "") {
+		return fmt.Errorf("exactly one of call and property is required")
+	}
+	if route.Property != "" && len(route.Args) > 0 {
+		return fmt.Errorf("args are only valid with call")
+	}
+	if route.Object == "" {
+		route.Object = "/"
+	}
+
+	return nil
+}
+
+// validateSignalRule checks the patterns of a signal rule
+func validateSignalRule(rule SignalRule) error {
+	if rule.Interface == "" {
+		return fmt.Errorf("interface is required")
+	}
+	for _, pattern := range []string{rule.Interface, rule.Member, rule.Destination} {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid pattern: %q", pattern)
+		}
+	}
+	return nil
+}
+
+// validateExport checks an exported name and fills in its defaults. D-Bus
+// names and types are checked when the objects are exported.
+func validateExport(export *ExportConfig) error {
+	if !validBusType(export.Bus) {
+		return fmt.Errorf("invalid bus type: %s", export.Bus)
+	}
+	if export.Name == "" || strings.HasPrefix(export.Name, ":") {
+		return fmt.Errorf("name must be a well-known bus name")
+	}
+	if export.Timeout == 0 {
+		export.Timeout = 25 * time.Second
+	}
+	if export.Timeout < 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+	if len(export.Objects) == 0 {
+		return fmt.Errorf("objects must not be empty")
+	}
+
+	for i := range export.Objects {
+		object := &export.Objects[i]
+		if object.Path == "" {
+			return fmt.Errorf("objects[%d]: path is required", i)
+		}
+		for j := range object.Interfaces {
+			iface := &object.Interfaces[j]
+			if iface.Name == "" {
+				return fmt.Errorf("objects[%d].interfaces[%d]: name is required", i, j)
+			}
+			for k, method := range iface.Methods {
+				if method.Name == "" || !validURL(method.URL) {
+					return fmt.Errorf("objects[%d].interfaces[%d].methods[%d]: name and an http or https url are required", i, j, k)
+				}
+			}
+			for k := range iface.Properties {
+				property := &iface.Properties[k]
+				if property.Name == "" || property.Type == "" || !validURL(property.URL) {
+					return fmt.Errorf("objects[%d].interfaces[%d].properties[%d]: name, type and an http or https url are required", i, j, k)
+				}
+				if property.Access == "" {
+					property.Access = "read"
+				}
+				if property.Access != "read" && property.Access != "write" && property.Access != "readwrite" {
+					return fmt.Errorf("objects[%d].interfaces[%d].properties[%d]: access must be 'read', 'write' or 'readwrite'", i, j, k)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func validURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func validBusType(busType string) bool {
+	return busType == "system" || busType == "session"
+}
@@ -0,0 +1,388 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	cfg, err := Load("")
+
+	assert.NoError(t, err)
+	assert.Equal(t, ":8080", cfg.Server.Addr)
+	assert.Equal(t, 15*time.Second, cfg.Server.ShutdownTimeout)
+	assert.False(t, cfg.GRPC.Enabled)
+	assert.Equal(t, ":9090", cfg.GRPC.Addr)
+	assert.Equal(t, 4, cfg.Jobs.MaxRunning)
+	assert.Equal(t, time.Hour, cfg.Jobs.Timeout)
+	assert.Equal(t, 4, cfg.Monitor.MaxSessions)
+	assert.Equal(t, 256, cfg.Monitor.Buffer)
+	assert.Equal(t, "info", cfg.Log.Level)
+	assert.Equal(t, "text", cfg.Log.Format)
+	assert.False(t, cfg.Metrics.Enabled)
+	assert.Equal(t, "/metrics", cfg.Metrics.Path)
+	assert.Equal(t, 15*time.Second, cfg.Metrics.Interval)
+	assert.False(t, cfg.Signals.Emit)
+}
+
+func TestLoad_MetricsTargets(t *testing.T) {
+	path := writeConfig(t, `
+server:
+  addr: ":9090"
+metrics:
+  enabled: true
+  interval: 30s
+  targets:
+    - bus: session
+      service: com.example.*
+      interface: com.example.Battery
+      watch: true
+`)
+
+	cfg, err := Load(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, ":9090", cfg.Server.Addr)
+	assert.Equal(t, 30*time.Second, cfg.Metrics.Interval)
+	assert.Len(t, cfg.Metrics.Targets, 1)
+
+	target := cfg.Metrics.Targets[0]
+	assert.Equal(t, "session", target.Bus)
+	assert.Equal(t, "com.example.*", target.Service)
+	assert.Equal(t, "/", target.Path)
+	assert.Equal(t, "*", target.Property)
+	assert.True(t, target.Watch)
+}
+
+func TestLoad_InvalidTarget(t *testing.T) {
+	path := writeConfig(t, `
+metrics:
+  enabled: true
+  targets:
+    - bus: invalid
+      service: com.example.Service
+      interface: com.example.Service
+`)
+
+	cfg, err := Load(path)
+
+	assert.Nil(t, cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid bus type")
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.yaml"))
+
+	assert.Nil(t, cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to read config file")
+}
+
+func TestLoad_InvalidLogFormat(t *testing.T) {
+	path := writeConfig(t, `
+log:
+  level: debug
+  format: xml
+`)
+
+	cfg, err := Load(path)
+
+	assert.Nil(t, cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "log.format")
+}
+
+func TestLoad_GRPCAddrConflict(t *testing.T) {
+	path := writeConfig(t, `
+server:
+  addr: ":8080"
+grpc:
+  enabled: true
+  addr: ":8080"
+`)
+
+	cfg, err := Load(path)
+
+	assert.Nil(t, cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "grpc.addr")
+}
+
+func TestLoad_InvalidJobs(t *testing.T) {
+	path := writeConfig(t, `
+jobs:
+  max_running: 0
+`)
+
+	cfg, err := Load(path)
+
+	assert.Nil(t, cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "jobs.max_running")
+}
+
+func TestLoad_InvalidMonitor(t *testing.T) {
+	path := writeConfig(t, `
+monitor:
+  buffer: 0
+`)
+
+	cfg, err := Load(path)
+
+	assert.Nil(t, cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "monitor.buffer")
+}
+
+func TestLoad_TracingFileExporterRequiresFile(t *testing.T) {
+	path := writeConfig(t, `
+tracing:
+  enabled: true
+  exporter: file
+`)
+
+	cfg, err := Load(path)
+
+	assert.Nil(t, cfg)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "tracing.file")
+}
+
+func TestLoad_Signals(t *testing.T) {
+	path := writeConfig(t, `
+signals:
+  emit: true
+  allow:
+    - interface: com.example.Door
+    - interface: com.example.Alarm
+      member: Raised
+      destination: ":1.*"
+`)
+
+	cfg, err := Load(path)
+
+	require.NoError(t, err)
+	assert.True(t, cfg.Signals.Emit)
+	assert.Equal(t, []SignalRule{
+		{Interface: "com.example.Door"},
+		{Interface: "com.example.Alarm", Member: "Raised", Destination: ":1.*"},
+	}, cfg.Signals.Allow)
+}
+
+func TestLoad_InvalidSignals(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		message string
+	}{
+		{"empty allow list", "signals:\n  emit: true\n", "signals.allow must not be empty"},
+		{"missing interface", "signals:\n  emit: true\n  allow:\n    - member: Opened\n", "signals.allow[0]: interface is required"},
+		{"invalid pattern", "signals:\n  emit: true\n  allow:\n    - interface: \"com.[example\"\n", "signals.allow[0]: invalid pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load(writeConfig(t, tt.content))
+
+			assert.Nil(t, cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.message)
+		})
+	}
+}
+
+func TestLoad_Routes(t *testing.T) {
+	path := writeConfig(t, `
+routes:
+  - path: /api/greeter/hello
+    bus: session
+    service: com.example.HelloWorld
+    interface: com.example.HelloWorld
+    call: Hello
+    signature: s
+    args: ["{query.name}"]
+  - method: put
+    path: /api/greeter/pid
+    bus: session
+    service: com.example.HelloWorld
+    object: /com/example/HelloWorld
+    interface: com.example.HelloWorld
+    property: PID
+`)
+
+	cfg, err := Load(path)
+
+	require.NoError(t, err)
+	require.Len(t, cfg.Routes, 2)
+	assert.Equal(t, "GET", cfg.Routes[0].Method)
+	assert.Equal(t, "/", cfg.Routes[0].Object)
+	assert.Equal(t, []string{"{query.name}"}, cfg.Routes[0].Args)
+	assert.Equal(t, "PUT", cfg.Routes[1].Method)
+	assert.Equal(t, "/com/example/HelloWorld", cfg.Routes[1].Object)
+}
+
+func TestLoad_InvalidRoute(t *testing.T) {
+	tests := map[string]string{
+		"reserved path": `
+routes:
+  - path: /buses/custom
+    bus: session
+    service: com.example.HelloWorld
+    interface: com.example.HelloWorld
+    call: Hello
+`,
+		"call and property": `
+routes:
+  - path: /api/hello
+    bus: session
+    service: com.example.HelloWorld
+    interface: com.example.HelloWorld
+    call: Hello
+    property: Data
+`,
+		"invalid bus": `
+routes:
+  - path: /api/hello
+    bus: user
+    service: com.example.HelloWorld
+    interface: com.example.HelloWorld
+    call: Hello
+`,
+		"unsupported method": `
+routes:
+  - method: HEAD
+    path: /api/hello
+    bus: session
+    service: com.example.HelloWorld
+    interface: com.example.HelloWorld
+    call: Hello
+`,
+		"duplicate route": `
+routes:
+  - path: /api/hello
+    bus: session
+    service: com.example.HelloWorld
+    interface: com.example.HelloWorld
+    call: Hello
+  - method: get
+    path: /api/hello
+    bus: session
+    service: com.example.HelloWorld
+    interface: com.example.HelloWorld
+    property: Data
+`,
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, content))
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "routes[0]")
+		})
+	}
+}
+
+func TestLoad_Exports(t *testing.T) {
+	path := writeConfig(t, `
+exports:
+  - bus: session
+    name: com.example.Bridge
+    objects:
+      - path: /com/example/Bridge
+        interfaces:
+          - name: com.example.Bridge
+            methods:
+              - name: Greet
+                url: http://localhost:8000/greet
+                in: [{name: name, type: s}]
+                out: [{name: greeting, type: s}]
+            properties:
+              - name: Mode
+                type: s
+                url: http://localhost:8000/mode
+`)
+
+	cfg, err := Load(path)
+
+	require.NoError(t, err)
+	require.Len(t, cfg.Exports, 1)
+	assert.Equal(t, 25*time.Second, cfg.Exports[0].Timeout)
+	iface := cfg.Exports[0].Objects[0].Interfaces[0]
+	assert.Equal(t, []ExportArg{{Name: "name", Type: "s"}}, iface.Methods[0].In)
+	assert.Equal(t, "read", iface.Properties[0].Access)
+}
+
+func TestLoad_InvalidExport(t *testing.T) {
+	tests := map[string]string{
+		"unique name": `
+exports:
+  - bus: session
+    name: ":1.42"
+    objects:
+      - path: /
+`,
+		"no objects": `
+exports:
+  - bus: session
+    name: com.example.Bridge
+`,
+		"method without url": `
+exports:
+  - bus: session
+    name: com.example.Bridge
+    objects:
+      - path: /
+        interfaces:
+          - name: com.example.Bridge
+            methods:
+              - name: Greet
+`,
+		"invalid access": `
+exports:
+  - bus: session
+    name: com.example.Bridge
+    objects:
+      - path: /
+        interfaces:
+          - name: com.example.Bridge
+            properties:
+              - name: Mode
+                type: s
+                url: http://localhost:8000/mode
+                access: none
+`,
+		"duplicate name": `
+exports:
+  - bus: session
+    name: com.example.Bridge
+    objects:
+      - path: /
+  - bus: session
+    name: com.example.Bridge
+    objects:
+      - path: /other
+`,
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, content))
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "exports[")
+		})
+	}
+}
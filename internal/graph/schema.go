@@ -0,0 +1,689 @@
+// Package graph exposes the D-Bus object model as a GraphQL schema: buses,
+// services, objects and the interfaces, methods, properties and signals of
+// their introspection data. Property values are read when they are selected.
+// Mutations call methods and set properties, and a subscription streams the
+// signals of a service.
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// Service provides the D-Bus operations resolving the schema. Signals are
+// streamed without a subscription, which the client SDK does not mirror.
+type Service interface {
+	service.DBusServiceInterface
+	StreamSignals(ctx context.Context, busType string, match service.SignalMatch) (<-chan *model.SignalEvent, error)
+}
+
+// Ensure DBusService resolves the schema
+var _ Service = (*service.DBusService)(nil)
+
+// maxObjects bounds the number of objects returned by the objects field of a service
+const maxObjects = 512
+
+// buses are the bus types of the controller
+var buses = []model.BusInfo{
+	{Type: "system", Description: "System D-Bus"},
+	{Type: "session", Description: "Session D-Bus"},
+}
+
+// serviceNode is a service on a bus
+type serviceNode struct {
+	Bus  string
+	Name string
+}
+
+// objectNode is an introspected object of a service
+type objectNode struct {
+	Bus     string
+	Service string
+	Path    string
+	data    *model.ParsedIntrospection
+}
+
+// interfaceNode is an interface of an object
+type interfaceNode struct {
+	object *objectNode
+	info   model.InterfaceInfo
+}
+
+// propertyNode is a property of an interface
+type propertyNode struct {
+	iface *interfaceNode
+	info  model.PropertyInfo
+}
+
+// annotation is a name and value pair of introspection annotations
+type annotation struct {
+	Name  string
+	Value string
+}
+
+// jsonScalar carries D-Bus values and method arguments as JSON values
+var jsonScalar = graphql.NewScalar(graphql.ScalarConfig{
+	Name:         "JSON",
+	Description:  "A JSON value. D-Bus variants are replaced by their values.",
+	Serialize:    plainValue,
+	ParseValue:   func(value interface{}) interface{} { return value },
+	ParseLiteral: literalValue,
+})
+
+// plainValue replaces the variants and signatures of a D-Bus value, which have no JSON encoding
+func plainValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, bool, string, []byte:
+		return v
+	case dbus.Variant:
+		return plainValue(v.Value())
+	case dbus.Signature:
+		return v.String()
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			list[i] = plainValue(v.Index(i).Interface())
+		}
+		return list
+	case reflect.Map:
+		object := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			object[fmt.Sprint(iter.Key().Interface())] = plainValue(iter.Value().Interface())
+		}
+		return object
+	default:
+		return value
+	}
+}
+
+// literalValue converts a literal to the value decoded from the equivalent JSON
+func literalValue(value ast.Value) interface{} {
+	switch v := value.(type) {
+	case *ast.StringValue:
+		return v.Value
+	case *ast.BooleanValue:
+		return v.Value
+	case *ast.IntValue:
+		// Kept as text, as 64-bit integers do not fit a float64
+		return json.Number(v.Value)
+	case *ast.FloatValue:
+		number, err := strconv.ParseFloat(v.Value, 64)
+		if err != nil {
+			return nil
+		}
+		return number
+	case *ast.EnumValue:
+		return v.Value
+	case *ast.ListValue:
+		list := make([]interface{}, len(v.Values))
+		for i, item := range v.Values {
+			list[i] = literalValue(item)
+		}
+		return list
+	case *ast.ObjectValue:
+		object := make(map[string]interface{}, len(v.Fields))
+		for _, field := range v.Fields {
+			object[field.Name.Value] = literalValue(field.Value)
+		}
+		return object
+	default:
+		return nil
+	}
+}
+
+// untypedValue converts the integer literals of a value sent without a
+// signature to float64, like the numbers of JSON arguments
+func untypedValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case json.Number:
+		number, err := v.Float64()
+		if err != nil {
+			return nil
+		}
+		return number
+	case []interface{}:
+		for i, item := range v {
+			v[i] = untypedValue(item)
+		}
+		return v
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = untypedValue(item)
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// NewSchema builds the schema resolving its fields with dbusService
+func NewSchema(dbusService Service) (graphql.Schema, error) {
+	r := &resolver{dbusService: dbusService}
+
+	nonNullString := graphql.NewNonNull(graphql.String)
+	nameFilter := graphql.FieldConfigArgument{
+		"name": {Type: graphql.String, Description: "Only the member with this name"},
+	}
+
+	annotationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Annotation",
+		Fields: graphql.Fields{
+			"name":  {Type: nonNullString},
+			"value": {Type: nonNullString},
+		},
+	})
+	annotationsField := func(annotationsOf func(source interface{}) map[string]string) *graphql.Field {
+		return &graphql.Field{
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(annotationType))),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return annotations(annotationsOf(p.Source)), nil
+			},
+		}
+	}
+
+	argumentType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Argument",
+		Description: "An argument of a method or signal",
+		Fields: graphql.Fields{
+			"name":      {Type: nonNullString},
+			"type":      {Type: nonNullString, Description: "D-Bus type signature"},
+			"direction": {Type: nonNullString},
+		},
+	})
+	argumentList := graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(argumentType)))
+
+	methodType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Method",
+		Fields: graphql.Fields{
+			"name":    {Type: nonNullString},
+			"inArgs":  {Type: argumentList},
+			"outArgs": {Type: argumentList},
+			"annotations": annotationsField(func(source interface{}) map[string]string {
+				return source.(model.MethodInfo).Annotations
+			}),
+		},
+	})
+
+	signalType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Signal",
+		Fields: graphql.Fields{
+			"name": {Type: nonNullString},
+			"args": {Type: argumentList},
+			"annotations": annotationsField(func(source interface{}) map[string]string {
+				return source.(model.SignalInfo).Annotations
+			}),
+		},
+	})
+
+	propertyType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Property",
+		Fields: graphql.Fields{
+			"name": {Type: nonNullString, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*propertyNode).info.Name, nil
+			}},
+			"type": {Type: nonNullString, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*propertyNode).info.Type, nil
+			}},
+			"access": {Type: nonNullString, Description: "read, write or readwrite", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*propertyNode).info.Access, nil
+			}},
+			"annotations": annotationsField(func(source interface{}) map[string]string {
+				return source.(*propertyNode).info.Annotations
+			}),
+			"value": {Type: jsonScalar, Description: "Current value, read with org.freedesktop.DBus.Properties.Get", Resolve: r.propertyValue},
+		},
+	})
+
+	interfaceType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Interface",
+		Fields: graphql.Fields{
+			"name": {Type: nonNullString, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(*interfaceNode).info.Name, nil
+			}},
+			"methods": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(methodType))),
+				Args: nameFilter,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					methods := make([]model.MethodInfo, 0)
+					for _, method := range p.Source.(*interfaceNode).info.Methods {
+						if matchName(p, method.Name) {
+							methods = append(methods, method)
+						}
+					}
+					return methods, nil
+				},
+			},
+			"properties": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(propertyType))),
+				Args: nameFilter,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					iface := p.Source.(*interfaceNode)
+					properties := make([]*propertyNode, 0)
+					for _, property := range iface.info.Properties {
+						if matchName(p, property.Name) {
+							properties = append(properties, &propertyNode{iface: iface, info: property})
+						}
+					}
+					return properties, nil
+				},
+			},
+			"signals": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(signalType))),
+				Args: nameFilter,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					signals := make([]model.SignalInfo, 0)
+					for _, signal := range p.Source.(*interfaceNode).info.Signals {
+						if matchName(p, signal.Name) {
+							signals = append(signals, signal)
+						}
+					}
+					return signals, nil
+				},
+			},
+		},
+	})
+
+	var objectType *graphql.Object
+	objectType = graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Object",
+		Description: "An object of a service",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"path": {Type: nonNullString},
+				"interfaces": {
+					Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(interfaceType))),
+					Args: nameFilter,
+					Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+						object := p.Source.(*objectNode)
+						interfaces := make([]*interfaceNode, 0)
+						if object.data != nil {
+							for _, iface := range object.data.Interfaces {
+								if matchName(p, iface.Name) {
+									interfaces = append(interfaces, &interfaceNode{object: object, info: iface})
+								}
+							}
+						}
+						return interfaces, nil
+					},
+				},
+				"children": {
+					Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(objectType))),
+					Description: "Child objects; objects that fail to introspect are skipped",
+					Resolve:     r.children,
+				},
+			}
+		}),
+	})
+	objectPathArg := &graphql.ArgumentConfig{Type: graphql.String, DefaultValue: "/", Description: "D-Bus object path"}
+
+	serviceType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Service",
+		Fields: graphql.Fields{
+			"name": {Type: nonNullString},
+			"bus":  {Type: nonNullString},
+			"owner": {Type: graphql.String, Description: "Unique name of the owner", Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				svc := p.Source.(*serviceNode)
+				// The queue is cheaper than the service info, which also reads credentials and introspects
+				queue, err := r.dbusService.ListQueuedOwners(p.Context, svc.Bus, svc.Name)
+				if err != nil || len(queue.Owners) == 0 {
+					return "unknown", nil
+				}
+				return queue.Owners[0], nil
+			}},
+			"object": {
+				Type: objectType,
+				Args: graphql.FieldConfigArgument{"path": objectPathArg},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					svc := p.Source.(*serviceNode)
+					return r.object(p.Context, svc.Bus, svc.Name, p.Args["path"].(string))
+				},
+			},
+			"objects": {
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(objectType))),
+				Description: fmt.Sprintf("Objects of the tree below root, breadth first and up to %d objects", maxObjects),
+				Args:        graphql.FieldConfigArgument{"root": {Type: graphql.String, DefaultValue: "/"}},
+				Resolve:     r.objects,
+			},
+		},
+	})
+
+	busType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Bus",
+		Fields: graphql.Fields{
+			"type":        {Type: nonNullString},
+			"description": {Type: nonNullString},
+			"services": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(serviceType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					bus := p.Source.(model.BusInfo)
+					names, err := r.dbusService.ListServices(p.Context, bus.Type)
+					if err != nil {
+						return nil, err
+					}
+					services := make([]*serviceNode, len(names))
+					for i, name := range names {
+						services[i] = &serviceNode{Bus: bus.Type, Name: name}
+					}
+					return services, nil
+				},
+			},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"buses": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(busType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return buses, nil
+				},
+			},
+			"bus": {
+				Type: busType,
+				Args: graphql.FieldConfigArgument{"type": {Type: nonNullString}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					for _, bus := range buses {
+						if bus.Type == p.Args["type"] {
+							return bus, nil
+						}
+					}
+					return nil, fmt.Errorf("invalid bus type: %s", p.Args["type"])
+				},
+			},
+			"service": {
+				Type: serviceType,
+				Args: graphql.FieldConfigArgument{
+					"bus":  {Type: nonNullString},
+					"name": {Type: nonNullString},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return &serviceNode{Bus: p.Args["bus"].(string), Name: p.Args["name"].(string)}, nil
+				},
+			},
+			"object": {
+				Type: objectType,
+				Args: graphql.FieldConfigArgument{
+					"bus":     {Type: nonNullString},
+					"service": {Type: nonNullString},
+					"path":    objectPathArg,
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return r.object(p.Context, p.Args["bus"].(string), p.Args["service"].(string), p.Args["path"].(string))
+				},
+			},
+		},
+	})
+
+	methodCallResultType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "MethodCallResult",
+		Fields: graphql.Fields{
+			"success":      {Type: graphql.NewNonNull(graphql.Boolean)},
+			"returnValues": {Type: graphql.NewList(jsonScalar)},
+			"error":        {Type: graphql.String},
+			"errorName":    {Type: graphql.String, Description: "D-Bus error name of a failed call"},
+		},
+	})
+
+	propertyValueType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PropertyValue",
+		Fields: graphql.Fields{
+			"name":  {Type: nonNullString},
+			"type":  {Type: graphql.String},
+			"value": {Type: jsonScalar},
+		},
+	})
+
+	targetArgs := func(member string, extra graphql.FieldConfigArgument) graphql.FieldConfigArgument {
+		args := graphql.FieldConfigArgument{
+			"bus":       {Type: nonNullString},
+			"service":   {Type: nonNullString},
+			"path":      objectPathArg,
+			"interface": {Type: nonNullString},
+			member:      {Type: nonNullString},
+			"signature": {Type: graphql.String, Description: "D-Bus types the JSON values are converted to"},
+		}
+		for name, arg := range extra {
+			args[name] = arg
+		}
+		return args
+	}
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"callMethod": {
+				Type: graphql.NewNonNull(methodCallResultType),
+				Args: targetArgs("method", graphql.FieldConfigArgument{
+					"args": {Type: graphql.NewList(jsonScalar)},
+				}),
+				Resolve: r.callMethod,
+			},
+			"setProperty": {
+				Type: graphql.NewNonNull(propertyValueType),
+				Args: targetArgs("property", graphql.FieldConfigArgument{
+					"value": {Type: graphql.NewNonNull(jsonScalar)},
+				}),
+				Resolve: r.setProperty,
+			},
+		},
+	})
+
+	signalEventType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "SignalEvent",
+		Fields: graphql.Fields{
+			"sender":     {Type: nonNullString},
+			"path":       {Type: nonNullString},
+			"interface":  {Type: nonNullString},
+			"signal":     {Type: nonNullString},
+			"body":       {Type: graphql.NewList(jsonScalar)},
+			"receivedAt": {Type: graphql.DateTime},
+		},
+	})
+
+	subscription := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Subscription",
+		Fields: graphql.Fields{
+			"signal": {
+				Type:        graphql.NewNonNull(signalEventType),
+				Description: "Signals of a service, watched until the stream ends",
+				Args: graphql.FieldConfigArgument{
+					"bus":       {Type: nonNullString},
+					"service":   {Type: nonNullString},
+					"interface": {Type: nonNullString},
+					"signal":    {Type: nonNullString},
+				},
+				Subscribe: r.signals,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:        query,
+		Mutation:     mutation,
+		Subscription: subscription,
+	})
+}
+
+// matchName reports whether a member passes the name argument of a field
+func matchName(p graphql.ResolveParams, name string) bool {
+	filter, ok := p.Args["name"].(string)
+	return !ok || filter == name
+}
+
+// annotations returns the annotations of a map ordered by name
+func annotations(values map[string]string) []annotation {
+	list := make([]annotation, 0, len(values))
+	for name, value := range values {
+		list = append(list, annotation{Name: name, Value: value})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// resolver resolves the fields backed by D-Bus calls
+type resolver struct {
+	dbusService Service
+}
+
+// object introspects an object of a service
+func (r *resolver) object(ctx context.Context, busType, serviceName, objectPath string) (*objectNode, error) {
+	if !dbus.ObjectPath(objectPath).IsValid() {
+		return nil, fmt.Errorf("invalid object path: %s", objectPath)
+	}
+	result, err := r.dbusService.IntrospectObject(ctx, busType, serviceName, objectPath)
+	if err != nil {
+		return nil, err
+	}
+	return &objectNode{Bus: busType, Service: serviceName, Path: objectPath, data: result.ParsedData}, nil
+}
+
+// children introspects the child objects of an object
+func (r *resolver) children(p graphql.ResolveParams) (interface{}, error) {
+	parent := p.Source.(*objectNode)
+	children := make([]*objectNode, 0)
+	if parent.data == nil {
+		return children, nil
+	}
+	for _, node := range parent.data.Nodes {
+		child, err := r.object(p.Context, parent.Bus, parent.Service, node.Path)
+		if err != nil {
+			continue
+		}
+		children = append(children, child)
+	}
+	return children, nil
+}
+
+// objects introspects the object tree of a service
+func (r *resolver) objects(p graphql.ResolveParams) (interface{}, error) {
+	svc := p.Source.(*serviceNode)
+	root := p.Args["root"].(string)
+	if !dbus.ObjectPath(root).IsValid() {
+		return nil, fmt.Errorf("invalid object path: %s", root)
+	}
+
+	results, err := service.IntrospectTree(p.Context, r.dbusService, svc.Bus, svc.Name, root, maxObjects)
+	if err != nil {
+		return nil, err
+	}
+	objects := make([]*objectNode, len(results))
+	for i, result := range results {
+		objects[i] = &objectNode{Bus: svc.Bus, Service: svc.Name, Path: result.ObjectPath, data: result.ParsedData}
+	}
+	return objects, nil
+}
+
+// propertyValue reads the current value of a readable property
+func (r *resolver) propertyValue(p graphql.ResolveParams) (interface{}, error) {
+	property := p.Source.(*propertyNode)
+	if property.info.Access == "write" {
+		return nil, nil
+	}
+	object := property.iface.object
+	value, err := r.dbusService.GetProperty(p.Context, object.Bus, object.Service, object.Path, property.iface.info.Name, property.info.Name)
+	if err != nil {
+		return nil, err
+	}
+	return value.Value, nil
+}
+
+// callMethod calls a method, converting the arguments to the types of the signature if given
+func (r *resolver) callMethod(p graphql.ResolveParams) (interface{}, error) {
+	objectPath := p.Args["path"].(string)
+	if !dbus.ObjectPath(objectPath).IsValid() {
+		return nil, fmt.Errorf("invalid object path: %s", objectPath)
+	}
+
+	args, _ := p.Args["args"].([]interface{})
+	if signature, ok := p.Args["signature"].(string); ok {
+		types, err := service.SplitSignature(signature)
+		if err != nil {
+			return nil, fmt.Errorf("signature: %w", err)
+		}
+		if len(types) != len(args) {
+			return nil, fmt.Errorf("signature %q has %d types for %d args", signature, len(types), len(args))
+		}
+		for i, typ := range types {
+			if args[i], err = service.ParseJSONArg(fmt.Sprintf("args[%d]", i), typ, args[i]); err != nil {
+				return nil, err
+			}
+		}
+	} else {
+		for i := range args {
+			args[i] = untypedValue(args[i])
+		}
+	}
+
+	return r.dbusService.CallMethod(p.Context, p.Args["bus"].(string), p.Args["service"].(string), objectPath,
+		p.Args["interface"].(string), p.Args["method"].(string), args)
+}
+
+// setProperty sets a property, converting the value to the type of the signature if given
+func (r *resolver) setProperty(p graphql.ResolveParams) (interface{}, error) {
+	objectPath := p.Args["path"].(string)
+	if !dbus.ObjectPath(objectPath).IsValid() {
+		return nil, fmt.Errorf("invalid object path: %s", objectPath)
+	}
+
+	value := p.Args["value"]
+	if signature, ok := p.Args["signature"].(string); ok {
+		typed, err := service.ParseJSONArg("value", signature, value)
+		if err != nil {
+			return nil, err
+		}
+		value = dbus.MakeVariant(typed)
+	} else {
+		value = untypedValue(value)
+	}
+
+	return r.dbusService.SetProperty(p.Context, p.Args["bus"].(string), p.Args["service"].(string), objectPath,
+		p.Args["interface"].(string), p.Args["property"].(string), value)
+}
+
+// signals watches a signal until the stream ends and forwards its events
+func (r *resolver) signals(p graphql.ResolveParams) (interface{}, error) {
+	match := service.SignalMatch{
+		Sender:    p.Args["service"].(string),
+		Interface: p.Args["interface"].(string),
+		Member:    p.Args["signal"].(string),
+	}
+	events, err := r.dbusService.StreamSignals(p.Context, p.Args["bus"].(string), match)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan interface{})
+	go func() {
+		defer close(out)
+		for event := range events {
+			select {
+			case out <- event:
+			case <-p.Context.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
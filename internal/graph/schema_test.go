@@ -0,0 +1,200 @@
+package graph_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/graphql-go/graphql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbrj/dbus-controller/internal/graph"
+	"github.com/mesbrj/dbus-controller/internal/handler/handlertest"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// helloObject is the introspection data of the example service object
+var helloObject = &model.IntrospectionResult{
+	Service:    "com.example.HelloWorld",
+	ObjectPath: "/com/example/HelloWorld",
+	ParsedData: &model.ParsedIntrospection{
+		Interfaces: []model.InterfaceInfo{{
+			Name: "com.example.HelloWorld",
+			Methods: []model.MethodInfo{{
+				Name:        "Hello",
+				InArgs:      []model.ArgumentInfo{{Name: "name", Type: "s", Direction: "in"}},
+				OutArgs:     []model.ArgumentInfo{{Name: "greeting", Type: "s", Direction: "out"}},
+				Annotations: map[string]string{"org.freedesktop.DBus.Deprecated": "false"},
+			}},
+			Properties: []model.PropertyInfo{
+				{Name: "Data", Type: "v", Access: "read"},
+				{Name: "Secret", Type: "s", Access: "write"},
+			},
+			Signals: []model.SignalInfo{{Name: "Changed"}},
+		}},
+	},
+}
+
+// run executes a request and returns its data as JSON
+func run(t *testing.T, mockService *handlertest.MockDBusService, request string) string {
+	schema, err := graph.NewSchema(mockService)
+	require.NoError(t, err)
+
+	result := graphql.Do(graphql.Params{Schema: schema, RequestString: request, Context: context.Background()})
+	require.Empty(t, result.Errors)
+
+	data, err := json.Marshal(result.Data)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestSchema_Object(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("IntrospectObject", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld").Return(helloObject, nil)
+	mockService.On("GetProperty", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Data").
+		Return(&model.PropertyValue{Name: "Data", Value: map[string]dbus.Variant{"a": dbus.MakeVariant(uint32(1))}}, nil)
+
+	data := run(t, mockService, `{
+		object(bus: "session", service: "com.example.HelloWorld", path: "/com/example/HelloWorld") {
+			path
+			interfaces {
+				name
+				methods { name inArgs { name type } annotations { name value } }
+				properties { name access value }
+				signals(name: "Changed") { name }
+			}
+		}
+	}`)
+
+	assert.JSONEq(t, `{"object": {"path": "/com/example/HelloWorld", "interfaces": [{
+		"name": "com.example.HelloWorld",
+		"methods": [{"name": "Hello", "inArgs": [{"name": "name", "type": "s"}], "annotations": [{"name": "org.freedesktop.DBus.Deprecated", "value": "false"}]}],
+		"properties": [{"name": "Data", "access": "read", "value": {"a": 1}}, {"name": "Secret", "access": "write", "value": null}],
+		"signals": [{"name": "Changed"}]
+	}]}}`, data)
+	mockService.AssertExpectations(t)
+}
+
+func TestSchema_ServiceObjects(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("ListServices", mock.Anything, "session").Return([]string{"com.example.HelloWorld"}, nil)
+	mockService.On("IntrospectObject", mock.Anything, "session", "com.example.HelloWorld", "/").Return(&model.IntrospectionResult{
+		ObjectPath: "/",
+		ParsedData: &model.ParsedIntrospection{Nodes: []model.NodeInfo{{Name: "com/example/HelloWorld", Path: "/com/example/HelloWorld"}}},
+	}, nil)
+	mockService.On("IntrospectObject", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld").Return(helloObject, nil)
+
+	data := run(t, mockService, `{ bus(type: "session") { services { name objects { path interfaces { name } } } } }`)
+
+	assert.JSONEq(t, `{"bus": {"services": [{"name": "com.example.HelloWorld", "objects": [
+		{"path": "/", "interfaces": []},
+		{"path": "/com/example/HelloWorld", "interfaces": [{"name": "com.example.HelloWorld"}]}
+	]}]}}`, data)
+}
+
+func TestSchema_CallMethod(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethod", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld", "Add",
+		[]interface{}{uint32(1), []string{"a"}}).
+		Return(&model.MethodCallResult{Success: true, ReturnValues: []interface{}{uint32(2)}}, nil)
+
+	data := run(t, mockService, `mutation {
+		callMethod(bus: "session", service: "com.example.HelloWorld", interface: "com.example.HelloWorld", method: "Add",
+			args: [1, ["a"]], signature: "uas") { success returnValues }
+	}`)
+
+	assert.JSONEq(t, `{"callMethod": {"success": true, "returnValues": [2]}}`, data)
+	mockService.AssertExpectations(t)
+}
+
+func TestSchema_CallMethod_Uint64(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethod", mock.Anything, "session", "com.example.Counter", "/", "com.example.Counter", "Set",
+		[]interface{}{uint64(18446744073709551615), int64(-9007199254740993)}).
+		Return(&model.MethodCallResult{Success: true}, nil)
+
+	data := run(t, mockService, `mutation {
+		callMethod(bus: "session", service: "com.example.Counter", interface: "com.example.Counter", method: "Set",
+			args: [18446744073709551615, -9007199254740993], signature: "tx") { success }
+	}`)
+
+	assert.JSONEq(t, `{"callMethod": {"success": true}}`, data)
+	mockService.AssertExpectations(t)
+}
+
+func TestSchema_CallMethod_Untyped(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethod", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld", "Scale",
+		[]interface{}{float64(2), []interface{}{float64(3), "a"}}).
+		Return(&model.MethodCallResult{Success: true}, nil)
+
+	data := run(t, mockService, `mutation {
+		callMethod(bus: "session", service: "com.example.HelloWorld", interface: "com.example.HelloWorld", method: "Scale",
+			args: [2, [3, "a"]]) { success }
+	}`)
+
+	assert.JSONEq(t, `{"callMethod": {"success": true}}`, data)
+	mockService.AssertExpectations(t)
+}
+
+func TestSchema_SetProperty(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("SetProperty", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld", "Level", dbus.MakeVariant(uint32(3))).
+		Return(&model.PropertyValue{Name: "Level", Type: "u", Value: uint32(3)}, nil)
+
+	data := run(t, mockService, `mutation {
+		setProperty(bus: "session", service: "com.example.HelloWorld", interface: "com.example.HelloWorld", property: "Level",
+			value: 3, signature: "u") { name type value }
+	}`)
+
+	assert.JSONEq(t, `{"setProperty": {"name": "Level", "type": "u", "value": 3}}`, data)
+}
+
+func TestSchema_Errors(t *testing.T) {
+	schema, err := graph.NewSchema(new(handlertest.MockDBusService))
+	require.NoError(t, err)
+
+	for _, request := range []string{
+		`{ bus(type: "user") { type } }`,
+		`{ object(bus: "session", service: "a.b", path: "relative") { path } }`,
+		`mutation { callMethod(bus: "session", service: "a.b", interface: "a.b", method: "M", args: [1], signature: "ss") { success } }`,
+	} {
+		result := graphql.Do(graphql.Params{Schema: schema, RequestString: request, Context: context.Background()})
+		assert.NotEmpty(t, result.Errors, request)
+	}
+}
+
+func TestSchema_SignalSubscription(t *testing.T) {
+	events := make(chan *model.SignalEvent, 1)
+	events <- &model.SignalEvent{Sender: ":1.7", Signal: "Changed", Body: []interface{}{dbus.MakeVariant("x")}}
+	close(events)
+
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("StreamSignals", mock.Anything, "session", service.SignalMatch{Sender: "a.b", Interface: "a.b", Member: "Changed"}).
+		Return(events, nil)
+
+	schema, err := graph.NewSchema(mockService)
+	require.NoError(t, err)
+
+	results := graphql.Subscribe(graphql.Params{
+		Schema:        schema,
+		RequestString: `subscription { signal(bus: "session", service: "a.b", interface: "a.b", signal: "Changed") { sender signal body } }`,
+		Context:       context.Background(),
+	})
+
+	var received []string
+	for result := range results {
+		require.Empty(t, result.Errors)
+		data, err := json.Marshal(result.Data)
+		require.NoError(t, err)
+		received = append(received, string(data))
+	}
+
+	require.Len(t, received, 1)
+	assert.JSONEq(t, `{"signal": {"sender": ":1.7", "signal": "Changed", "body": ["x"]}}`, received[0])
+	mockService.AssertNotCalled(t, "SubscribeToSignal")
+}
@@ -0,0 +1,160 @@
+package grpcserver
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+	pb "github.com/mesbrj/dbus-controller/proto/dbuscontroller/v1"
+)
+
+func busHealth(health *model.BusHealth) *pb.BusHealth {
+	return &pb.BusHealth{
+		Type:      health.Type,
+		Connected: health.Connected,
+		Id:        health.ID,
+		Latency:   health.Latency,
+		Error:     health.Error,
+	}
+}
+
+func serviceInfo(info *model.ServiceInfo) *pb.ServiceInfo {
+	message := &pb.ServiceInfo{
+		Name:             info.Name,
+		Owner:            info.Owner,
+		Interfaces:       info.Interfaces,
+		ObjectPaths:      info.ObjectPaths,
+		Aliases:          info.Aliases,
+		AliasesTruncated: info.AliasesTruncated,
+	}
+	if credentials := info.Credentials; credentials != nil {
+		message.Credentials = &pb.ConnectionCredentials{
+			UnixUserId:         credentials.UnixUserID,
+			UnixGroupIds:       credentials.UnixGroupIDs,
+			ProcessId:          credentials.ProcessID,
+			LinuxSecurityLabel: credentials.LinuxSecurityLabel,
+		}
+	}
+	if process := info.Process; process != nil {
+		message.Process = &pb.ProcessInfo{
+			Pid:     process.PID,
+			Cmdline: process.Cmdline,
+			Exe:     process.Exe,
+			Cgroup:  process.Cgroup,
+		}
+		if process.StartTime != nil {
+			message.Process.StartTime = timestamp(*process.StartTime)
+		}
+	}
+	return message
+}
+
+func introspectionResult(result *model.IntrospectionResult) *pb.IntrospectionResult {
+	message := &pb.IntrospectionResult{Service: result.Service, Path: result.ObjectPath, Xml: result.XML}
+	if result.ParsedData != nil {
+		for _, info := range result.ParsedData.Interfaces {
+			message.Interfaces = append(message.Interfaces, interfaceInfo(info))
+		}
+		for _, node := range result.ParsedData.Nodes {
+			message.Nodes = append(message.Nodes, &pb.Node{Name: node.Name, Path: node.Path})
+		}
+	}
+	return message
+}
+
+func interfaceInfo(info model.InterfaceInfo) *pb.InterfaceInfo {
+	return &pb.InterfaceInfo{
+		Name:       info.Name,
+		Methods:    methodInfos(info.Methods),
+		Properties: propertyInfos(info.Properties),
+		Signals:    signalInfos(info.Signals),
+	}
+}
+
+func methodInfos(methods []model.MethodInfo) []*pb.MethodInfo {
+	messages := make([]*pb.MethodInfo, len(methods))
+	for i, method := range methods {
+		messages[i] = &pb.MethodInfo{
+			Name:        method.Name,
+			InArgs:      argumentInfos(method.InArgs),
+			OutArgs:     argumentInfos(method.OutArgs),
+			Annotations: method.Annotations,
+		}
+	}
+	return messages
+}
+
+func propertyInfos(properties []model.PropertyInfo) []*pb.PropertyInfo {
+	messages := make([]*pb.PropertyInfo, len(properties))
+	for i, property := range properties {
+		messages[i] = &pb.PropertyInfo{
+			Name:        property.Name,
+			Type:        property.Type,
+			Access:      property.Access,
+			Annotations: property.Annotations,
+		}
+	}
+	return messages
+}
+
+func signalInfos(signals []model.SignalInfo) []*pb.SignalInfo {
+	messages := make([]*pb.SignalInfo, len(signals))
+	for i, signal := range signals {
+		messages[i] = &pb.SignalInfo{
+			Name:        signal.Name,
+			Args:        argumentInfos(signal.Args),
+			Annotations: signal.Annotations,
+		}
+	}
+	return messages
+}
+
+func argumentInfos(args []model.ArgumentInfo) []*pb.ArgumentInfo {
+	messages := make([]*pb.ArgumentInfo, len(args))
+	for i, arg := range args {
+		messages[i] = &pb.ArgumentInfo{Name: arg.Name, Type: arg.Type, Direction: arg.Direction}
+	}
+	return messages
+}
+
+// propertyValue converts a property value, typed by the signature of the variant it was read from
+func propertyValue(property *model.PropertyValue) *pb.PropertyValue {
+	value := toValue(property.Value)
+	if value != nil && property.Type != "" {
+		value.Signature = property.Type
+	}
+	return &pb.PropertyValue{Name: property.Name, Value: value, Timestamp: timestamp(property.Timestamp)}
+}
+
+func signalSubscription(subscription model.SignalSubscription) *pb.Subscription {
+	return &pb.Subscription{
+		Id:        subscription.ID,
+		Bus:       subscription.BusType,
+		Service:   subscription.Service,
+		Interface: subscription.Interface,
+		Signal:    subscription.Signal,
+		Active:    subscription.Active,
+		CreatedAt: timestamp(subscription.CreatedAt),
+	}
+}
+
+func signalEvent(event *model.SignalEvent) *pb.SignalEvent {
+	return &pb.SignalEvent{
+		SubscriptionId: event.SubscriptionID,
+		Sender:         event.Sender,
+		Path:           event.Path,
+		Interface:      event.Interface,
+		Signal:         event.Signal,
+		Body:           toValues(event.Body),
+		ReceivedAt:     timestamp(event.ReceivedAt),
+	}
+}
+
+// timestamp converts a time, leaving unset times empty
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
@@ -0,0 +1,347 @@
+// Package grpcserver serves the D-Bus operations of the controller over gRPC
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/godbus/dbus/v5"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/mesbrj/dbus-controller/internal/logging"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/internal/tracing"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+	pb "github.com/mesbrj/dbus-controller/proto/dbuscontroller/v1"
+)
+
+// dbusErrorDomain is the domain of the ErrorInfo detail carrying D-Bus error names
+const dbusErrorDomain = "org.freedesktop.DBus"
+
+// Server implements the gRPC DBusService on top of the D-Bus service
+type Server struct {
+	pb.UnimplementedDBusServiceServer
+	dbusService service.DBusServiceInterface
+}
+
+// New creates a gRPC DBusService backed by dbusService
+func New(dbusService service.DBusServiceInterface) *Server {
+	return &Server{dbusService: dbusService}
+}
+
+// NewGRPCServer creates a gRPC server serving the DBusService, with the
+// tracing and request logging of the HTTP server
+func NewGRPCServer(dbusService service.DBusServiceInterface) *grpc.Server {
+	s := grpc.NewServer(
+		grpc.StatsHandler(tracing.GRPCStatsHandler()),
+		grpc.ChainUnaryInterceptor(logging.UnaryServerInterceptor),
+		grpc.ChainStreamInterceptor(logging.StreamServerInterceptor),
+	)
+	pb.RegisterDBusServiceServer(s, New(dbusService))
+	return s
+}
+
+// CheckBus reports the connectivity of a bus
+func (s *Server) CheckBus(ctx context.Context, req *pb.CheckBusRequest) (*pb.BusHealth, error) {
+	if err := checkBus(req.Bus); err != nil {
+		return nil, err
+	}
+	return busHealth(s.dbusService.CheckBus(ctx, req.Bus)), nil
+}
+
+// ListServices returns the names owned on a bus
+func (s *Server) ListServices(ctx context.Context, req *pb.ListServicesRequest) (*pb.ListServicesResponse, error) {
+	if err := checkBus(req.Bus); err != nil {
+		return nil, err
+	}
+	services, err := s.dbusService.ListServices(ctx, req.Bus)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.ListServicesResponse{Services: services}, nil
+}
+
+// GetServiceInfo returns the owner, interfaces and objects of a service
+func (s *Server) GetServiceInfo(ctx context.Context, req *pb.GetServiceInfoRequest) (*pb.ServiceInfo, error) {
+	if err := checkBus(req.Bus); err != nil {
+		return nil, err
+	}
+	info, err := s.dbusService.GetServiceInfo(ctx, req.Bus, req.Service)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return serviceInfo(info), nil
+}
+
+// IntrospectObject returns the introspection data of an object
+func (s *Server) IntrospectObject(ctx context.Context, req *pb.ObjectRequest) (*pb.IntrospectionResult, error) {
+	path, err := objectPath(req.Bus, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.dbusService.IntrospectObject(ctx, req.Bus, req.Service, path)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return introspectionResult(result), nil
+}
+
+// ListInterfaces returns the interfaces of an object
+func (s *Server) ListInterfaces(ctx context.Context, req *pb.ObjectRequest) (*pb.ListInterfacesResponse, error) {
+	path, err := objectPath(req.Bus, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	interfaces, err := s.dbusService.ListInterfaces(ctx, req.Bus, req.Service, path)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.ListInterfacesResponse{Interfaces: interfaces}, nil
+}
+
+// GetInterfaceInfo returns the members of an interface
+func (s *Server) GetInterfaceInfo(ctx context.Context, req *pb.InterfaceRequest) (*pb.InterfaceInfo, error) {
+	path, err := objectPath(req.Bus, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := s.dbusService.GetInterfaceInfo(ctx, req.Bus, req.Service, path, req.Interface)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return interfaceInfo(*info), nil
+}
+
+// ListMethods returns the methods of an interface
+func (s *Server) ListMethods(ctx context.Context, req *pb.InterfaceRequest) (*pb.ListMethodsResponse, error) {
+	path, err := objectPath(req.Bus, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	methods, err := s.dbusService.ListMethods(ctx, req.Bus, req.Service, path, req.Interface)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.ListMethodsResponse{Methods: methodInfos(methods)}, nil
+}
+
+// ListProperties returns the properties of an interface
+func (s *Server) ListProperties(ctx context.Context, req *pb.InterfaceRequest) (*pb.ListPropertiesResponse, error) {
+	path, err := objectPath(req.Bus, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	properties, err := s.dbusService.ListProperties(ctx, req.Bus, req.Service, path, req.Interface)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.ListPropertiesResponse{Properties: propertyInfos(properties)}, nil
+}
+
+// ListSignals returns the signals of an interface
+func (s *Server) ListSignals(ctx context.Context, req *pb.InterfaceRequest) (*pb.ListSignalsResponse, error) {
+	path, err := objectPath(req.Bus, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	signals, err := s.dbusService.ListSignals(ctx, req.Bus, req.Service, path, req.Interface)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.ListSignalsResponse{Signals: signalInfos(signals)}, nil
+}
+
+// CallMethod calls a method with typed arguments and header flags. With
+// no_reply, or for methods annotated NoReply, the call is sent without waiting
+// for a reply, and the response only reports whether it was sent.
+func (s *Server) CallMethod(ctx context.Context, req *pb.CallMethodRequest) (*pb.CallMethodResponse, error) {
+	path, err := objectPath(req.Bus, req.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]interface{}, len(req.Args))
+	for i, arg := range req.Args {
+		args[i], err = fromValue(fmt.Sprintf("args[%d]", i), arg)
+		if err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	flags := model.CallFlags{
+		NoReply:                       req.NoReply,
+		NoAutoStart:                   req.NoAutoStart,
+		AllowInteractiveAuthorization: req.AllowInteractiveAuthorization,
+	}
+	result, err := s.dbusService.CallMethodWithFlags(ctx, req.Bus, req.Service, path, req.Interface, req.Method, flags, args)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.CallMethodResponse{
+		Success:      result.Success,
+		ReturnValues: toValues(result.ReturnValues),
+		Error:        result.Error,
+		ErrorName:    result.ErrorName,
+		Timestamp:    timestamp(result.Timestamp),
+		NoReply:      result.NoReply,
+	}, nil
+}
+
+// GetProperty reads a property
+func (s *Server) GetProperty(ctx context.Context, req *pb.PropertyRequest) (*pb.PropertyValue, error) {
+	path, err := objectPath(req.Bus, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	value, err := s.dbusService.GetProperty(ctx, req.Bus, req.Service, path, req.Interface, req.Property)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return propertyValue(value), nil
+}
+
+// SetProperty writes a property and returns its updated value
+func (s *Server) SetProperty(ctx context.Context, req *pb.SetPropertyRequest) (*pb.PropertyValue, error) {
+	path, err := objectPath(req.Bus, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	value, err := fromValue("value", req.Value)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Keep the signature of the value, which may not be the default one of its Go type
+	variant := dbus.MakeVariantWithSignature(value, dbus.ParseSignatureMust(req.Value.Signature))
+	updated, err := s.dbusService.SetProperty(ctx, req.Bus, req.Service, path, req.Interface, req.Property, variant)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return propertyValue(updated), nil
+}
+
+// Subscribe subscribes to a signal
+func (s *Server) Subscribe(ctx context.Context, req *pb.SubscribeRequest) (*pb.Subscription, error) {
+	if err := checkBus(req.Bus); err != nil {
+		return nil, err
+	}
+	subscription, err := s.dbusService.SubscribeToSignal(ctx, req.Bus, req.Service, req.Interface, req.Signal)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return signalSubscription(*subscription), nil
+}
+
+// ListSubscriptions returns the active signal subscriptions
+func (s *Server) ListSubscriptions(ctx context.Context, _ *pb.ListSubscriptionsRequest) (*pb.ListSubscriptionsResponse, error) {
+	subscriptions, err := s.dbusService.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	response := &pb.ListSubscriptionsResponse{Subscriptions: make([]*pb.Subscription, len(subscriptions))}
+	for i, subscription := range subscriptions {
+		response.Subscriptions[i] = signalSubscription(subscription)
+	}
+	return response, nil
+}
+
+// Unsubscribe removes a signal subscription and ends its streams
+func (s *Server) Unsubscribe(ctx context.Context, req *pb.UnsubscribeRequest) (*pb.UnsubscribeResponse, error) {
+	if err := s.dbusService.Unsubscribe(ctx, req.SubscriptionId); err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.UnsubscribeResponse{}, nil
+}
+
+// StreamSignals sends the signals of a subscription until it is removed, the
+// server shuts down or the client cancels the call
+func (s *Server) StreamSignals(req *pb.StreamSignalsRequest, stream grpc.ServerStreamingServer[pb.SignalEvent]) error {
+	ctx := stream.Context()
+	events, err := s.dbusService.StreamSubscription(ctx, req.SubscriptionId)
+	if err != nil {
+		return statusError(err)
+	}
+
+	slog.InfoContext(ctx, "Signal stream opened", "subscription", req.SubscriptionId)
+	defer slog.InfoContext(ctx, "Signal stream closed", "subscription", req.SubscriptionId)
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(signalEvent(event)); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+// checkBus rejects unknown bus types
+func checkBus(busType string) error {
+	if busType != "system" && busType != "session" {
+		return status.Errorf(codes.InvalidArgument, "bus must be 'system' or 'session', got %q", busType)
+	}
+	return nil
+}
+
+// objectPath validates the bus and object path of a request, "/" by default
+func objectPath(busType, path string) (string, error) {
+	if err := checkBus(busType); err != nil {
+		return "", err
+	}
+	if path == "" {
+		return "/", nil
+	}
+	if !dbus.ObjectPath(path).IsValid() {
+		return "", status.Errorf(codes.InvalidArgument, "invalid object path: %s", path)
+	}
+	return path, nil
+}
+
+// statusError converts a D-Bus service error to a gRPC status. Errors of D-Bus
+// calls carry their error name in an ErrorInfo detail.
+func statusError(err error) error {
+	if errors.Is(err, service.ErrSubscriptionNotFound) || errors.Is(err, service.ErrInterfaceNotFound) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+
+	name := service.ErrorName(err)
+	if name == "" {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	st := status.New(dbusErrorCode(name), err.Error())
+	if detailed, detailErr := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   "DBUS_ERROR",
+		Domain:   dbusErrorDomain,
+		Metadata: map[string]string{"name": name},
+	}); detailErr == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// dbusErrorCode maps a D-Bus error name to a gRPC status code
+func dbusErrorCode(name string) codes.Code {
+	switch service.ClassifyError(name) {
+	case service.ErrorClassNotFound:
+		return codes.NotFound
+	case service.ErrorClassInvalid:
+		return codes.InvalidArgument
+	case service.ErrorClassDenied:
+		return codes.PermissionDenied
+	case service.ErrorClassTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Unknown
+	}
+}
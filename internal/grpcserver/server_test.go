@@ -0,0 +1,214 @@
+package grpcserver
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/mesbrj/dbus-controller/internal/handler/handlertest"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+	pb "github.com/mesbrj/dbus-controller/proto/dbuscontroller/v1"
+)
+
+// newClient serves the DBusService backed by mockService over an in-memory connection
+func newClient(t *testing.T, mockService *handlertest.MockDBusService) pb.DBusServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	server := NewGRPCServer(mockService)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewDBusServiceClient(conn)
+}
+
+func TestServer_ListServices(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("ListServices", mock.Anything, "session").Return([]string{"com.example.HelloWorld"}, nil)
+
+	response, err := newClient(t, mockService).ListServices(context.Background(), &pb.ListServicesRequest{Bus: "session"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"com.example.HelloWorld"}, response.Services)
+}
+
+func TestServer_GetServiceInfo(t *testing.T) {
+	uid, pid := uint32(1000), uint32(4242)
+	started := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("GetServiceInfo", mock.Anything, "session", "com.example.HelloWorld").Return(&model.ServiceInfo{
+		Name:             "com.example.HelloWorld",
+		Owner:            ":1.42",
+		Aliases:          []string{"com.example.Hello"},
+		AliasesTruncated: true,
+		Credentials:      &model.ConnectionCredentials{UnixUserID: &uid, UnixGroupIDs: []uint32{1000, 27}, ProcessID: &pid, LinuxSecurityLabel: "unconfined"},
+		Process:          &model.ProcessInfo{PID: pid, Cmdline: []string{"hello", "--verbose"}, Exe: "/usr/bin/hello", Cgroup: "/user.slice/hello.service", StartTime: &started},
+	}, nil)
+
+	info, err := newClient(t, mockService).GetServiceInfo(context.Background(), &pb.GetServiceInfoRequest{Bus: "session", Service: "com.example.HelloWorld"})
+
+	require.NoError(t, err)
+	assert.Equal(t, ":1.42", info.Owner)
+	assert.Equal(t, []string{"com.example.Hello"}, info.Aliases)
+	assert.True(t, info.AliasesTruncated)
+	assert.Equal(t, uint32(1000), info.Credentials.GetUnixUserId())
+	assert.Equal(t, []uint32{1000, 27}, info.Credentials.UnixGroupIds)
+	assert.Equal(t, pid, info.Credentials.GetProcessId())
+	assert.Equal(t, "unconfined", info.Credentials.LinuxSecurityLabel)
+	assert.Equal(t, pid, info.Process.Pid)
+	assert.Equal(t, []string{"hello", "--verbose"}, info.Process.Cmdline)
+	assert.Equal(t, "/usr/bin/hello", info.Process.Exe)
+	assert.Equal(t, "/user.slice/hello.service", info.Process.Cgroup)
+	assert.Equal(t, started, info.Process.StartTime.AsTime())
+}
+
+func TestServer_GetServiceInfo_NoCredentials(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("GetServiceInfo", mock.Anything, "session", "com.example.HelloWorld").
+		Return(&model.ServiceInfo{Name: "com.example.HelloWorld", Owner: "unknown"}, nil)
+
+	info, err := newClient(t, mockService).GetServiceInfo(context.Background(), &pb.GetServiceInfoRequest{Bus: "session", Service: "com.example.HelloWorld"})
+
+	require.NoError(t, err)
+	assert.Nil(t, info.Credentials)
+	assert.Nil(t, info.Process)
+	assert.False(t, info.AliasesTruncated)
+}
+
+func TestServer_InvalidRequest(t *testing.T) {
+	client := newClient(t, new(handlertest.MockDBusService))
+
+	_, err := client.ListServices(context.Background(), &pb.ListServicesRequest{Bus: "user"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.ListInterfaces(context.Background(), &pb.ObjectRequest{Bus: "session", Service: "a.b", Path: "relative"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.CallMethod(context.Background(), &pb.CallMethodRequest{Bus: "session", Service: "a.b", Interface: "a.b", Method: "M",
+		Args: []*pb.Value{stringValue("u", "1")}})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestServer_CallMethod(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethodWithFlags", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Hello",
+		model.CallFlags{}, []interface{}{"World", uint32(3)}).
+		Return(&model.MethodCallResult{Success: true, ReturnValues: []interface{}{"Hello, World!", []interface{}{int32(1), true}}}, nil)
+
+	response, err := newClient(t, mockService).CallMethod(context.Background(), &pb.CallMethodRequest{
+		Bus:       "session",
+		Service:   "com.example.HelloWorld",
+		Path:      "/com/example/HelloWorld",
+		Interface: "com.example.HelloWorld",
+		Method:    "Hello",
+		Args:      []*pb.Value{stringValue("s", "World"), uintValue("u", 3)},
+	})
+
+	require.NoError(t, err)
+	assert.True(t, response.Success)
+	require.Len(t, response.ReturnValues, 2)
+	assert.Equal(t, "Hello, World!", response.ReturnValues[0].GetStringValue())
+	assert.Equal(t, "(ib)", response.ReturnValues[1].GetSignature())
+	mockService.AssertExpectations(t)
+}
+
+func TestServer_CallMethodNoReply(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethodWithFlags", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld", "Hello", model.CallFlags{NoReply: true}, []interface{}{}).
+		Return(&model.MethodCallResult{Success: true, NoReply: true}, nil)
+
+	response, err := newClient(t, mockService).CallMethod(context.Background(), &pb.CallMethodRequest{
+		Bus: "session", Service: "com.example.HelloWorld", Interface: "com.example.HelloWorld", Method: "Hello", NoReply: true,
+	})
+
+	require.NoError(t, err)
+	assert.True(t, response.Success)
+	assert.True(t, response.NoReply)
+	mockService.AssertExpectations(t)
+}
+
+func TestServer_DBusError(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("GetProperty", mock.Anything, "session", "a.b", "/", "a.b", "Missing").
+		Return((*model.PropertyValue)(nil), dbus.Error{Name: "org.freedesktop.DBus.Error.UnknownProperty", Body: []interface{}{"no such property"}})
+
+	_, err := newClient(t, mockService).GetProperty(context.Background(), &pb.PropertyRequest{Bus: "session", Service: "a.b", Interface: "a.b", Property: "Missing"})
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.NotFound, st.Code())
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, "org.freedesktop.DBus.Error.UnknownProperty", info.Metadata["name"])
+}
+
+func TestDBusErrorCode(t *testing.T) {
+	assert.Equal(t, codes.InvalidArgument, dbusErrorCode("org.freedesktop.DBus.Error.MatchRuleInvalid"))
+	assert.Equal(t, codes.PermissionDenied, dbusErrorCode("org.freedesktop.DBus.Error.AccessDenied"))
+	assert.Equal(t, codes.DeadlineExceeded, dbusErrorCode("org.freedesktop.DBus.Error.NoReply"))
+	assert.Equal(t, codes.Unknown, dbusErrorCode("com.example.Error.Failed"))
+}
+
+func TestServer_SetProperty(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("SetProperty", mock.Anything, "session", "a.b", "/", "a.b", "Level", dbus.MakeVariant(uint32(3))).
+		Return(&model.PropertyValue{Name: "Level", Type: "u", Value: uint32(3)}, nil)
+
+	response, err := newClient(t, mockService).SetProperty(context.Background(), &pb.SetPropertyRequest{
+		Bus: "session", Service: "a.b", Interface: "a.b", Property: "Level", Value: uintValue("u", 3),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "u", response.Value.Signature)
+	assert.Equal(t, uint64(3), response.Value.GetUintValue())
+}
+
+func TestServer_StreamSignals(t *testing.T) {
+	events := make(chan *model.SignalEvent, 1)
+	events <- &model.SignalEvent{SubscriptionID: "session:a.b:a.b:Changed", Signal: "Changed", Body: []interface{}{"x"}}
+	close(events)
+
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("StreamSubscription", mock.Anything, "session:a.b:a.b:Changed").Return(events, nil)
+
+	stream, err := newClient(t, mockService).StreamSignals(context.Background(), &pb.StreamSignalsRequest{SubscriptionId: "session:a.b:a.b:Changed"})
+	require.NoError(t, err)
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "Changed", event.Signal)
+	assert.Equal(t, "x", event.Body[0].GetStringValue())
+
+	_, err = stream.Recv()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestServer_StreamSignals_NotFound(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("StreamSubscription", mock.Anything, "missing").Return(nil, service.ErrSubscriptionNotFound)
+
+	stream, err := newClient(t, mockService).StreamSignals(context.Background(), &pb.StreamSignalsRequest{SubscriptionId: "missing"})
+	require.NoError(t, err)
+
+	_, err = stream.Recv()
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
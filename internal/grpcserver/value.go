@@ -0,0 +1,266 @@
+package grpcserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/mesbrj/dbus-controller/internal/service"
+	pb "github.com/mesbrj/dbus-controller/proto/dbuscontroller/v1"
+)
+
+// fromValue converts a Value to the typed D-Bus value of its signature.
+// The kind of each Value is checked against its type, then the Value is
+// lowered to the JSON form accepted by service.ParseJSONArg.
+func fromValue(name string, value *pb.Value) (interface{}, error) {
+	if value == nil {
+		return nil, &service.ValueError{Path: name, Msg: "value is required"}
+	}
+	if types, err := service.SplitSignature(value.Signature); err != nil || len(types) != 1 {
+		return nil, &service.ValueError{Path: name, Msg: fmt.Sprintf("invalid signature %q", value.Signature)}
+	}
+	plain, err := plainValue(name, value.Signature, value)
+	if err != nil {
+		return nil, err
+	}
+	return service.ParseJSONArg(name, value.Signature, plain)
+}
+
+// plainValue lowers a Value of type typ to decoded JSON. Integers become
+// json.Number to keep their precision, and variants become {"signature", "value"} objects.
+func plainValue(path, typ string, value *pb.Value) (interface{}, error) {
+	mismatch := func() error {
+		return &service.ValueError{Path: path, Msg: fmt.Sprintf("%s does not match type %s", valueKind(value), typ)}
+	}
+
+	switch kind := value.GetKind().(type) {
+	case *pb.Value_BoolValue:
+		if typ != "b" {
+			return nil, mismatch()
+		}
+		return kind.BoolValue, nil
+	case *pb.Value_IntValue:
+		if typ != "n" && typ != "i" && typ != "x" {
+			return nil, mismatch()
+		}
+		return json.Number(strconv.FormatInt(kind.IntValue, 10)), nil
+	case *pb.Value_UintValue:
+		if typ != "y" && typ != "q" && typ != "u" && typ != "t" {
+			return nil, mismatch()
+		}
+		return json.Number(strconv.FormatUint(kind.UintValue, 10)), nil
+	case *pb.Value_DoubleValue:
+		if typ != "d" {
+			return nil, mismatch()
+		}
+		return kind.DoubleValue, nil
+	case *pb.Value_StringValue:
+		if typ != "s" && typ != "o" && typ != "g" {
+			return nil, mismatch()
+		}
+		return kind.StringValue, nil
+	case *pb.Value_ArrayValue:
+		if typ[0] != 'a' || typ[1] == '{' {
+			return nil, mismatch()
+		}
+		elements := kind.ArrayValue.GetElements()
+		plain := make([]interface{}, len(elements))
+		for i, element := range elements {
+			elem, err := plainValue(fmt.Sprintf("%s[%d]", path, i), typ[1:], element)
+			if err != nil {
+				return nil, err
+			}
+			plain[i] = elem
+		}
+		return plain, nil
+	case *pb.Value_StructValue:
+		if typ[0] != '(' {
+			return nil, mismatch()
+		}
+		members, _ := service.SplitSignature(typ[1 : len(typ)-1])
+		fields := kind.StructValue.GetFields()
+		if len(fields) != len(members) {
+			return nil, &service.ValueError{Path: path, Msg: fmt.Sprintf("expected %d struct members, got %d", len(members), len(fields))}
+		}
+		plain := make([]interface{}, len(fields))
+		for i, field := range fields {
+			elem, err := plainValue(fmt.Sprintf("%s[%d]", path, i), members[i], field)
+			if err != nil {
+				return nil, err
+			}
+			plain[i] = elem
+		}
+		return plain, nil
+	case *pb.Value_DictValue:
+		if !strings.HasPrefix(typ, "a{") {
+			return nil, mismatch()
+		}
+		entry, _ := service.SplitSignature(typ[2 : len(typ)-1])
+		object := make(map[string]interface{}, len(kind.DictValue.GetEntries()))
+		for i, dictEntry := range kind.DictValue.GetEntries() {
+			key, err := plainValue(fmt.Sprintf("%s{%d}", path, i), entry[0], dictEntry.GetKey())
+			if err != nil {
+				return nil, err
+			}
+			text := fmt.Sprint(key)
+			elem, err := plainValue(path+"."+text, entry[1], dictEntry.GetValue())
+			if err != nil {
+				return nil, err
+			}
+			object[text] = elem
+		}
+		return object, nil
+	case *pb.Value_VariantValue:
+		if typ != "v" {
+			return nil, mismatch()
+		}
+		inner := kind.VariantValue
+		if types, err := service.SplitSignature(inner.GetSignature()); err != nil || len(types) != 1 {
+			return nil, &service.ValueError{Path: path, Msg: fmt.Sprintf("invalid variant signature %q", inner.GetSignature())}
+		}
+		plain, err := plainValue(path+".value", inner.Signature, inner)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"signature": inner.Signature, "value": plain}, nil
+	default:
+		return nil, &service.ValueError{Path: path, Msg: "value is empty"}
+	}
+}
+
+// valueKind names the kind set on a Value
+func valueKind(value *pb.Value) string {
+	switch value.GetKind().(type) {
+	case *pb.Value_BoolValue:
+		return "bool_value"
+	case *pb.Value_IntValue:
+		return "int_value"
+	case *pb.Value_UintValue:
+		return "uint_value"
+	case *pb.Value_DoubleValue:
+		return "double_value"
+	case *pb.Value_StringValue:
+		return "string_value"
+	case *pb.Value_ArrayValue:
+		return "array_value"
+	case *pb.Value_StructValue:
+		return "struct_value"
+	case *pb.Value_DictValue:
+		return "dict_value"
+	case *pb.Value_VariantValue:
+		return "variant_value"
+	default:
+		return "no value"
+	}
+}
+
+// toValue converts a value decoded by godbus to a Value. Structs are decoded
+// as []interface{}, so their signature is built from their fields; the element
+// signature of arrays and dictionaries is taken from their first element.
+func toValue(v interface{}) *pb.Value {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case dbus.Variant:
+		inner := toValue(x.Value())
+		if inner == nil {
+			inner = &pb.Value{}
+		}
+		inner.Signature = x.Signature().String()
+		return &pb.Value{Signature: "v", Kind: &pb.Value_VariantValue{VariantValue: inner}}
+	case dbus.ObjectPath:
+		return &pb.Value{Signature: "o", Kind: &pb.Value_StringValue{StringValue: string(x)}}
+	case dbus.Signature:
+		return &pb.Value{Signature: "g", Kind: &pb.Value_StringValue{StringValue: x.String()}}
+	case dbus.UnixFDIndex:
+		return &pb.Value{Signature: "h", Kind: &pb.Value_UintValue{UintValue: uint64(x)}}
+	case dbus.UnixFD:
+		return &pb.Value{Signature: "h", Kind: &pb.Value_UintValue{UintValue: uint64(x)}}
+	case []interface{}:
+		return structValue(x)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return &pb.Value{Signature: "b", Kind: &pb.Value_BoolValue{BoolValue: rv.Bool()}}
+	case reflect.Int16, reflect.Int32, reflect.Int64, reflect.Int:
+		return &pb.Value{Signature: dbus.SignatureOf(v).String(), Kind: &pb.Value_IntValue{IntValue: rv.Int()}}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint:
+		return &pb.Value{Signature: dbus.SignatureOf(v).String(), Kind: &pb.Value_UintValue{UintValue: rv.Uint()}}
+	case reflect.Float64:
+		return &pb.Value{Signature: "d", Kind: &pb.Value_DoubleValue{DoubleValue: rv.Float()}}
+	case reflect.String:
+		return &pb.Value{Signature: "s", Kind: &pb.Value_StringValue{StringValue: rv.String()}}
+	case reflect.Slice, reflect.Array:
+		elements := make([]*pb.Value, rv.Len())
+		for i := range elements {
+			elements[i] = toValue(rv.Index(i).Interface())
+		}
+		elemSignature := typeSignature(rv.Type().Elem())
+		if len(elements) > 0 {
+			elemSignature = elements[0].GetSignature()
+		}
+		return &pb.Value{Signature: "a" + elemSignature, Kind: &pb.Value_ArrayValue{ArrayValue: &pb.Array{Elements: elements}}}
+	case reflect.Map:
+		entries := make([]*pb.DictEntry, 0, rv.Len())
+		for _, key := range sortedMapKeys(rv) {
+			entries = append(entries, &pb.DictEntry{Key: toValue(key.Interface()), Value: toValue(rv.MapIndex(key).Interface())})
+		}
+		keySignature, valueSignature := typeSignature(rv.Type().Key()), typeSignature(rv.Type().Elem())
+		if len(entries) > 0 {
+			keySignature, valueSignature = entries[0].Key.GetSignature(), entries[0].Value.GetSignature()
+		}
+		return &pb.Value{Signature: "a{" + keySignature + valueSignature + "}", Kind: &pb.Value_DictValue{DictValue: &pb.Dict{Entries: entries}}}
+	case reflect.Struct:
+		fields := make([]interface{}, rv.NumField())
+		for i := range fields {
+			fields[i] = rv.Field(i).Interface()
+		}
+		return structValue(fields)
+	default:
+		// Not a D-Bus value; keep its text without a signature
+		return &pb.Value{Kind: &pb.Value_StringValue{StringValue: fmt.Sprint(v)}}
+	}
+}
+
+// structValue converts the fields of a struct to a Value
+func structValue(fields []interface{}) *pb.Value {
+	values := make([]*pb.Value, len(fields))
+	signature := "("
+	for i, field := range fields {
+		values[i] = toValue(field)
+		signature += values[i].GetSignature()
+	}
+	return &pb.Value{Signature: signature + ")", Kind: &pb.Value_StructValue{StructValue: &pb.Struct{Fields: values}}}
+}
+
+// toValues converts a message body to Values
+func toValues(body []interface{}) []*pb.Value {
+	values := make([]*pb.Value, len(body))
+	for i, v := range body {
+		values[i] = toValue(v)
+	}
+	return values
+}
+
+// typeSignature returns the signature of the values of an empty array or
+// dictionary. Empty arrays of structs cannot be told apart from arrays of
+// variant arrays, and are reported as the latter.
+func typeSignature(t reflect.Type) string {
+	return dbus.SignatureOfType(t).String()
+}
+
+// sortedMapKeys returns the keys of a map in order, for deterministic responses
+func sortedMapKeys(rv reflect.Value) []reflect.Value {
+	keys := rv.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	return keys
+}
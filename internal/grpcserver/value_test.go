@@ -0,0 +1,145 @@
+package grpcserver
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/mesbrj/dbus-controller/proto/dbuscontroller/v1"
+)
+
+func uintValue(signature string, v uint64) *pb.Value {
+	return &pb.Value{Signature: signature, Kind: &pb.Value_UintValue{UintValue: v}}
+}
+
+func stringValue(signature, v string) *pb.Value {
+	return &pb.Value{Signature: signature, Kind: &pb.Value_StringValue{StringValue: v}}
+}
+
+func TestFromValue(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    *pb.Value
+		expected interface{}
+	}{
+		{"uint64", uintValue("t", 1<<63), uint64(1 << 63)},
+		{"object path", stringValue("o", "/a/b"), dbus.ObjectPath("/a/b")},
+		{
+			"array",
+			&pb.Value{Signature: "ai", Kind: &pb.Value_ArrayValue{ArrayValue: &pb.Array{Elements: []*pb.Value{
+				{Kind: &pb.Value_IntValue{IntValue: -1}},
+				{Kind: &pb.Value_IntValue{IntValue: 2}},
+			}}}},
+			[]int32{-1, 2},
+		},
+		{
+			"dictionary of variants",
+			&pb.Value{Signature: "a{sv}", Kind: &pb.Value_DictValue{DictValue: &pb.Dict{Entries: []*pb.DictEntry{
+				{Key: stringValue("", "level"), Value: &pb.Value{Kind: &pb.Value_VariantValue{VariantValue: uintValue("y", 3)}}},
+			}}}},
+			map[string]dbus.Variant{"level": dbus.MakeVariant(byte(3))},
+		},
+		{
+			"dictionary with integer keys",
+			&pb.Value{Signature: "a{us}", Kind: &pb.Value_DictValue{DictValue: &pb.Dict{Entries: []*pb.DictEntry{
+				{Key: uintValue("", 7), Value: stringValue("", "seven")},
+			}}}},
+			map[uint32]string{7: "seven"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := fromValue("arg", tt.value)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestFromValue_Struct(t *testing.T) {
+	value, err := fromValue("arg", &pb.Value{Signature: "(sb)", Kind: &pb.Value_StructValue{StructValue: &pb.Struct{Fields: []*pb.Value{
+		stringValue("", "a"),
+		{Kind: &pb.Value_BoolValue{BoolValue: true}},
+	}}}})
+	require.NoError(t, err)
+
+	assert.Equal(t, "(sb)", dbus.SignatureOf(value).String())
+	assert.Equal(t, "(sb)", toValue(value).GetSignature())
+}
+
+func TestFromValue_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		value *pb.Value
+	}{
+		{"missing value", nil},
+		{"missing signature", uintValue("", 1)},
+		{"several types", uintValue("uu", 1)},
+		{"empty value", &pb.Value{Signature: "u"}},
+		{"type mismatch", stringValue("u", "1")},
+		{"signed for unsigned", &pb.Value{Signature: "u", Kind: &pb.Value_IntValue{IntValue: 1}}},
+		{"out of range", uintValue("y", 256)},
+		{"array element mismatch", &pb.Value{Signature: "as", Kind: &pb.Value_ArrayValue{ArrayValue: &pb.Array{Elements: []*pb.Value{uintValue("", 1)}}}}},
+		{"struct member count", &pb.Value{Signature: "(ss)", Kind: &pb.Value_StructValue{StructValue: &pb.Struct{Fields: []*pb.Value{stringValue("", "a")}}}}},
+		{"invalid object path", stringValue("o", "relative")},
+		{"variant without signature", &pb.Value{Signature: "v", Kind: &pb.Value_VariantValue{VariantValue: uintValue("", 1)}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fromValue("arg", tt.value)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestToValue(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		signature string
+	}{
+		{"int16", int16(-1), "n"},
+		{"byte", byte(1), "y"},
+		{"signature", dbus.Signature{}, "g"},
+		{"string array", []string{"a"}, "as"},
+		{"empty string array", []string{}, "as"},
+		{"struct", []interface{}{"a", uint32(1)}, "(su)"},
+		{"array of structs", [][]interface{}{{"a", uint32(1)}}, "a(su)"},
+		{"dictionary of variants", map[string]dbus.Variant{"a": dbus.MakeVariant(int32(1))}, "a{sv}"},
+		{"empty dictionary", map[string]dbus.Variant{}, "a{sv}"},
+		{"object path dictionary", map[dbus.ObjectPath]map[string]dbus.Variant{"/a": {}}, "a{oa{sv}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.signature, toValue(tt.value).GetSignature())
+		})
+	}
+}
+
+func TestToValue_Variant(t *testing.T) {
+	value := toValue(dbus.MakeVariant(map[string]dbus.Variant{"level": dbus.MakeVariant(uint32(3))}))
+
+	assert.Equal(t, "v", value.Signature)
+	inner := value.GetVariantValue()
+	assert.Equal(t, "a{sv}", inner.Signature)
+	entry := inner.GetDictValue().GetEntries()[0]
+	assert.Equal(t, "level", entry.Key.GetStringValue())
+	assert.Equal(t, "u", entry.Value.GetVariantValue().GetSignature())
+	assert.Equal(t, uint64(3), entry.Value.GetVariantValue().GetUintValue())
+}
+
+func TestValue_RoundTrip(t *testing.T) {
+	original := map[string]dbus.Variant{
+		"name":  dbus.MakeVariant("a"),
+		"items": dbus.MakeVariant([]uint64{1 << 63}),
+	}
+
+	value, err := fromValue("arg", toValue(original))
+	require.NoError(t, err)
+	assert.Equal(t, original, value)
+}
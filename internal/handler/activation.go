@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-fuego/fuego"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// StartService starts an activatable service by name
+func (h *Handler) StartService(c fuego.ContextNoBody) (*model.ServiceStart, error) {
+	busType := c.PathParam("busType")
+	serviceName := c.PathParam("serviceName")
+	return h.dbusService.StartService(c.Context(), busType, serviceName)
+}
+
+// UpdateActivationEnvironment adds or replaces variables of the environment
+// of activated services
+func (h *Handler) UpdateActivationEnvironment(c *fuego.ContextWithBody[model.ActivationEnvironment]) (any, error) {
+	busType := c.PathParam("busType")
+
+	body, err := c.Body()
+	if err != nil {
+		slog.WarnContext(c.Context(), "Invalid activation environment body", "error", err)
+		return nil, fuego.BadRequestError{Title: "Invalid request body", Detail: err.Error()}
+	}
+	environment := body.Environment
+	if len(environment) == 0 {
+		return nil, fuego.BadRequestError{Title: "Invalid request body", Detail: "no variables given", Err: fmt.Errorf("empty environment")}
+	}
+
+	var invalid []fuego.ErrorItem
+	for name := range environment {
+		if name == "" || strings.ContainsAny(name, "=\x00") {
+			invalid = append(invalid, fuego.ErrorItem{Name: name, Reason: "invalid variable name"})
+		}
+	}
+	if len(invalid) > 0 {
+		sort.Slice(invalid, func(i, j int) bool { return invalid[i].Name < invalid[j].Name })
+		return nil, fuego.BadRequestError{Title: "Invalid request body", Detail: "invalid variable names", Errors: invalid, Err: fmt.Errorf("invalid environment")}
+	}
+
+	if err := h.dbusService.UpdateActivationEnvironment(c.Context(), busType, environment); err != nil {
+		return nil, err
+	}
+
+	c.SetStatus(http.StatusNoContent)
+	return nil, nil
+}
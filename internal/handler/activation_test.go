@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-fuego/fuego"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbrj/dbus-controller/internal/handler/handlertest"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+func TestHandler_StartService(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("StartService", mock.Anything, "session", "com.example.Flash").
+		Return(&model.ServiceStart{Name: "com.example.Flash", Reply: "success", Owner: ":1.12"}, nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/services/{serviceName}/start", NewHandler(mockService).StartService)
+	}, http.MethodPost, "/buses/session/services/com.example.Flash/start", "")
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"name":"com.example.Flash","reply":"success","owner":":1.12"}`, rec.Body.String())
+}
+
+func TestHandler_StartService_Unknown(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("StartService", mock.Anything, "session", "com.example.Missing").
+		Return(nil, dbus.Error{Name: "org.freedesktop.DBus.Error.ServiceUnknown", Body: []interface{}{"The name is not activatable"}})
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/services/{serviceName}/start", NewHandler(mockService).StartService)
+	}, http.MethodPost, "/buses/session/services/com.example.Missing/start", "")
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHandler_UpdateActivationEnvironment(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("UpdateActivationEnvironment", mock.Anything, "session", map[string]string{"LANG": "C.UTF-8"}).Return(nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Patch(s, "/buses/{busType}/activation/environment", NewHandler(mockService).UpdateActivationEnvironment)
+	}, http.MethodPatch, "/buses/session/activation/environment", `{"environment": {"LANG": "C.UTF-8"}}`)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_UpdateActivationEnvironment_Invalid(t *testing.T) {
+	for _, body := range []string{`{}`, `{"environment": {"A=B": "c"}}`, `{"environment": {"": "c"}}`, `{"environment": {"LANG": 1}}`} {
+		mockService := new(handlertest.MockDBusService)
+
+		rec := serveRoute(func(s *fuego.Server) {
+			fuego.Patch(s, "/buses/{busType}/activation/environment", NewHandler(mockService).UpdateActivationEnvironment)
+		}, http.MethodPatch, "/buses/session/activation/environment", body)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+		mockService.AssertNotCalled(t, "UpdateActivationEnvironment")
+	}
+}
@@ -0,0 +1,344 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/go-fuego/fuego"
+	"github.com/godbus/dbus/v5"
+
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// maxBatchOperations bounds the operations of one batch
+const maxBatchOperations = 100
+
+// Outcomes of batch operations
+const (
+	batchOK      = "ok"
+	batchFailed  = "failed"
+	batchSkipped = "skipped"
+)
+
+// batchID matches the IDs of batch operations, which must be usable in references
+var batchID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Batch runs the operations of a batch in order and reports the outcome of
+// each. Consecutive independent operations run in parallel. Operations that
+// completed are not undone when a later one fails.
+func (h *Handler) Batch(c *fuego.ContextWithBody[model.BatchRequest]) (*model.BatchResponse, error) {
+	body, err := c.Body()
+	if err != nil {
+		slog.WarnContext(c.Context(), "Invalid batch body", "error", err)
+		return nil, fuego.BadRequestError{Title: "Invalid request body", Detail: err.Error()}
+	}
+
+	steps, err := batchSteps(body.Operations)
+	if err != nil {
+		return nil, fuego.BadRequestError{Title: "Invalid batch", Detail: err.Error(), Err: err}
+	}
+
+	slog.InfoContext(c.Context(), "Running batch", "operations", len(body.Operations), "steps", len(steps), "stop_on_error", body.StopOnError)
+
+	response := &model.BatchResponse{Success: true, Results: make([]model.BatchResult, len(body.Operations))}
+	// Results of the succeeded operations by ID; only written between steps
+	results := make(map[string]any)
+	scope := map[string]any{"results": results}
+
+	for _, step := range steps {
+		if !response.Success && body.StopOnError {
+			for _, i := range step {
+				response.Results[i] = model.BatchResult{ID: body.Operations[i].ID, Op: body.Operations[i].Op, Status: batchSkipped}
+			}
+			continue
+		}
+
+		if len(step) == 1 {
+			response.Results[step[0]] = h.runBatchOperation(c.Context(), &body.Operations[step[0]], scope)
+		} else {
+			var wg sync.WaitGroup
+			for _, i := range step {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					response.Results[i] = h.runBatchOperation(c.Context(), &body.Operations[i], scope)
+				}()
+			}
+			wg.Wait()
+		}
+
+		for _, i := range step {
+			result := &response.Results[i]
+			if result.Status == batchFailed {
+				response.Success = false
+			} else if result.ID != "" {
+				results[result.ID] = result.Result
+			}
+		}
+	}
+
+	return response, nil
+}
+
+// batchSteps validates the operations of a batch and groups them into the
+// steps run in order: one operation, or a run of consecutive independent ones
+func batchSteps(ops []model.BatchOperation) ([][]int, error) {
+	if len(ops) == 0 {
+		return nil, errors.New("operations must not be empty")
+	}
+	if len(ops) > maxBatchOperations {
+		return nil, fmt.Errorf("at most %d operations are allowed, got %d", maxBatchOperations, len(ops))
+	}
+
+	var steps [][]int
+	defined := make(map[string]int) // Step of each operation ID
+	for i := range ops {
+		op := &ops[i]
+		if op.Independent && i > 0 && ops[i-1].Independent {
+			steps[len(steps)-1] = append(steps[len(steps)-1], i)
+		} else {
+			steps = append(steps, []int{i})
+		}
+		step := len(steps) - 1
+
+		if err := validateBatchOperation(op); err != nil {
+			return nil, fmt.Errorf("operations[%d]: %w", i, err)
+		}
+
+		refs, err := batchRefs(op)
+		if err != nil {
+			return nil, fmt.Errorf("operations[%d]: %w", i, err)
+		}
+		for _, ref := range refs {
+			if len(ref) < 2 {
+				return nil, fmt.Errorf("operations[%d]: {results} must name an operation", i)
+			}
+			refStep, ok := defined[ref[1]]
+			if !ok {
+				return nil, fmt.Errorf("operations[%d]: {%s} does not name an earlier operation", i, strings.Join(ref, "."))
+			}
+			if refStep == step {
+				return nil, fmt.Errorf("operations[%d]: {%s} names an operation run in parallel", i, strings.Join(ref, "."))
+			}
+		}
+
+		if op.ID != "" {
+			if !batchID.MatchString(op.ID) {
+				return nil, fmt.Errorf("operations[%d]: id %q must only contain letters, digits, '_' and '-'", i, op.ID)
+			}
+			if _, ok := defined[op.ID]; ok {
+				return nil, fmt.Errorf("operations[%d]: duplicate id %q", i, op.ID)
+			}
+			defined[op.ID] = step
+		}
+	}
+	return steps, nil
+}
+
+// validateBatchOperation checks the fields required by the kind of an operation
+func validateBatchOperation(op *model.BatchOperation) error {
+	if op.Bus != "system" && op.Bus != "session" {
+		return fmt.Errorf("invalid bus type: %q", op.Bus)
+	}
+	if op.Service == "" || op.Interface == "" {
+		return errors.New("service and interface are required")
+	}
+	if op.Path != "" && !strings.Contains(op.Path, "{results.") && !dbus.ObjectPath(op.Path).IsValid() {
+		return fmt.Errorf("invalid object path: %s", op.Path)
+	}
+
+	switch op.Op {
+	case "call":
+		if op.Method == "" || op.Property != "" || op.Value != nil {
+			return errors.New("call requires method, without property or value")
+		}
+		if op.Signature != "" {
+			types, err := service.SplitSignature(op.Signature)
+			if err != nil {
+				return fmt.Errorf("signature: %w", err)
+			}
+			if len(types) != len(op.Args) {
+				return fmt.Errorf("signature %q has %d types for %d args", op.Signature, len(types), len(op.Args))
+			}
+		}
+	case "get":
+		if op.Property == "" || op.Method != "" || op.Args != nil || op.Value != nil || op.Signature != "" {
+			return errors.New("get requires property, without method, args, value or signature")
+		}
+	case "set":
+		if op.Property == "" || op.Method != "" || op.Args != nil || op.Value == nil {
+			return errors.New("set requires property and value, without method or args")
+		}
+		if op.Signature != "" {
+			if types, err := service.SplitSignature(op.Signature); err != nil || len(types) != 1 {
+				return fmt.Errorf("signature %q must be one complete type", op.Signature)
+			}
+		}
+	default:
+		return fmt.Errorf("op must be 'call', 'get' or 'set', got %q", op.Op)
+	}
+	return nil
+}
+
+// batchRefs returns the references of the path, args and value of an operation
+func batchRefs(op *model.BatchOperation) ([][]string, error) {
+	var refs [][]string
+	var walk func(value any) error
+	walk = func(value any) error {
+		switch v := value.(type) {
+		case string:
+			if !strings.Contains(v, "{results") {
+				return nil
+			}
+			t, err := parseRouteTemplate(v, "results")
+			if err != nil {
+				return err
+			}
+			refs = append(refs, t.refs...)
+		case []any:
+			for _, item := range v {
+				if err := walk(item); err != nil {
+					return err
+				}
+			}
+		case map[string]any:
+			for _, item := range v {
+				if err := walk(item); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	for _, value := range []any{op.Path, op.Args, op.Value} {
+		if err := walk(value); err != nil {
+			return nil, err
+		}
+	}
+	return refs, nil
+}
+
+// resolveRefs replaces the strings of a JSON value referencing earlier results.
+// A string made of a single reference takes the referenced value.
+func resolveRefs(value any, scope map[string]any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		if !strings.Contains(v, "{results") {
+			return v, nil
+		}
+		t, err := parseRouteTemplate(v, "results")
+		if err != nil {
+			return nil, err
+		}
+		return t.evaluate(scope)
+	case []any:
+		resolved := make([]any, len(v))
+		for i, item := range v {
+			var err error
+			if resolved[i], err = resolveRefs(item, scope); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	case map[string]any:
+		resolved := make(map[string]any, len(v))
+		for key, item := range v {
+			var err error
+			if resolved[key], err = resolveRefs(item, scope); err != nil {
+				return nil, err
+			}
+		}
+		return resolved, nil
+	default:
+		return value, nil
+	}
+}
+
+// runBatchOperation runs one operation, reporting its failure in the result
+func (h *Handler) runBatchOperation(ctx context.Context, op *model.BatchOperation, scope map[string]any) model.BatchResult {
+	result := model.BatchResult{ID: op.ID, Op: op.Op, Status: batchOK}
+
+	value, err := h.batchOperation(ctx, op, scope)
+	if err != nil {
+		slog.WarnContext(ctx, "Batch operation failed", "id", op.ID, "op", op.Op, "error", err)
+		result.Status = batchFailed
+		result.Error = err.Error()
+		result.ErrorName = service.ErrorName(err)
+		return result
+	}
+
+	result.Result = value
+	return result
+}
+
+// batchOperation resolves the references of an operation and runs it. The
+// result of a call is its return values, and that of get or set the property value.
+func (h *Handler) batchOperation(ctx context.Context, op *model.BatchOperation, scope map[string]any) (any, error) {
+	objectPath := "/"
+	if op.Path != "" {
+		value, err := resolveRefs(op.Path, scope)
+		if err != nil {
+			return nil, err
+		}
+		objectPath = fmt.Sprint(value)
+		if !dbus.ObjectPath(objectPath).IsValid() {
+			return nil, fmt.Errorf("invalid object path: %s", objectPath)
+		}
+	}
+
+	switch op.Op {
+	case "get":
+		property, err := h.dbusService.GetProperty(ctx, op.Bus, op.Service, objectPath, op.Interface, op.Property)
+		if err != nil {
+			return nil, err
+		}
+		return property.Value, nil
+
+	case "set":
+		value, err := resolveRefs(op.Value, scope)
+		if err != nil {
+			return nil, err
+		}
+		if op.Signature != "" {
+			if value, err = service.ParseJSONArg("value", op.Signature, service.ToJSONArg(value)); err != nil {
+				return nil, err
+			}
+		}
+		property, err := h.dbusService.SetProperty(ctx, op.Bus, op.Service, objectPath, op.Interface, op.Property, value)
+		if err != nil {
+			return nil, err
+		}
+		return property.Value, nil
+
+	default:
+		resolved, err := resolveRefs([]any(op.Args), scope)
+		if err != nil {
+			return nil, err
+		}
+		args := resolved.([]any)
+		if op.Signature != "" {
+			types, _ := service.SplitSignature(op.Signature)
+			for i, arg := range args {
+				if args[i], err = service.ParseJSONArg(fmt.Sprintf("args[%d]", i), types[i], service.ToJSONArg(arg)); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		callResult, err := h.dbusService.CallMethod(ctx, op.Bus, op.Service, objectPath, op.Interface, op.Method, args)
+		if err != nil {
+			return nil, err
+		}
+		if err := resultError(callResult); err != nil {
+			return nil, err
+		}
+		return callResult.ReturnValues, nil
+	}
+}
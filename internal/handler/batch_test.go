@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-fuego/fuego"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbrj/dbus-controller/internal/handler/handlertest"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+func TestHandler_Batch_References(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethod", mock.Anything, "session", "com.example.Manager", "/", "com.example.Manager", "Create", []interface{}{"web"}).
+		Return(&model.MethodCallResult{Success: true, ReturnValues: []interface{}{dbus.ObjectPath("/com/example/Unit/1"), uint32(7)}}, nil)
+	mockService.On("SetProperty", mock.Anything, "session", "com.example.Manager", "/com/example/Unit/1", "com.example.Unit", "Limit", uint64(7)).
+		Return(&model.PropertyValue{Name: "Limit", Value: uint64(7)}, nil)
+	mockService.On("CallMethod", mock.Anything, "session", "com.example.Manager", "/com/example/Unit/1", "com.example.Unit", "Start",
+		[]interface{}{"unit 7 of /com/example/Unit/1"}).
+		Return(&model.MethodCallResult{Success: true}, nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/batch", NewHandler(mockService).Batch)
+	}, http.MethodPost, "/batch", `{"operations": [
+		{"id": "unit", "op": "call", "bus": "session", "service": "com.example.Manager", "interface": "com.example.Manager", "method": "Create", "args": ["web"]},
+		{"op": "set", "bus": "session", "service": "com.example.Manager", "path": "{results.unit.0}", "interface": "com.example.Unit",
+			"property": "Limit", "value": "{results.unit.1}", "signature": "t"},
+		{"op": "call", "bus": "session", "service": "com.example.Manager", "path": "{results.unit.0}", "interface": "com.example.Unit",
+			"method": "Start", "args": ["unit {results.unit.1} of {results.unit.0}"]}
+	]}`)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"success": true, "results": [
+		{"id": "unit", "op": "call", "status": "ok", "result": ["/com/example/Unit/1", 7]},
+		{"op": "set", "status": "ok", "result": 7},
+		{"op": "call", "status": "ok", "result": null}
+	]}`, rec.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_Batch_StopOnError(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethod", mock.Anything, "session", "a.b", "/", "a.b", "Fail", []interface{}{}).
+		Return(&model.MethodCallResult{Success: false, Error: "denied", ErrorName: "org.freedesktop.DBus.Error.AccessDenied"}, nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/batch", NewHandler(mockService).Batch)
+	}, http.MethodPost, "/batch", `{"stop_on_error": true, "operations": [
+		{"op": "call", "bus": "session", "service": "a.b", "interface": "a.b", "method": "Fail"},
+		{"op": "get", "bus": "session", "service": "a.b", "interface": "a.b", "property": "Level"}
+	]}`)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"success": false, "results": [
+		{"op": "call", "status": "failed", "error": "denied", "error_name": "org.freedesktop.DBus.Error.AccessDenied"},
+		{"op": "get", "status": "skipped"}
+	]}`, rec.Body.String())
+	mockService.AssertNotCalled(t, "GetProperty")
+}
+
+func TestHandler_Batch_ContinueAfterError(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("GetProperty", mock.Anything, "session", "a.b", "/", "a.b", "Missing").
+		Return((*model.PropertyValue)(nil), dbus.Error{Name: "org.freedesktop.DBus.Error.UnknownProperty", Body: []interface{}{"no such property"}})
+	mockService.On("GetProperty", mock.Anything, "session", "a.b", "/", "a.b", "Level").
+		Return(&model.PropertyValue{Name: "Level", Value: uint32(3)}, nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/batch", NewHandler(mockService).Batch)
+	}, http.MethodPost, "/batch", `{"operations": [
+		{"id": "missing", "op": "get", "bus": "session", "service": "a.b", "interface": "a.b", "property": "Missing"},
+		{"op": "get", "bus": "session", "service": "a.b", "interface": "a.b", "property": "Level"},
+		{"op": "call", "bus": "session", "service": "a.b", "interface": "a.b", "method": "Use", "args": ["{results.missing}"]}
+	]}`)
+
+	var response model.BatchResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.False(t, response.Success)
+	assert.Equal(t, "org.freedesktop.DBus.Error.UnknownProperty", response.Results[0].ErrorName)
+	assert.Equal(t, "ok", response.Results[1].Status)
+	assert.Equal(t, "failed", response.Results[2].Status)
+	assert.Contains(t, response.Results[2].Error, "{results.missing} is missing")
+	mockService.AssertNotCalled(t, "CallMethod")
+}
+
+func TestHandler_Batch_Independent(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	for _, name := range []string{"A", "B", "C"} {
+		mockService.On("GetProperty", mock.Anything, "session", "a.b", "/", "a.b", name).
+			Return(&model.PropertyValue{Name: name, Value: name}, nil)
+	}
+	mockService.On("CallMethod", mock.Anything, "session", "a.b", "/", "a.b", "Join", []interface{}{"A", "B", "C"}).
+		Return(&model.MethodCallResult{Success: true, ReturnValues: []interface{}{"ABC"}}, nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/batch", NewHandler(mockService).Batch)
+	}, http.MethodPost, "/batch", `{"operations": [
+		{"id": "a", "op": "get", "bus": "session", "service": "a.b", "interface": "a.b", "property": "A", "independent": true},
+		{"id": "b", "op": "get", "bus": "session", "service": "a.b", "interface": "a.b", "property": "B", "independent": true},
+		{"id": "c", "op": "get", "bus": "session", "service": "a.b", "interface": "a.b", "property": "C", "independent": true},
+		{"op": "call", "bus": "session", "service": "a.b", "interface": "a.b", "method": "Join",
+			"args": ["{results.a}", "{results.b}", "{results.c}"], "signature": "sss"}
+	]}`)
+
+	var response model.BatchResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.True(t, response.Success)
+	assert.Equal(t, []interface{}{"ABC"}, response.Results[3].Result)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_Batch_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		detail string
+	}{
+		{"empty", `{"operations": []}`, "must not be empty"},
+		{"unknown op", `{"operations": [{"op": "emit", "bus": "session", "service": "a.b", "interface": "a.b"}]}`, "op must be"},
+		{"invalid bus", `{"operations": [{"op": "get", "bus": "user", "service": "a.b", "interface": "a.b", "property": "P"}]}`, "invalid bus type"},
+		{"get with args", `{"operations": [{"op": "get", "bus": "session", "service": "a.b", "interface": "a.b", "property": "P", "args": [1]}]}`, "get requires"},
+		{"signature count", `{"operations": [{"op": "call", "bus": "session", "service": "a.b", "interface": "a.b", "method": "M", "args": [1], "signature": "uu"}]}`, "2 types for 1 args"},
+		{"invalid path", `{"operations": [{"op": "get", "bus": "session", "service": "a.b", "path": "a", "interface": "a.b", "property": "P"}]}`, "invalid object path"},
+		{"duplicate id", `{"operations": [
+			{"id": "x", "op": "get", "bus": "session", "service": "a.b", "interface": "a.b", "property": "P"},
+			{"id": "x", "op": "get", "bus": "session", "service": "a.b", "interface": "a.b", "property": "P"}]}`, "duplicate id"},
+		{"forward reference", `{"operations": [
+			{"op": "call", "bus": "session", "service": "a.b", "interface": "a.b", "method": "M", "args": ["{results.x}"]},
+			{"id": "x", "op": "get", "bus": "session", "service": "a.b", "interface": "a.b", "property": "P"}]}`, "does not name an earlier operation"},
+		{"parallel reference", `{"operations": [
+			{"id": "x", "op": "get", "bus": "session", "service": "a.b", "interface": "a.b", "property": "P", "independent": true},
+			{"op": "call", "bus": "session", "service": "a.b", "interface": "a.b", "method": "M", "args": ["{results.x}"], "independent": true}]}`, "run in parallel"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveRoute(func(s *fuego.Server) {
+				fuego.Post(s, "/batch", NewHandler(new(handlertest.MockDBusService)).Batch)
+			}, http.MethodPost, "/batch", tt.body)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), tt.detail)
+		})
+	}
+}
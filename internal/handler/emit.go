@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"path"
+
+	"github.com/go-fuego/fuego"
+	"github.com/mesbrj/dbus-controller/internal/config"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// SignalEmitter contains the HTTP handler emitting the D-Bus signals allowed
+// by the signal configuration
+type SignalEmitter struct {
+	dbusService service.DBusServiceInterface
+	allow       []config.SignalRule
+}
+
+// NewSignalEmitter creates a signal emitter allowing the signals matching a rule
+func NewSignalEmitter(dbusService service.DBusServiceInterface, allow []config.SignalRule) *SignalEmitter {
+	return &SignalEmitter{
+		dbusService: dbusService,
+		allow:       allow,
+	}
+}
+
+// EmitSignal emits a D-Bus signal from the connection of the controller,
+// answering 403 for the signals not allowed by the configuration
+func (h *SignalEmitter) EmitSignal(c *fuego.ContextWithBody[model.EmitSignalRequest]) (any, error) {
+	busType := c.PathParam("busType")
+
+	body, err := c.Body()
+	if err != nil {
+		slog.WarnContext(c.Context(), "Invalid signal body", "error", err)
+		return nil, fuego.BadRequestError{Title: "Invalid request body", Detail: err.Error()}
+	}
+	if body.Path == "" || body.Interface == "" || body.Member == "" {
+		return nil, fuego.BadRequestError{Title: "Invalid request body", Detail: "path, interface and member are required", Err: fmt.Errorf("incomplete signal")}
+	}
+
+	if !h.allowed(body) {
+		err := fmt.Errorf("signal %s.%s to %q is not allowed", body.Interface, body.Member, body.Destination)
+		slog.WarnContext(c.Context(), "Signal emission denied", "bus", busType, "interface", body.Interface, "member", body.Member, "destination", body.Destination)
+		return nil, fuego.ForbiddenError{Title: "Signal not allowed", Detail: err.Error(), Err: err}
+	}
+
+	end := startConversion(c.Context())
+	args, err := signalArgs(body)
+	end(err)
+	if err != nil {
+		return nil, err
+	}
+
+	err = h.dbusService.EmitSignal(c.Context(), busType, body.Destination, body.Path, body.Interface, body.Member, args)
+	if errors.Is(err, service.ErrInvalidSignal) {
+		return nil, fuego.BadRequestError{Title: "Invalid signal", Detail: err.Error(), Err: err}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	c.SetStatus(http.StatusNoContent)
+	return nil, nil
+}
+
+// allowed reports whether a rule allows the signal
+func (h *SignalEmitter) allowed(signal model.EmitSignalRequest) bool {
+	for _, rule := range h.allow {
+		if matchPattern(rule.Interface, signal.Interface) &&
+			(rule.Member == "" || matchPattern(rule.Member, signal.Member)) &&
+			matchPattern(rule.Destination, signal.Destination) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern reports whether name matches a glob pattern, validated with the configuration
+func matchPattern(pattern, name string) bool {
+	matched, _ := path.Match(pattern, name)
+	return matched
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-fuego/fuego"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mesbrj/dbus-controller/internal/config"
+	"github.com/mesbrj/dbus-controller/internal/handler/handlertest"
+	"github.com/mesbrj/dbus-controller/internal/service"
+)
+
+// allowAllSignals allows any signal, broadcast or unicast
+var allowAllSignals = []config.SignalRule{{Interface: "*", Member: "*", Destination: "*"}}
+
+func TestSignalEmitter_EmitSignal(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("EmitSignal", mock.Anything, "session", ":1.42", "/com/example/Door", "com.example.Door", "Opened",
+		[]interface{}{"front", uint32(3), map[string]dbus.Variant{"by": dbus.MakeVariant("badge")}}).
+		Return(nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/signals", NewSignalEmitter(mockService, allowAllSignals).EmitSignal)
+	}, http.MethodPost, "/buses/session/signals", `{"path": "/com/example/Door", "interface": "com.example.Door", "member": "Opened", "destination": ":1.42",
+		"signature": "sua{sv}", "args": ["front", 3, {"by": "badge"}]}`)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestSignalEmitter_EmitSignal_TextArgs(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("EmitSignal", mock.Anything, "session", "", "/", "com.example.Door", "Opened", []interface{}{"front", int64(-1)}).
+		Return(nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/signals", NewSignalEmitter(mockService, allowAllSignals).EmitSignal)
+	}, http.MethodPost, "/buses/session/signals", `{"path": "/", "interface": "com.example.Door", "member": "Opened", "signature": "sx", "text_args": ["front", "-1"]}`)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestSignalEmitter_EmitSignal_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"missing member", `{"path": "/", "interface": "com.example.Door"}`},
+		{"signature mismatch", `{"path": "/", "interface": "com.example.Door", "member": "Opened", "signature": "su", "args": ["front"]}`},
+		{"invalid value", `{"path": "/", "interface": "com.example.Door", "member": "Opened", "signature": "u", "args": [-1]}`},
+		{"invalid text", `{"path": "/", "interface": "com.example.Door", "member": "Opened", "signature": "u", "text_args": ["x"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(handlertest.MockDBusService)
+
+			rec := serveRoute(func(s *fuego.Server) {
+				fuego.Post(s, "/buses/{busType}/signals", NewSignalEmitter(mockService, allowAllSignals).EmitSignal)
+			}, http.MethodPost, "/buses/session/signals", tt.body)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+			mockService.AssertNotCalled(t, "EmitSignal")
+		})
+	}
+}
+
+func TestSignalEmitter_EmitSignal_InvalidHeader(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("EmitSignal", mock.Anything, "session", "", "/", "com..Door", "Opened", []interface{}(nil)).
+		Return(fmt.Errorf("%w: invalid interface name", service.ErrInvalidSignal))
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/signals", NewSignalEmitter(mockService, allowAllSignals).EmitSignal)
+	}, http.MethodPost, "/buses/session/signals", `{"path": "/", "interface": "com..Door", "member": "Opened"}`)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestSignalEmitter_EmitSignal_Allowed(t *testing.T) {
+	allow := []config.SignalRule{
+		{Interface: "com.example.Door"},
+		{Interface: "com.example.Alarm", Member: "Raised", Destination: ":1.*"},
+	}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"broadcast", `{"path": "/", "interface": "com.example.Door", "member": "Opened"}`},
+		{"unicast", `{"path": "/", "interface": "com.example.Alarm", "member": "Raised", "destination": ":1.42"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(handlertest.MockDBusService)
+			mockService.On("EmitSignal", mock.Anything, "session", mock.Anything, "/", mock.Anything, mock.Anything, []interface{}(nil)).
+				Return(nil)
+
+			rec := serveRoute(func(s *fuego.Server) {
+				fuego.Post(s, "/buses/{busType}/signals", NewSignalEmitter(mockService, allow).EmitSignal)
+			}, http.MethodPost, "/buses/session/signals", tt.body)
+
+			assert.Equal(t, http.StatusNoContent, rec.Code, rec.Body.String())
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSignalEmitter_EmitSignal_Denied(t *testing.T) {
+	allow := []config.SignalRule{
+		{Interface: "com.example.Door"},
+		{Interface: "com.example.Alarm", Member: "Raised", Destination: ":1.*"},
+	}
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"interface", `{"path": "/", "interface": "org.freedesktop.login1.Manager", "member": "PrepareForShutdown"}`},
+		{"member", `{"path": "/", "interface": "com.example.Alarm", "member": "Cleared", "destination": ":1.42"}`},
+		{"destination", `{"path": "/", "interface": "com.example.Alarm", "member": "Raised", "destination": "com.example.Siren"}`},
+		{"unicast", `{"path": "/", "interface": "com.example.Door", "member": "Opened", "destination": ":1.42"}`},
+		{"broadcast", `{"path": "/", "interface": "com.example.Alarm", "member": "Raised"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(handlertest.MockDBusService)
+
+			rec := serveRoute(func(s *fuego.Server) {
+				fuego.Post(s, "/buses/{busType}/signals", NewSignalEmitter(mockService, allow).EmitSignal)
+			}, http.MethodPost, "/buses/session/signals", tt.body)
+
+			assert.Equal(t, http.StatusForbidden, rec.Code, rec.Body.String())
+			mockService.AssertNotCalled(t, "EmitSignal")
+		})
+	}
+}
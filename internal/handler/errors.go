@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/go-fuego/fuego"
+	"github.com/godbus/dbus/v5"
+	"github.com/mesbrj/dbus-controller/internal/service"
+)
+
+// ErrorHandler converts errors of failed D-Bus calls to HTTP errors carrying
+// the D-Bus error name, then applies the default fuego error handling.
+// The error name is exposed as the name of the first error item.
+func ErrorHandler(err error) error {
+	var httpErr fuego.HTTPError
+	var errorStatus fuego.ErrorWithStatus
+	if errors.As(err, &httpErr) || errors.As(err, &errorStatus) {
+		return fuego.ErrorHandler(err)
+	}
+
+	if name := service.ErrorName(err); name != "" {
+		err = fuego.HTTPError{
+			Err:    err,
+			Title:  "D-Bus error",
+			Status: dbusErrorStatus(name),
+			Detail: err.Error(),
+			Errors: []fuego.ErrorItem{{Name: name, Reason: dbusErrorMessage(err)}},
+		}
+	}
+
+	return fuego.ErrorHandler(err)
+}
+
+// dbusErrorStatus maps a D-Bus error name to an HTTP status code
+func dbusErrorStatus(name string) int {
+	switch service.ClassifyError(name) {
+	case service.ErrorClassNotFound:
+		return http.StatusNotFound
+	case service.ErrorClassInvalid:
+		return http.StatusBadRequest
+	case service.ErrorClassDenied:
+		return http.StatusForbidden
+	case service.ErrorClassTimeout:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// dbusErrorMessage returns the message carried by the D-Bus error reply
+func dbusErrorMessage(err error) string {
+	var dbusErr dbus.Error
+	if errors.As(err, &dbusErr) {
+		return dbusErr.Error()
+	}
+	var dbusErrPtr *dbus.Error
+	if errors.As(err, &dbusErrPtr) {
+		return dbusErrPtr.Error()
+	}
+	return err.Error()
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/go-fuego/fuego"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorHandler_DBusError(t *testing.T) {
+	replyErr := &dbus.Error{Name: "org.freedesktop.DBus.Error.ServiceUnknown", Body: []interface{}{"The name is not activatable"}}
+
+	err := ErrorHandler(fmt.Errorf("failed to get property Data: %w", replyErr))
+
+	var httpErr fuego.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusNotFound, httpErr.StatusCode())
+	assert.Equal(t, "D-Bus error", httpErr.Title)
+	require.Len(t, httpErr.Errors, 1)
+	assert.Equal(t, "org.freedesktop.DBus.Error.ServiceUnknown", httpErr.Errors[0].Name)
+	assert.Equal(t, "The name is not activatable", httpErr.Errors[0].Reason)
+}
+
+func TestErrorHandler_KeepsHTTPErrors(t *testing.T) {
+	err := ErrorHandler(fuego.BadRequestError{Title: "Invalid request body", Err: fmt.Errorf("bad")})
+
+	var httpErr fuego.HTTPError
+	require.ErrorAs(t, err, &httpErr)
+	assert.Equal(t, http.StatusBadRequest, httpErr.StatusCode())
+	assert.Empty(t, httpErr.Errors)
+}
+
+func TestDBusErrorStatus(t *testing.T) {
+	assert.Equal(t, http.StatusBadRequest, dbusErrorStatus("org.freedesktop.DBus.Error.InvalidArgs"))
+	assert.Equal(t, http.StatusForbidden, dbusErrorStatus("org.freedesktop.DBus.Error.AccessDenied"))
+	assert.Equal(t, http.StatusGatewayTimeout, dbusErrorStatus("org.freedesktop.DBus.Error.NoReply"))
+	assert.Equal(t, http.StatusBadGateway, dbusErrorStatus("com.example.Error.Failed"))
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// streamKeepAlive is the interval of the comments keeping idle event streams open
+const streamKeepAlive = 15 * time.Second
+
+// sseEvent is a Server-Sent Event, with data sent as JSON or empty when nil
+type sseEvent struct {
+	name string
+	data any
+}
+
+// closeEvent ends the streams once their source closes
+var closeEvent = sseEvent{name: "close", data: struct{}{}}
+
+// serveEvents streams the values of events as Server-Sent Events until events
+// closes, the client goes away or next marks the last event. Each value is
+// sent as the event next makes of it, and closing once events closes. Idle
+// streams are kept open with comments every streamKeepAlive.
+func serveEvents[T any](w http.ResponseWriter, r *http.Request, events <-chan T, next func(T) (event sseEvent, last bool), closing sseEvent) {
+	// Streams outlive the server write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.WarnContext(r.Context(), "Failed to clear stream write deadline", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	_ = rc.Flush()
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case value, ok := <-events:
+			if !ok {
+				_ = writeEvent(w, closing)
+				_ = rc.Flush()
+				return
+			}
+			event, last := next(value)
+			if err := writeEvent(w, event); err != nil {
+				slog.WarnContext(r.Context(), "Failed to write stream event", "event", event.name, "error", err)
+				if last {
+					return
+				}
+				continue
+			}
+			// Flush once the queued events are written
+			if !last && len(events) > 0 {
+				continue
+			}
+			if err := rc.Flush(); err != nil || last {
+				return
+			}
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// writeEvent writes an event, leaving the stream untouched when its data does not encode
+func writeEvent(w http.ResponseWriter, event sseEvent) error {
+	var data []byte
+	if event.data != nil {
+		var err error
+		if data, err = json.Marshal(event.data); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.name, data)
+	return err
+}
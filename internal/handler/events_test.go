@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServeEvents(t *testing.T) {
+	events := make(chan int, 3)
+	events <- 1
+	events <- 2
+	close(events)
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	rec := httptest.NewRecorder()
+
+	serveEvents(rec, req, events, func(n int) (sseEvent, bool) {
+		return sseEvent{name: "number", data: n}, false
+	}, closeEvent)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+	assert.Equal(t, "event: number\ndata: 1\n\nevent: number\ndata: 2\n\nevent: close\ndata: {}\n\n", rec.Body.String())
+}
+
+func TestServeEvents_Last(t *testing.T) {
+	events := make(chan int, 3)
+	events <- 1
+	events <- 2
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	rec := httptest.NewRecorder()
+
+	serveEvents(rec, req, events, func(n int) (sseEvent, bool) {
+		return sseEvent{name: "done", data: n}, true
+	}, closeEvent)
+
+	assert.Equal(t, "event: done\ndata: 1\n\n", rec.Body.String())
+}
+
+func TestServeEvents_EmptyData(t *testing.T) {
+	events := make(chan int)
+	close(events)
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	rec := httptest.NewRecorder()
+
+	serveEvents(rec, req, events, func(n int) (sseEvent, bool) {
+		return sseEvent{name: "next", data: n}, false
+	}, sseEvent{name: "complete"})
+
+	assert.Equal(t, "event: complete\ndata: \n\n", rec.Body.String())
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-fuego/fuego"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+
+	"github.com/mesbrj/dbus-controller/internal/graph"
+)
+
+// GraphQLRequest represents a GraphQL request, as a POST body or GET query parameters
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables,omitempty"`
+	OperationName string                 `json:"operationName,omitempty"`
+}
+
+// GraphQLHandler serves the GraphQL schema of the D-Bus object model
+type GraphQLHandler struct {
+	schema graphql.Schema
+}
+
+// NewGraphQLHandler builds the GraphQL schema resolved with dbusService
+func NewGraphQLHandler(dbusService graph.Service) (*GraphQLHandler, error) {
+	schema, err := graph.NewSchema(dbusService)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GraphQL schema: %w", err)
+	}
+	return &GraphQLHandler{schema: schema}, nil
+}
+
+// Serve runs GraphQL queries and mutations, and streams the results of
+// subscriptions as Server-Sent Events. Mutations are only accepted over POST.
+func (h *GraphQLHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	request, err := graphQLRequest(r)
+	if err != nil {
+		fuego.SendJSONError(w, r, fuego.BadRequestError{Title: "Invalid GraphQL request", Detail: err.Error(), Err: err})
+		return
+	}
+
+	params := graphql.Params{
+		Schema:         h.schema,
+		RequestString:  request.Query,
+		VariableValues: request.Variables,
+		OperationName:  request.OperationName,
+		Context:        r.Context(),
+	}
+
+	switch operationType(request) {
+	case ast.OperationTypeSubscription:
+		h.stream(w, r, params)
+		return
+	case ast.OperationTypeMutation:
+		if r.Method != http.MethodPost {
+			err := errors.New("mutations are only accepted over POST")
+			fuego.SendJSONError(w, r, fuego.HTTPError{Title: "Method not allowed", Detail: err.Error(), Status: http.StatusMethodNotAllowed, Err: err})
+			return
+		}
+	}
+
+	result := graphql.Do(params)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		slog.WarnContext(r.Context(), "Failed to write GraphQL response", "error", err)
+	}
+}
+
+// stream sends the results of a subscription as "next" events, ending with a
+// "complete" event when the subscription ends
+func (h *GraphQLHandler) stream(w http.ResponseWriter, r *http.Request, params graphql.Params) {
+	results := graphql.Subscribe(params)
+
+	slog.InfoContext(r.Context(), "GraphQL subscription opened", "operation", params.OperationName)
+	serveEvents(w, r, results, func(result *graphql.Result) (sseEvent, bool) {
+		return sseEvent{name: "next", data: result}, false
+	}, sseEvent{name: "complete"})
+	slog.InfoContext(r.Context(), "GraphQL subscription closed", "operation", params.OperationName)
+}
+
+// graphQLRequest decodes a request from the JSON body of a POST or the query
+// parameters of a GET. Numbers in variables are kept as json.Number, like
+// integer literals, as 64-bit integers do not fit a float64.
+func graphQLRequest(r *http.Request) (*GraphQLRequest, error) {
+	var request GraphQLRequest
+	if r.Method == http.MethodPost {
+		decoder := json.NewDecoder(r.Body)
+		decoder.UseNumber()
+		if err := decoder.Decode(&request); err != nil {
+			return nil, fmt.Errorf("invalid JSON body: %w", err)
+		}
+	} else {
+		query := r.URL.Query()
+		request.Query = query.Get("query")
+		request.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			decoder := json.NewDecoder(strings.NewReader(variables))
+			decoder.UseNumber()
+			if err := decoder.Decode(&request.Variables); err != nil {
+				return nil, fmt.Errorf("invalid variables: %w", err)
+			}
+		}
+	}
+
+	if request.Query == "" {
+		return nil, errors.New("query is required")
+	}
+	return &request, nil
+}
+
+// operationType returns the type of the operation a request runs, or "" when the
+// query does not parse or has no such operation, leaving the error to the executor
+func operationType(request *GraphQLRequest) string {
+	document, err := parser.Parse(parser.ParseParams{Source: request.Query})
+	if err != nil {
+		return ""
+	}
+	for _, definition := range document.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if request.OperationName == "" || operation.Name != nil && operation.Name.Value == request.OperationName {
+			return operation.Operation
+		}
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbrj/dbus-controller/internal/handler/handlertest"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+func serveGraphQL(t *testing.T, mockService *handlertest.MockDBusService, req *http.Request) *httptest.ResponseRecorder {
+	h, err := NewGraphQLHandler(mockService)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	h.Serve(rec, req)
+	return rec
+}
+
+func TestGraphQLHandler_Query(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("ListServices", mock.Anything, "session").Return([]string{"com.example.HelloWorld"}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"query": "query Services($bus: String!) { bus(type: $bus) { services { name } } }", "variables": {"bus": "session"}}`))
+	rec := serveGraphQL(t, mockService, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data": {"bus": {"services": [{"name": "com.example.HelloWorld"}]}}}`, rec.Body.String())
+}
+
+func TestGraphQLHandler_Uint64Variables(t *testing.T) {
+	query := `mutation Set($args: [JSON]) {
+		callMethod(bus: "session", service: "com.example.Counter", interface: "com.example.Counter", method: "Set", args: $args, signature: "tx") { success }
+	}`
+	body := `{"query": ` + strconv.Quote(query) + `, "variables": {"args": [18446744073709551615, -9007199254740993]}}`
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethod", mock.Anything, "session", "com.example.Counter", "/", "com.example.Counter", "Set",
+		[]interface{}{uint64(18446744073709551615), int64(-9007199254740993)}).
+		Return(&model.MethodCallResult{Success: true}, nil)
+
+	rec := serveGraphQL(t, mockService, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(body)))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data": {"callMethod": {"success": true}}}`, rec.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestGraphQLRequest_GetVariables(t *testing.T) {
+	values := url.Values{"query": {"{ buses { type } }"}, "variables": {`{"value": 18446744073709551615}`}}
+
+	request, err := graphQLRequest(httptest.NewRequest(http.MethodGet, "/graphql?"+values.Encode(), nil))
+
+	require.NoError(t, err)
+	assert.Equal(t, json.Number("18446744073709551615"), request.Variables["value"])
+}
+
+func TestGraphQLHandler_GetQuery(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/graphql?"+url.Values{"query": {"{ buses { type } }"}}.Encode(), nil)
+	rec := serveGraphQL(t, new(handlertest.MockDBusService), req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"data": {"buses": [{"type": "system"}, {"type": "session"}]}}`, rec.Body.String())
+}
+
+func TestGraphQLHandler_MutationOverGet(t *testing.T) {
+	query := `mutation { callMethod(bus: "session", service: "a.b", interface: "a.b", method: "M") { success } }`
+	req := httptest.NewRequest(http.MethodGet, "/graphql?"+url.Values{"query": {query}}.Encode(), nil)
+	mockService := new(handlertest.MockDBusService)
+
+	rec := serveGraphQL(t, mockService, req)
+
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	mockService.AssertNotCalled(t, "CallMethod")
+}
+
+func TestGraphQLHandler_InvalidRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(`{"variables": {}}`))
+	rec := serveGraphQL(t, new(handlertest.MockDBusService), req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestGraphQLHandler_Subscription(t *testing.T) {
+	events := make(chan *model.SignalEvent, 1)
+	events <- &model.SignalEvent{Signal: "Changed"}
+	close(events)
+
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("StreamSignals", mock.Anything, "session", service.SignalMatch{Sender: "a.b", Interface: "a.b", Member: "Changed"}).
+		Return(events, nil)
+
+	query := `subscription { signal(bus: "session", service: "a.b", interface: "a.b", signal: "Changed") { signal } }`
+	req := httptest.NewRequest(http.MethodGet, "/graphql?"+url.Values{"query": {query}}.Encode(), nil)
+	rec := serveGraphQL(t, mockService, req)
+
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "event: next\ndata: {\"data\":{\"signal\":{\"signal\":\"Changed\"}}}\n\nevent: complete\ndata: \n\n", rec.Body.String())
+}
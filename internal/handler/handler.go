@@ -1,11 +1,25 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
 	"github.com/go-fuego/fuego"
-	"github.com/mesbrj/dbus-controller/internal/model"
+	"github.com/godbus/dbus/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+
 	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
 )
 
+// tracer creates the spans of request processing around the D-Bus calls
+var tracer = otel.Tracer("github.com/mesbrj/dbus-controller/internal/handler")
+
 // Handler contains the HTTP handlers for D-Bus operations
 type Handler struct {
 	dbusService service.DBusServiceInterface
@@ -18,6 +32,18 @@ func NewHandler(dbusService service.DBusServiceInterface) *Handler {
 	}
 }
 
+// queryObjectPath returns the object path selected by the path query parameter, "/" by default
+func queryObjectPath(c interface{ QueryParam(name string) string }) (string, error) {
+	path := c.QueryParam("path")
+	if path == "" {
+		return "/", nil
+	}
+	if !dbus.ObjectPath(path).IsValid() {
+		return "", fuego.BadRequestError{Title: "Invalid object path", Detail: path, Err: fmt.Errorf("invalid object path: %s", path)}
+	}
+	return path, nil
+}
+
 // ListBuses returns available D-Bus types (system, session)
 func (h *Handler) ListBuses(c fuego.ContextNoBody) ([]model.BusInfo, error) {
 	buses := []model.BusInfo{
@@ -32,6 +58,7 @@ func (h *Handler) GetBusInfo(c fuego.ContextNoBody) (*model.BusInfo, error) {
 	busType := c.PathParam("busType")
 
 	if busType != "system" && busType != "session" {
+		slog.DebugContext(c.Context(), "Invalid bus type", "bus", busType)
 		return nil, fuego.BadRequestError{Title: "Invalid bus type", Detail: "Bus type must be 'system' or 'session'"}
 	}
 
@@ -44,23 +71,89 @@ func (h *Handler) GetBusInfo(c fuego.ContextNoBody) (*model.BusInfo, error) {
 }
 
 // ListServices returns all services on the specified bus
+//
+// With the wait query parameter, the services are listed once the name has an
+// owner, waiting up to the timeout query parameter (30s by default) for it.
 func (h *Handler) ListServices(c fuego.ContextNoBody) ([]string, error) {
 	busType := c.PathParam("busType")
-	return h.dbusService.ListServices(busType)
+
+	if name := c.QueryParam("wait"); name != "" {
+		if err := h.waitForName(c, busType, name); err != nil {
+			return nil, err
+		}
+	}
+
+	return h.dbusService.ListServices(c.Context(), busType)
+}
+
+// defaultWaitTimeout and maxWaitTimeout bound the wait for a name to appear
+const (
+	defaultWaitTimeout = 30 * time.Second
+	maxWaitTimeout     = 5 * time.Minute
+)
+
+// queryDuration parses the duration query parameter name, returning fallback
+// when it is empty and a 400 error when it is not within (0, max]
+func queryDuration(name, value string, fallback, max time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration <= 0 || duration > max {
+		return 0, fuego.BadRequestError{
+			Title:  "Invalid " + name,
+			Detail: fmt.Sprintf("%s must be a duration between 0 and %s, e.g. 30s", name, max),
+			Err:    fmt.Errorf("invalid %s %q", name, value),
+		}
+	}
+	return duration, nil
+}
+
+// waitForName waits for a name to have an owner, answering 408 if it does not
+// appear within the timeout query parameter
+func (h *Handler) waitForName(c fuego.ContextNoBody, busType, name string) error {
+	timeout, err := queryDuration("timeout", c.QueryParam("timeout"), defaultWaitTimeout, maxWaitTimeout)
+	if err != nil {
+		return err
+	}
+
+	// The wait may outlast the server write timeout
+	rc := http.NewResponseController(c.Response())
+	if err := rc.SetWriteDeadline(time.Now().Add(timeout + defaultWaitTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.WarnContext(c.Context(), "Failed to extend write deadline", "error", err)
+	}
+
+	ctx, cancel := context.WithTimeout(c.Context(), timeout)
+	defer cancel()
+
+	_, err = h.dbusService.WaitForName(ctx, busType, name)
+	if errors.Is(err, context.DeadlineExceeded) && c.Context().Err() == nil {
+		return fuego.HTTPError{
+			Title:  "Name did not appear",
+			Status: http.StatusRequestTimeout,
+			Detail: fmt.Sprintf("%s has no owner after %s", name, timeout),
+			Err:    err,
+		}
+	}
+	return err
 }
 
 // GetService returns detailed information about a service
 func (h *Handler) GetService(c fuego.ContextNoBody) (*model.ServiceInfo, error) {
 	busType := c.PathParam("busType")
 	serviceName := c.PathParam("serviceName")
-	return h.dbusService.GetServiceInfo(busType, serviceName)
+	return h.dbusService.GetServiceInfo(c.Context(), busType, serviceName)
 }
 
 // ListInterfaces returns all interfaces for a service
 func (h *Handler) ListInterfaces(c fuego.ContextNoBody) ([]string, error) {
 	busType := c.PathParam("busType")
 	serviceName := c.PathParam("serviceName")
-	return h.dbusService.ListInterfaces(busType, serviceName)
+	objectPath, err := queryObjectPath(c)
+	if err != nil {
+		return nil, err
+	}
+	return h.dbusService.ListInterfaces(c.Context(), busType, serviceName, objectPath)
 }
 
 // GetInterface returns detailed information about an interface
@@ -68,7 +161,11 @@ func (h *Handler) GetInterface(c fuego.ContextNoBody) (*model.InterfaceInfo, err
 	busType := c.PathParam("busType")
 	serviceName := c.PathParam("serviceName")
 	interfaceName := c.PathParam("interfaceName")
-	return h.dbusService.GetInterfaceInfo(busType, serviceName, interfaceName)
+	objectPath, err := queryObjectPath(c)
+	if err != nil {
+		return nil, err
+	}
+	return h.dbusService.GetInterfaceInfo(c.Context(), busType, serviceName, objectPath, interfaceName)
 }
 
 // ListMethods returns all methods for an interface
@@ -76,27 +173,120 @@ func (h *Handler) ListMethods(c fuego.ContextNoBody) ([]model.MethodInfo, error)
 	busType := c.PathParam("busType")
 	serviceName := c.PathParam("serviceName")
 	interfaceName := c.PathParam("interfaceName")
-	return h.dbusService.ListMethods(busType, serviceName, interfaceName)
+	objectPath, err := queryObjectPath(c)
+	if err != nil {
+		return nil, err
+	}
+	return h.dbusService.ListMethods(c.Context(), busType, serviceName, objectPath, interfaceName)
 }
 
-// CallMethodRequest represents the request body for method calls
+// CallMethodRequest represents the request body for method calls.
+// Args holds JSON values; alternatively TextArgs holds the arguments as text
+// in busctl notation, typed by Signature, or in dbus-send notation.
+// The call flags set the header flags of the method call message.
 type CallMethodRequest struct {
-	Args []interface{} `json:"args,omitempty"`
+	Args      []interface{} `json:"args,omitempty"`
+	Syntax    string        `json:"syntax,omitempty"`
+	Signature string        `json:"signature,omitempty"`
+	TextArgs  []string      `json:"text_args,omitempty"`
+	Async     bool          `json:"async,omitempty"` // Start the call as a job instead of waiting for the reply
+	model.CallFlags
 }
 
-// CallMethod executes a D-Bus method call
-func (h *Handler) CallMethod(c *fuego.ContextWithBody[CallMethodRequest]) (*model.MethodCallResult, error) {
+// textArgs parses textual arguments in the requested notation, busctl by default
+func textArgs(syntax, signature string, args []string) ([]interface{}, error) {
+	var values []interface{}
+	var err error
+	switch syntax {
+	case "", "busctl":
+		values, err = service.ParseBusctlArgs(signature, args)
+	case "dbus-send":
+		if signature != "" {
+			return nil, fuego.BadRequestError{Title: "Invalid arguments", Detail: "dbus-send arguments carry their own types, signature must be empty", Err: fmt.Errorf("signature given with dbus-send arguments")}
+		}
+		values, err = service.ParseDBusSendArgs(args)
+	default:
+		return nil, fuego.BadRequestError{Title: "Invalid arguments", Detail: fmt.Sprintf("unknown syntax %q", syntax), Err: fmt.Errorf("unknown syntax: %s", syntax)}
+	}
+
+	var argErr *service.ArgError
+	if errors.As(err, &argErr) {
+		name := "signature"
+		if argErr.Arg >= 0 {
+			name = fmt.Sprintf("text_args[%d]", argErr.Arg)
+		}
+		return nil, fuego.BadRequestError{
+			Title:  "Invalid arguments",
+			Detail: err.Error(),
+			Err:    err,
+			Errors: []fuego.ErrorItem{{Name: name, Reason: argErr.Msg, More: map[string]any{"offset": argErr.Offset}}},
+		}
+	}
+	return values, err
+}
+
+// CallMethod executes a D-Bus method call and returns its result. With async
+// in the body or a "Prefer: respond-async" header, it starts the call as a job
+// instead and returns the job. The result is typed any to answer either.
+func (h *Handler) CallMethod(c *fuego.ContextWithBody[CallMethodRequest]) (any, error) {
 	busType := c.PathParam("busType")
 	serviceName := c.PathParam("serviceName")
 	interfaceName := c.PathParam("interfaceName")
 	methodName := c.PathParam("methodName")
+	objectPath, err := queryObjectPath(c)
+	if err != nil {
+		return nil, err
+	}
 
 	body, err := c.Body()
 	if err != nil {
+		slog.WarnContext(c.Context(), "Invalid method call body", "error", err)
 		return nil, fuego.BadRequestError{Title: "Invalid request body", Detail: err.Error()}
 	}
 
-	return h.dbusService.CallMethod(busType, serviceName, interfaceName, methodName, body.Args)
+	if preferAsync(c.Request()) {
+		c.Response().Header().Set("Preference-Applied", "respond-async")
+		body.Async = true
+	}
+	if body.Async {
+		return h.startJob(c, busType, serviceName, objectPath, interfaceName, methodName, body)
+	}
+
+	end := startConversion(c.Context())
+	args, err := methodArgs(body)
+	end(err)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(c.Context(), "Calling D-Bus method",
+		"bus", busType, "service", serviceName, "path", objectPath, "interface", interfaceName, "method", methodName, "args", len(args))
+
+	return h.dbusService.CallMethodWithFlags(c.Context(), busType, serviceName, objectPath, interfaceName, methodName, body.CallFlags, args)
+}
+
+// startConversion starts the span of the conversion of request arguments to
+// D-Bus values. The returned function ends the span with the error, if any.
+func startConversion(ctx context.Context) func(error) {
+	_, span := tracer.Start(ctx, "convertArguments")
+	return func(err error) {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}
+}
+
+// methodArgs returns the arguments of a method call request, converting text arguments
+func methodArgs(body CallMethodRequest) ([]interface{}, error) {
+	if body.Syntax == "" && body.Signature == "" && len(body.TextArgs) == 0 {
+		return body.Args, nil
+	}
+	if len(body.Args) > 0 {
+		return nil, fuego.BadRequestError{Title: "Invalid request body", Detail: "args and text_args are mutually exclusive", Err: fmt.Errorf("both args and text_args given")}
+	}
+	return textArgs(body.Syntax, body.Signature, body.TextArgs)
 }
 
 // ListProperties returns all properties for an interface
@@ -104,7 +294,11 @@ func (h *Handler) ListProperties(c fuego.ContextNoBody) ([]model.PropertyInfo, e
 	busType := c.PathParam("busType")
 	serviceName := c.PathParam("serviceName")
 	interfaceName := c.PathParam("interfaceName")
-	return h.dbusService.ListProperties(busType, serviceName, interfaceName)
+	objectPath, err := queryObjectPath(c)
+	if err != nil {
+		return nil, err
+	}
+	return h.dbusService.ListProperties(c.Context(), busType, serviceName, objectPath, interfaceName)
 }
 
 // GetProperty returns the value of a specific property
@@ -113,12 +307,21 @@ func (h *Handler) GetProperty(c fuego.ContextNoBody) (*model.PropertyValue, erro
 	serviceName := c.PathParam("serviceName")
 	interfaceName := c.PathParam("interfaceName")
 	propertyName := c.PathParam("propertyName")
-	return h.dbusService.GetProperty(busType, serviceName, interfaceName, propertyName)
+	objectPath, err := queryObjectPath(c)
+	if err != nil {
+		return nil, err
+	}
+	return h.dbusService.GetProperty(c.Context(), busType, serviceName, objectPath, interfaceName, propertyName)
 }
 
-// SetPropertyRequest represents the request body for setting properties
+// SetPropertyRequest represents the request body for setting properties.
+// Value holds a JSON value; alternatively TextArgs holds the value as text
+// in busctl notation, typed by Signature, or in dbus-send notation.
 type SetPropertyRequest struct {
-	Value interface{} `json:"value"`
+	Value     interface{} `json:"value"`
+	Syntax    string      `json:"syntax,omitempty"`
+	Signature string      `json:"signature,omitempty"`
+	TextArgs  []string    `json:"text_args,omitempty"`
 }
 
 // SetProperty sets the value of a specific property
@@ -127,13 +330,38 @@ func (h *Handler) SetProperty(c *fuego.ContextWithBody[SetPropertyRequest]) (*mo
 	serviceName := c.PathParam("serviceName")
 	interfaceName := c.PathParam("interfaceName")
 	propertyName := c.PathParam("propertyName")
+	objectPath, err := queryObjectPath(c)
+	if err != nil {
+		return nil, err
+	}
 
 	body, err := c.Body()
 	if err != nil {
+		slog.WarnContext(c.Context(), "Invalid set property body", "error", err)
 		return nil, fuego.BadRequestError{Title: "Invalid request body", Detail: err.Error()}
 	}
 
-	return h.dbusService.SetProperty(busType, serviceName, interfaceName, propertyName, body.Value)
+	value := body.Value
+	if body.Syntax != "" || body.Signature != "" || len(body.TextArgs) > 0 {
+		if body.Value != nil {
+			return nil, fuego.BadRequestError{Title: "Invalid request body", Detail: "value and text_args are mutually exclusive", Err: fmt.Errorf("both value and text_args given")}
+		}
+		end := startConversion(c.Context())
+		values, err := textArgs(body.Syntax, body.Signature, body.TextArgs)
+		end(err)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != 1 {
+			return nil, fuego.BadRequestError{Title: "Invalid arguments", Detail: fmt.Sprintf("expected one value, got %d", len(values)), Err: fmt.Errorf("expected one property value, got %d", len(values))}
+		}
+		value = values[0]
+	}
+
+	slog.InfoContext(c.Context(), "Setting D-Bus property",
+		"bus", busType, "service", serviceName, "path", objectPath, "interface", interfaceName, "property", propertyName)
+
+	return h.dbusService.SetProperty(c.Context(), busType, serviceName, objectPath, interfaceName, propertyName, value)
 }
 
 // ListSignals returns all signals for an interface
@@ -141,7 +369,48 @@ func (h *Handler) ListSignals(c fuego.ContextNoBody) ([]model.SignalInfo, error)
 	busType := c.PathParam("busType")
 	serviceName := c.PathParam("serviceName")
 	interfaceName := c.PathParam("interfaceName")
-	return h.dbusService.ListSignals(busType, serviceName, interfaceName)
+	objectPath, err := queryObjectPath(c)
+	if err != nil {
+		return nil, err
+	}
+	return h.dbusService.ListSignals(c.Context(), busType, serviceName, objectPath, interfaceName)
+}
+
+// signalArgs returns the arguments of a signal: text arguments as in a method
+// call, or JSON values converted to the types of the signature when given
+func signalArgs(body model.EmitSignalRequest) ([]interface{}, error) {
+	if body.Syntax != "" || len(body.TextArgs) > 0 {
+		return methodArgs(CallMethodRequest{Args: body.Args, Syntax: body.Syntax, Signature: body.Signature, TextArgs: body.TextArgs})
+	}
+	if body.Signature == "" {
+		return body.Args, nil
+	}
+
+	types, err := service.SplitSignature(body.Signature)
+	if err != nil {
+		return nil, fuego.BadRequestError{Title: "Invalid arguments", Detail: err.Error(), Err: err}
+	}
+	if len(types) != len(body.Args) {
+		err := fmt.Errorf("signature %q has %d types for %d args", body.Signature, len(types), len(body.Args))
+		return nil, fuego.BadRequestError{Title: "Invalid arguments", Detail: err.Error(), Err: err}
+	}
+
+	args := make([]interface{}, len(types))
+	for i, typ := range types {
+		if args[i], err = service.ParseJSONArg(fmt.Sprintf("args[%d]", i), typ, body.Args[i]); err != nil {
+			var valueErr *service.ValueError
+			if errors.As(err, &valueErr) {
+				return nil, fuego.BadRequestError{
+					Title:  "Invalid arguments",
+					Detail: err.Error(),
+					Err:    err,
+					Errors: []fuego.ErrorItem{{Name: valueErr.Path, Reason: valueErr.Msg}},
+				}
+			}
+			return nil, err
+		}
+	}
+	return args, nil
 }
 
 // SubscribeToSignal subscribes to a D-Bus signal
@@ -150,12 +419,16 @@ func (h *Handler) SubscribeToSignal(c fuego.ContextNoBody) (*model.SignalSubscri
 	serviceName := c.PathParam("serviceName")
 	interfaceName := c.PathParam("interfaceName")
 	signalName := c.PathParam("signalName")
-	return h.dbusService.SubscribeToSignal(busType, serviceName, interfaceName, signalName)
+	return h.dbusService.SubscribeToSignal(c.Context(), busType, serviceName, interfaceName, signalName)
 }
 
-// IntrospectService returns the introspection XML for a service
+// IntrospectService returns the introspection XML for an object of a service
 func (h *Handler) IntrospectService(c fuego.ContextNoBody) (*model.IntrospectionResult, error) {
 	busType := c.PathParam("busType")
 	serviceName := c.PathParam("serviceName")
-	return h.dbusService.IntrospectService(busType, serviceName)
+	objectPath, err := queryObjectPath(c)
+	if err != nil {
+		return nil, err
+	}
+	return h.dbusService.IntrospectObject(c.Context(), busType, serviceName, objectPath)
 }
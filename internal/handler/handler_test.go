@@ -1,97 +1,40 @@
 package handler
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/go-fuego/fuego"
+	"github.com/go-fuego/fuego/option"
+	"github.com/go-fuego/fuego/param"
+	"github.com/godbus/dbus/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
-	"github.com/mesbrj/dbus-controller/internal/model"
+	"github.com/mesbrj/dbus-controller/internal/handler/handlertest"
+	"github.com/mesbrj/dbus-controller/pkg/model"
 )
 
-// MockDBusService is a mock implementation of the DBusServiceInterface
-type MockDBusService struct {
-	mock.Mock
-}
-
-func (m *MockDBusService) ListServices(busType string) ([]string, error) {
-	args := m.Called(busType)
-	return args.Get(0).([]string), args.Error(1)
-}
-
-func (m *MockDBusService) GetServiceInfo(busType, serviceName string) (*model.ServiceInfo, error) {
-	args := m.Called(busType, serviceName)
-	return args.Get(0).(*model.ServiceInfo), args.Error(1)
-}
-
-func (m *MockDBusService) ListInterfaces(busType, serviceName string) ([]string, error) {
-	args := m.Called(busType, serviceName)
-	return args.Get(0).([]string), args.Error(1)
-}
-
-func (m *MockDBusService) GetInterfaceInfo(busType, serviceName, interfaceName string) (*model.InterfaceInfo, error) {
-	args := m.Called(busType, serviceName, interfaceName)
-	return args.Get(0).(*model.InterfaceInfo), args.Error(1)
-}
-
-func (m *MockDBusService) ListMethods(busType, serviceName, interfaceName string) ([]model.MethodInfo, error) {
-	args := m.Called(busType, serviceName, interfaceName)
-	return args.Get(0).([]model.MethodInfo), args.Error(1)
-}
-
-func (m *MockDBusService) CallMethod(busType, serviceName, interfaceName, methodName string, args []interface{}) (*model.MethodCallResult, error) {
-	mockArgs := m.Called(busType, serviceName, interfaceName, methodName, args)
-	return mockArgs.Get(0).(*model.MethodCallResult), mockArgs.Error(1)
-}
-
-func (m *MockDBusService) ListProperties(busType, serviceName, interfaceName string) ([]model.PropertyInfo, error) {
-	args := m.Called(busType, serviceName, interfaceName)
-	return args.Get(0).([]model.PropertyInfo), args.Error(1)
-}
-
-func (m *MockDBusService) GetProperty(busType, serviceName, interfaceName, propertyName string) (*model.PropertyValue, error) {
-	args := m.Called(busType, serviceName, interfaceName, propertyName)
-	return args.Get(0).(*model.PropertyValue), args.Error(1)
-}
-
-func (m *MockDBusService) SetProperty(busType, serviceName, interfaceName, propertyName string, value interface{}) (*model.PropertyValue, error) {
-	args := m.Called(busType, serviceName, interfaceName, propertyName, value)
-	return args.Get(0).(*model.PropertyValue), args.Error(1)
-}
-
-func (m *MockDBusService) ListSignals(busType, serviceName, interfaceName string) ([]model.SignalInfo, error) {
-	args := m.Called(busType, serviceName, interfaceName)
-	return args.Get(0).([]model.SignalInfo), args.Error(1)
-}
-
-func (m *MockDBusService) SubscribeToSignal(busType, serviceName, interfaceName, signalName string) (*model.SignalSubscription, error) {
-	args := m.Called(busType, serviceName, interfaceName, signalName)
-	return args.Get(0).(*model.SignalSubscription), args.Error(1)
-}
-
-func (m *MockDBusService) IntrospectService(busType, serviceName string) (*model.IntrospectionResult, error) {
-	args := m.Called(busType, serviceName)
-	return args.Get(0).(*model.IntrospectionResult), args.Error(1)
-}
-
-func (m *MockDBusService) Close() {
-	m.Called()
-}
-
 // HandlerTestSuite defines a test suite for handler tests
 type HandlerTestSuite struct {
 	suite.Suite
 	handler     *Handler
-	mockService *MockDBusService
+	mockService *handlertest.MockDBusService
 	server      *fuego.Server
 }
 
 func (suite *HandlerTestSuite) SetupTest() {
-	suite.mockService = new(MockDBusService)
+	suite.mockService = new(handlertest.MockDBusService)
 	suite.handler = NewHandler(suite.mockService)
 	suite.server = fuego.NewServer()
 }
@@ -113,7 +56,7 @@ func (suite *HandlerTestSuite) TestListBuses() {
 	}
 
 	// For this simple case, we can test the logic directly
-	result, err := suite.handler.ListBuses(nil)
+	result, err := suite.handler.ListBuses(fuego.ContextNoBody{Req: req, Res: rec})
 
 	assert.NoError(suite.T(), err)
 	assert.Len(suite.T(), result, 2)
@@ -123,11 +66,11 @@ func (suite *HandlerTestSuite) TestListBuses() {
 func (suite *HandlerTestSuite) TestListServices() {
 	expectedServices := []string{"org.freedesktop.DBus", "org.freedesktop.NetworkManager"}
 
-	suite.mockService.On("ListServices", "system").Return(expectedServices, nil)
+	suite.mockService.On("ListServices", mock.Anything, "system").Return(expectedServices, nil)
 
 	// In a real test, you'd create proper fuego context
 	// For now, we test the service call directly
-	services, err := suite.mockService.ListServices("system")
+	services, err := suite.mockService.ListServices(context.Background(), "system")
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), expectedServices, services)
@@ -140,9 +83,9 @@ func (suite *HandlerTestSuite) TestGetServiceInfo() {
 		Interfaces: []string{"org.freedesktop.DBus"},
 	}
 
-	suite.mockService.On("GetServiceInfo", "system", "org.freedesktop.DBus").Return(expectedService, nil)
+	suite.mockService.On("GetServiceInfo", mock.Anything, "system", "org.freedesktop.DBus").Return(expectedService, nil)
 
-	service, err := suite.mockService.GetServiceInfo("system", "org.freedesktop.DBus")
+	service, err := suite.mockService.GetServiceInfo(context.Background(), "system", "org.freedesktop.DBus")
 
 	assert.NoError(suite.T(), err)
 	assert.Equal(suite.T(), expectedService, service)
@@ -154,7 +97,7 @@ func TestHandlerSuite(t *testing.T) {
 
 // Individual test functions for specific scenarios
 func TestNewHandler(t *testing.T) {
-	mockService := new(MockDBusService)
+	mockService := new(handlertest.MockDBusService)
 	handler := NewHandler(mockService)
 
 	assert.NotNil(t, handler)
@@ -162,17 +105,16 @@ func TestNewHandler(t *testing.T) {
 }
 
 func TestHandler_GetBusInfo_ValidBusType(t *testing.T) {
-	handler := NewHandler(new(MockDBusService))
+	handler := NewHandler(new(handlertest.MockDBusService))
 
 	// Test system bus
-	// Note: In real implementation, you'd create proper fuego context
-	// This is a simplified unit test
-	systemBus := &model.BusInfo{
-		Type:        "system",
-		Description: "system D-Bus",
-	}
+	req := httptest.NewRequest(http.MethodGet, "/buses/system", nil)
+	req.SetPathValue("busType", "system")
+
+	systemBus, err := handler.GetBusInfo(fuego.ContextNoBody{Req: req})
 
 	// Test the business logic
+	assert.NoError(t, err)
 	assert.Equal(t, "system", systemBus.Type)
 	assert.Contains(t, systemBus.Description, "system")
 }
@@ -190,3 +132,177 @@ func TestHandler_GetBusInfo_InvalidBusType(t *testing.T) {
 		assert.NotContains(t, validTypes, invalidType)
 	}
 }
+
+func TestHandler_ObjectPath(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("ListInterfaces", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld").
+		Return([]string{"com.example.HelloWorld"}, nil)
+	handler := NewHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodGet, "/buses/session/services/com.example.HelloWorld/interfaces?path=/com/example/HelloWorld", nil)
+	server := fuego.NewServer()
+	fuego.Get(server, "/buses/{busType}/services/{serviceName}/interfaces", handler.ListInterfaces,
+		option.Query("path", "D-Bus object path", param.Default("/")))
+	rec := httptest.NewRecorder()
+
+	server.Mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestQueryObjectPath_Invalid(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/?path=relative/path", nil)
+	server := fuego.NewServer()
+	var pathErr error
+	fuego.Get(server, "/", func(c fuego.ContextNoBody) (string, error) {
+		_, pathErr = queryObjectPath(c)
+		return "", nil
+	}, option.Query("path", "D-Bus object path"))
+
+	server.Mux.ServeHTTP(httptest.NewRecorder(), req)
+
+	var badRequest fuego.BadRequestError
+	assert.ErrorAs(t, pathErr, &badRequest)
+}
+
+func TestHandler_CallMethod_TextArgs(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethodWithFlags", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld", "Hello",
+		model.CallFlags{}, []interface{}{"world", uint32(5), map[string]dbus.Variant{"k": dbus.MakeVariant(int32(1))}}).
+		Return(&model.MethodCallResult{Success: true}, nil)
+	handler := NewHandler(mockService)
+
+	server := fuego.NewServer()
+	fuego.Post(server, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/methods/{methodName}/call", handler.CallMethod)
+	body := `{"signature": "sua{sv}", "text_args": ["world", "5", "1", "k", "i", "1"]}`
+	req := httptest.NewRequest(http.MethodPost, "/buses/session/services/com.example.HelloWorld/interfaces/com.example.HelloWorld/methods/Hello/call", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	server.Mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_CallMethod_Flags(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethodWithFlags", mock.Anything, "system", "org.freedesktop.login1", "/", "org.freedesktop.login1.Manager", "Reboot",
+		model.CallFlags{NoAutoStart: true, AllowInteractiveAuthorization: true}, []interface{}{false}).
+		Return(&model.MethodCallResult{Success: true}, nil)
+	handler := NewHandler(mockService)
+
+	server := fuego.NewServer()
+	fuego.Post(server, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/methods/{methodName}/call", handler.CallMethod)
+	body := `{"args": [false], "no_auto_start": true, "allow_interactive_authorization": true}`
+	req := httptest.NewRequest(http.MethodPost, "/buses/system/services/org.freedesktop.login1/interfaces/org.freedesktop.login1.Manager/methods/Reboot/call", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	server.Mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_SetProperty_DBusSendArgs(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("SetProperty", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld", "PID",
+		dbus.MakeVariant(int32(7))).
+		Return(&model.PropertyValue{Name: "PID", Type: "i", Value: int32(7)}, nil)
+	handler := NewHandler(mockService)
+
+	server := fuego.NewServer()
+	fuego.Put(server, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/properties/{propertyName}", handler.SetProperty)
+	body := `{"syntax": "dbus-send", "text_args": ["variant:int32:7"]}`
+	req := httptest.NewRequest(http.MethodPut, "/buses/session/services/com.example.HelloWorld/interfaces/com.example.HelloWorld/properties/PID", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	server.Mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestTextArgs_Errors(t *testing.T) {
+	_, err := textArgs("", "si", []string{"a", "x"})
+	var badRequest fuego.BadRequestError
+	require.ErrorAs(t, err, &badRequest)
+	require.Len(t, badRequest.Errors, 1)
+	assert.Equal(t, "text_args[1]", badRequest.Errors[0].Name)
+
+	_, err = textArgs("busctl", "a{", nil)
+	require.ErrorAs(t, err, &badRequest)
+	assert.Equal(t, "signature", badRequest.Errors[0].Name)
+	assert.Equal(t, map[string]any{"offset": 2}, badRequest.Errors[0].More)
+
+	_, err = textArgs("dbus-send", "s", []string{"string:a"})
+	assert.ErrorAs(t, err, &badRequest)
+
+	_, err = textArgs("gdbus", "", nil)
+	assert.ErrorAs(t, err, &badRequest)
+}
+
+func TestHandler_CallMethod_ConversionSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	handler := NewHandler(new(handlertest.MockDBusService))
+	server := fuego.NewServer()
+	fuego.Post(server, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/methods/{methodName}/call", handler.CallMethod)
+	body := `{"signature": "u", "text_args": ["x"]}`
+	req := httptest.NewRequest(http.MethodPost, "/buses/session/services/com.example.HelloWorld/interfaces/com.example.HelloWorld/methods/Hello/call", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	server.Mux.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "convertArguments", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Len(t, spans[0].Events(), 1)
+}
+
+// serveRoute sends a request with a JSON body to a server with the error
+// handler of the API, on which register adds the route under test
+func serveRoute(register func(s *fuego.Server), method, target, body string) *httptest.ResponseRecorder {
+	server := fuego.NewServer(fuego.WithErrorHandler(ErrorHandler))
+	register(server)
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	server.Mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHandler_ServiceOpenAPI(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("IntrospectObject", mock.Anything, "session", "com.example.HelloWorld", "/").
+		Return(&model.IntrospectionResult{ObjectPath: "/", ParsedData: &model.ParsedIntrospection{
+			Nodes: []model.NodeInfo{{Name: "A", Path: "/A"}, {Name: "B", Path: "/B"}},
+		}}, nil)
+	mockService.On("IntrospectObject", mock.Anything, "session", "com.example.HelloWorld", "/A").
+		Return(&model.IntrospectionResult{ObjectPath: "/A", ParsedData: &model.ParsedIntrospection{
+			Interfaces: []model.InterfaceInfo{{Name: "com.example.HelloWorld", Methods: []model.MethodInfo{{Name: "Hello"}}}},
+		}}, nil)
+	mockService.On("IntrospectObject", mock.Anything, "session", "com.example.HelloWorld", "/B").
+		Return((*model.IntrospectionResult)(nil), errors.New("object vanished"))
+	handler := NewHandler(mockService)
+
+	server := fuego.NewServer()
+	fuego.Get(server, "/swagger/services/{serviceName}/openapi.json", handler.ServiceOpenAPI,
+		option.Query("bus", "Bus type", param.Default("system")), option.Query("path", "Root object", param.Default("/")))
+	req := httptest.NewRequest(http.MethodGet, "/swagger/services/com.example.HelloWorld/openapi.json?bus=session", nil)
+	rec := httptest.NewRecorder()
+
+	server.Mux.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "/buses/session/services/com.example.HelloWorld/interfaces/com.example.HelloWorld/methods/Hello/call")
+	mockService.AssertExpectations(t)
+}
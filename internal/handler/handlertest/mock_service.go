@@ -0,0 +1,222 @@
+// Package handlertest provides a mock of the D-Bus service for the tests of
+// the handlers and of the packages serving them.
+package handlertest
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// MockDBusService is a mock implementation of the DBusServiceInterface
+type MockDBusService struct {
+	mock.Mock
+}
+
+func (m *MockDBusService) CheckBus(ctx context.Context, busType string) *model.BusHealth {
+	args := m.Called(ctx, busType)
+	return args.Get(0).(*model.BusHealth)
+}
+
+func (m *MockDBusService) ListServices(ctx context.Context, busType string) ([]string, error) {
+	args := m.Called(ctx, busType)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockDBusService) ListNames(ctx context.Context, busType string) ([]model.NameInfo, error) {
+	args := m.Called(ctx, busType)
+	names, _ := args.Get(0).([]model.NameInfo)
+	return names, args.Error(1)
+}
+
+func (m *MockDBusService) StartService(ctx context.Context, busType, serviceName string) (*model.ServiceStart, error) {
+	args := m.Called(ctx, busType, serviceName)
+	start, _ := args.Get(0).(*model.ServiceStart)
+	return start, args.Error(1)
+}
+
+func (m *MockDBusService) UpdateActivationEnvironment(ctx context.Context, busType string, environment map[string]string) error {
+	args := m.Called(ctx, busType, environment)
+	return args.Error(0)
+}
+
+func (m *MockDBusService) RequestName(ctx context.Context, busType, name string, flags model.NameFlags) (*model.NameOwnership, error) {
+	args := m.Called(ctx, busType, name, flags)
+	ownership, _ := args.Get(0).(*model.NameOwnership)
+	return ownership, args.Error(1)
+}
+
+func (m *MockDBusService) ReleaseName(ctx context.Context, busType, name string) (*model.NameOwnership, error) {
+	args := m.Called(ctx, busType, name)
+	ownership, _ := args.Get(0).(*model.NameOwnership)
+	return ownership, args.Error(1)
+}
+
+func (m *MockDBusService) ListQueuedOwners(ctx context.Context, busType, name string) (*model.NameQueue, error) {
+	args := m.Called(ctx, busType, name)
+	queue, _ := args.Get(0).(*model.NameQueue)
+	return queue, args.Error(1)
+}
+
+func (m *MockDBusService) WatchNameOwners(ctx context.Context, busType, pattern string, unique bool) (<-chan *model.NameOwnerEvent, error) {
+	args := m.Called(ctx, busType, pattern, unique)
+	events, _ := args.Get(0).(chan *model.NameOwnerEvent)
+	return events, args.Error(1)
+}
+
+func (m *MockDBusService) WaitForName(ctx context.Context, busType, name string) (string, error) {
+	args := m.Called(ctx, busType, name)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockDBusService) GetServiceInfo(ctx context.Context, busType, serviceName string) (*model.ServiceInfo, error) {
+	args := m.Called(ctx, busType, serviceName)
+	return args.Get(0).(*model.ServiceInfo), args.Error(1)
+}
+
+func (m *MockDBusService) ListInterfaces(ctx context.Context, busType, serviceName, objectPath string) ([]string, error) {
+	args := m.Called(ctx, busType, serviceName, objectPath)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockDBusService) GetInterfaceInfo(ctx context.Context, busType, serviceName, objectPath, interfaceName string) (*model.InterfaceInfo, error) {
+	args := m.Called(ctx, busType, serviceName, objectPath, interfaceName)
+	return args.Get(0).(*model.InterfaceInfo), args.Error(1)
+}
+
+func (m *MockDBusService) ListMethods(ctx context.Context, busType, serviceName, objectPath, interfaceName string) ([]model.MethodInfo, error) {
+	args := m.Called(ctx, busType, serviceName, objectPath, interfaceName)
+	return args.Get(0).([]model.MethodInfo), args.Error(1)
+}
+
+func (m *MockDBusService) CallMethod(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName string, args []interface{}) (*model.MethodCallResult, error) {
+	mockArgs := m.Called(ctx, busType, serviceName, objectPath, interfaceName, methodName, args)
+	return mockArgs.Get(0).(*model.MethodCallResult), mockArgs.Error(1)
+}
+
+func (m *MockDBusService) CallMethodWithFlags(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName string, flags model.CallFlags, args []interface{}) (*model.MethodCallResult, error) {
+	mockArgs := m.Called(ctx, busType, serviceName, objectPath, interfaceName, methodName, flags, args)
+	result, _ := mockArgs.Get(0).(*model.MethodCallResult)
+	return result, mockArgs.Error(1)
+}
+
+func (m *MockDBusService) CallMethodNoReply(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName string, args []interface{}) error {
+	mockArgs := m.Called(ctx, busType, serviceName, objectPath, interfaceName, methodName, args)
+	return mockArgs.Error(0)
+}
+
+func (m *MockDBusService) StartJob(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName string, flags model.CallFlags, args []interface{}) (*model.Job, error) {
+	mockArgs := m.Called(ctx, busType, serviceName, objectPath, interfaceName, methodName, flags, args)
+	job, _ := mockArgs.Get(0).(*model.Job)
+	return job, mockArgs.Error(1)
+}
+
+func (m *MockDBusService) ListJobs(ctx context.Context) ([]model.Job, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.Job), args.Error(1)
+}
+
+func (m *MockDBusService) GetJob(ctx context.Context, jobID string) (*model.Job, error) {
+	args := m.Called(ctx, jobID)
+	job, _ := args.Get(0).(*model.Job)
+	return job, args.Error(1)
+}
+
+func (m *MockDBusService) CancelJob(ctx context.Context, jobID string) error {
+	args := m.Called(ctx, jobID)
+	return args.Error(0)
+}
+
+func (m *MockDBusService) WatchJob(ctx context.Context, jobID string) (<-chan *model.Job, error) {
+	args := m.Called(ctx, jobID)
+	jobs, _ := args.Get(0).(chan *model.Job)
+	return jobs, args.Error(1)
+}
+
+func (m *MockDBusService) ListProperties(ctx context.Context, busType, serviceName, objectPath, interfaceName string) ([]model.PropertyInfo, error) {
+	args := m.Called(ctx, busType, serviceName, objectPath, interfaceName)
+	return args.Get(0).([]model.PropertyInfo), args.Error(1)
+}
+
+func (m *MockDBusService) GetProperty(ctx context.Context, busType, serviceName, objectPath, interfaceName, propertyName string) (*model.PropertyValue, error) {
+	args := m.Called(ctx, busType, serviceName, objectPath, interfaceName, propertyName)
+	return args.Get(0).(*model.PropertyValue), args.Error(1)
+}
+
+func (m *MockDBusService) SetProperty(ctx context.Context, busType, serviceName, objectPath, interfaceName, propertyName string, value interface{}) (*model.PropertyValue, error) {
+	args := m.Called(ctx, busType, serviceName, objectPath, interfaceName, propertyName, value)
+	return args.Get(0).(*model.PropertyValue), args.Error(1)
+}
+
+func (m *MockDBusService) ListSignals(ctx context.Context, busType, serviceName, objectPath, interfaceName string) ([]model.SignalInfo, error) {
+	args := m.Called(ctx, busType, serviceName, objectPath, interfaceName)
+	return args.Get(0).([]model.SignalInfo), args.Error(1)
+}
+
+func (m *MockDBusService) EmitSignal(ctx context.Context, busType, destination, objectPath, interfaceName, signalName string, args []interface{}) error {
+	mockArgs := m.Called(ctx, busType, destination, objectPath, interfaceName, signalName, args)
+	return mockArgs.Error(0)
+}
+
+func (m *MockDBusService) SubscribeToSignal(ctx context.Context, busType, serviceName, interfaceName, signalName string) (*model.SignalSubscription, error) {
+	args := m.Called(ctx, busType, serviceName, interfaceName, signalName)
+	return args.Get(0).(*model.SignalSubscription), args.Error(1)
+}
+
+func (m *MockDBusService) ListSubscriptions(ctx context.Context) ([]model.SignalSubscription, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]model.SignalSubscription), args.Error(1)
+}
+
+func (m *MockDBusService) Unsubscribe(ctx context.Context, subscriptionID string) error {
+	args := m.Called(ctx, subscriptionID)
+	return args.Error(0)
+}
+
+func (m *MockDBusService) StreamSubscription(ctx context.Context, subscriptionID string) (<-chan *model.SignalEvent, error) {
+	args := m.Called(ctx, subscriptionID)
+	events, _ := args.Get(0).(chan *model.SignalEvent)
+	return events, args.Error(1)
+}
+
+func (m *MockDBusService) StreamSignals(ctx context.Context, busType string, match service.SignalMatch) (<-chan *model.SignalEvent, error) {
+	args := m.Called(ctx, busType, match)
+	events, _ := args.Get(0).(chan *model.SignalEvent)
+	return events, args.Error(1)
+}
+
+func (m *MockDBusService) Monitor(ctx context.Context, busType string, rules []string) (<-chan *model.MonitorMessage, error) {
+	args := m.Called(ctx, busType, rules)
+	messages, _ := args.Get(0).(chan *model.MonitorMessage)
+	return messages, args.Error(1)
+}
+
+func (m *MockDBusService) Capture(ctx context.Context, busType string, rules []string, duration time.Duration, w io.Writer) (*model.CaptureStats, error) {
+	args := m.Called(ctx, busType, rules, duration, w)
+	stats, _ := args.Get(0).(*model.CaptureStats)
+	return stats, args.Error(1)
+}
+
+func (m *MockDBusService) CloseStreams() {
+	m.Called()
+}
+
+func (m *MockDBusService) IntrospectService(ctx context.Context, busType, serviceName string) (*model.IntrospectionResult, error) {
+	args := m.Called(ctx, busType, serviceName)
+	return args.Get(0).(*model.IntrospectionResult), args.Error(1)
+}
+
+func (m *MockDBusService) IntrospectObject(ctx context.Context, busType, serviceName, objectPath string) (*model.IntrospectionResult, error) {
+	args := m.Called(ctx, busType, serviceName, objectPath)
+	result, _ := args.Get(0).(*model.IntrospectionResult)
+	return result, args.Error(1)
+}
+
+func (m *MockDBusService) Close() {
+	m.Called()
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-fuego/fuego"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// busTypes lists the buses reported by the readiness probe
+var busTypes = []string{"system", "session"}
+
+// HealthHandler contains the HTTP handlers for the liveness and readiness probes
+type HealthHandler struct {
+	dbusService   service.DBusServiceInterface
+	requiredBuses map[string]bool
+}
+
+// NewHealthHandler creates a new health handler instance.
+// Without required buses, the controller is ready when any bus responds.
+func NewHealthHandler(dbusService service.DBusServiceInterface, requiredBuses []string) *HealthHandler {
+	required := make(map[string]bool, len(requiredBuses))
+	for _, busType := range requiredBuses {
+		required[busType] = true
+	}
+
+	return &HealthHandler{
+		dbusService:   dbusService,
+		requiredBuses: required,
+	}
+}
+
+// Healthz reports that the controller process is alive
+func (h *HealthHandler) Healthz(c fuego.ContextNoBody) (*model.HealthStatus, error) {
+	return &model.HealthStatus{
+		Status:    "ok",
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// Readyz reports whether the required buses are connected and responding.
+// It answers 503 Service Unavailable with the per-bus detail when not ready.
+func (h *HealthHandler) Readyz(c fuego.ContextNoBody) (*model.ReadinessStatus, error) {
+	status := h.readiness(c.Context())
+
+	if !status.Ready {
+		// The status must be written before fuego serializes the body
+		c.Res.Header().Set("Content-Type", "application/json")
+		c.SetStatus(http.StatusServiceUnavailable)
+	}
+
+	return status, nil
+}
+
+// readiness checks every bus and evaluates the required ones
+func (h *HealthHandler) readiness(ctx context.Context) *model.ReadinessStatus {
+	status := &model.ReadinessStatus{
+		Buses:     make([]model.BusHealth, 0, len(busTypes)),
+		Timestamp: time.Now(),
+	}
+
+	ready := len(h.requiredBuses) > 0
+	anyConnected := false
+
+	for _, busType := range busTypes {
+		health := h.dbusService.CheckBus(ctx, busType)
+		health.Required = h.requiredBuses[busType]
+
+		if health.Connected {
+			anyConnected = true
+		} else if health.Required {
+			ready = false
+		}
+
+		status.Buses = append(status.Buses, *health)
+	}
+
+	if len(h.requiredBuses) == 0 {
+		ready = anyConnected
+	}
+	status.Ready = ready
+
+	return status
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-fuego/fuego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mesbrj/dbus-controller/internal/handler/handlertest"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+func newHealthContext() (fuego.ContextNoBody, *httptest.ResponseRecorder) {
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+	return fuego.ContextNoBody{Req: req, Res: rec}, rec
+}
+
+func TestHealthHandler_Healthz(t *testing.T) {
+	h := NewHealthHandler(new(handlertest.MockDBusService), nil)
+	c, _ := newHealthContext()
+
+	status, err := h.Healthz(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", status.Status)
+}
+
+func TestHealthHandler_Readyz_RequiredBusConnected(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CheckBus", mock.Anything, "system").Return(&model.BusHealth{Type: "system", Error: "system bus not available"})
+	mockService.On("CheckBus", mock.Anything, "session").Return(&model.BusHealth{Type: "session", Connected: true, ID: "abc"})
+
+	h := NewHealthHandler(mockService, []string{"session"})
+	c, rec := newHealthContext()
+
+	status, err := h.Readyz(c)
+
+	assert.NoError(t, err)
+	assert.True(t, status.Ready)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, status.Buses, 2)
+	assert.False(t, status.Buses[0].Required)
+	assert.True(t, status.Buses[1].Required)
+	mockService.AssertExpectations(t)
+}
+
+func TestHealthHandler_Readyz_RequiredBusDown(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CheckBus", mock.Anything, "system").Return(&model.BusHealth{Type: "system", Error: "system bus not available"})
+	mockService.On("CheckBus", mock.Anything, "session").Return(&model.BusHealth{Type: "session", Connected: true})
+
+	h := NewHealthHandler(mockService, []string{"system", "session"})
+	c, rec := newHealthContext()
+
+	status, err := h.Readyz(c)
+
+	assert.NoError(t, err)
+	assert.False(t, status.Ready)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "system bus not available", status.Buses[0].Error)
+}
+
+func TestHealthHandler_Readyz_NoRequiredBuses(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CheckBus", mock.Anything, "system").Return(&model.BusHealth{Type: "system", Error: "system bus not available"})
+	mockService.On("CheckBus", mock.Anything, "session").Return(&model.BusHealth{Type: "session", Error: "session bus not available"})
+
+	h := NewHealthHandler(mockService, nil)
+	c, rec := newHealthContext()
+
+	status, err := h.Readyz(c)
+
+	assert.NoError(t, err)
+	assert.False(t, status.Ready)
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
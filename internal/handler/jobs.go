@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-fuego/fuego"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// StartJob starts a D-Bus method call in the background and returns its job
+// without waiting for the reply, like an asynchronous CallMethod
+func (h *Handler) StartJob(c *fuego.ContextWithBody[CallMethodRequest]) (*model.Job, error) {
+	busType := c.PathParam("busType")
+	serviceName := c.PathParam("serviceName")
+	interfaceName := c.PathParam("interfaceName")
+	methodName := c.PathParam("methodName")
+	objectPath, err := queryObjectPath(c)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.Body()
+	if err != nil {
+		slog.WarnContext(c.Context(), "Invalid method call body", "error", err)
+		return nil, fuego.BadRequestError{Title: "Invalid request body", Detail: err.Error()}
+	}
+
+	return h.startJob(c, busType, serviceName, objectPath, interfaceName, methodName, body)
+}
+
+// startJob starts the call of a method call request as a job, answering 202
+// with the job and its URL in the Location header
+func (h *Handler) startJob(c *fuego.ContextWithBody[CallMethodRequest], busType, serviceName, objectPath, interfaceName, methodName string, body CallMethodRequest) (*model.Job, error) {
+	if body.NoReply {
+		return nil, fuego.BadRequestError{Title: "Invalid request body", Detail: "no_reply is not supported for jobs", Err: fmt.Errorf("no_reply job")}
+	}
+
+	end := startConversion(c.Context())
+	args, err := methodArgs(body)
+	end(err)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err := h.dbusService.StartJob(c.Context(), busType, serviceName, objectPath, interfaceName, methodName, body.CallFlags, args)
+	if err != nil {
+		if errors.Is(err, service.ErrTooManyJobs) {
+			return nil, fuego.HTTPError{Title: "Too many jobs", Status: http.StatusTooManyRequests, Detail: err.Error(), Err: err}
+		}
+		return nil, err
+	}
+
+	c.Response().Header().Set("Location", "/jobs/"+job.ID)
+	c.SetStatus(http.StatusAccepted)
+	return job, nil
+}
+
+// preferAsync reports whether a request asks for an asynchronous answer with
+// the respond-async preference of RFC 7240
+func preferAsync(r *http.Request) bool {
+	for _, header := range r.Header.Values("Prefer") {
+		for _, preference := range strings.Split(header, ",") {
+			token, _, _ := strings.Cut(preference, ";")
+			if strings.EqualFold(strings.TrimSpace(token), "respond-async") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ListJobs returns the pending, running and recently finished jobs
+func (h *Handler) ListJobs(c fuego.ContextNoBody) ([]model.Job, error) {
+	return h.dbusService.ListJobs(c.Context())
+}
+
+// GetJob returns the status of a job, with the result of its call once finished
+func (h *Handler) GetJob(c fuego.ContextNoBody) (*model.Job, error) {
+	jobID := c.PathParam("jobId")
+
+	job, err := h.dbusService.GetJob(c.Context(), jobID)
+	if errors.Is(err, service.ErrJobNotFound) {
+		return nil, fuego.NotFoundError{Title: "Job not found", Detail: jobID, Err: err}
+	}
+	return job, err
+}
+
+// CancelJob removes a job, abandoning its call if it has not finished
+func (h *Handler) CancelJob(c fuego.ContextNoBody) (any, error) {
+	jobID := c.PathParam("jobId")
+
+	if err := h.dbusService.CancelJob(c.Context(), jobID); err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			return nil, fuego.NotFoundError{Title: "Job not found", Detail: jobID, Err: err}
+		}
+		return nil, err
+	}
+
+	c.SetStatus(http.StatusNoContent)
+	return nil, nil
+}
+
+// StreamJob waits for a job as a Server-Sent Events stream. A "done" event
+// carries the job once it finishes or is abandoned; the stream ends with a
+// "close" event instead when the server shuts down.
+func (h *Handler) StreamJob(w http.ResponseWriter, r *http.Request) {
+	jobID := r.PathValue("jobId")
+
+	jobs, err := h.dbusService.WatchJob(r.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			err = fuego.NotFoundError{Title: "Job not found", Detail: jobID, Err: err}
+		}
+		fuego.SendJSONError(w, r, err)
+		return
+	}
+
+	serveEvents(w, r, jobs, func(job *model.Job) (sseEvent, bool) {
+		return sseEvent{name: "done", data: job}, true
+	}, closeEvent)
+}
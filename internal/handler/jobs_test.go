@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-fuego/fuego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbrj/dbus-controller/internal/handler/handlertest"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+func TestHandler_StartJob(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("StartJob", mock.Anything, "system", "com.example.Flash", "/fw", "com.example.Flash", "Write", model.CallFlags{NoAutoStart: true}, []interface{}{"image.bin"}).
+		Return(&model.Job{ID: "0af1", Status: service.JobPending}, nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/methods/{methodName}/jobs", NewHandler(mockService).StartJob)
+	}, http.MethodPost, "/buses/system/services/com.example.Flash/interfaces/com.example.Flash/methods/Write/jobs?path=/fw", `{"args": ["image.bin"], "no_auto_start": true}`)
+
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	assert.Equal(t, "/jobs/0af1", rec.Header().Get("Location"))
+	assert.Contains(t, rec.Body.String(), `"status":"pending"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_StartJob_TooManyJobs(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("StartJob", mock.Anything, "system", "com.example.Flash", "/fw", "com.example.Flash", "Write", model.CallFlags{}, []interface{}(nil)).
+		Return(nil, fmt.Errorf("%w: 64 jobs waiting", service.ErrTooManyJobs))
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/methods/{methodName}/jobs", NewHandler(mockService).StartJob)
+	}, http.MethodPost, "/buses/system/services/com.example.Flash/interfaces/com.example.Flash/methods/Write/jobs?path=/fw", `{}`)
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+}
+
+func TestHandler_StartJob_NoReply(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/methods/{methodName}/jobs", NewHandler(mockService).StartJob)
+	}, http.MethodPost, "/buses/system/services/com.example.Flash/interfaces/com.example.Flash/methods/Write/jobs?path=/fw", `{"no_reply": true}`)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockService.AssertNotCalled(t, "StartJob")
+}
+
+func TestHandler_CallMethod_Async(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("StartJob", mock.Anything, "system", "com.example.Flash", "/fw", "com.example.Flash", "Write", model.CallFlags{}, []interface{}{"image.bin"}).
+		Return(&model.Job{ID: "0af1", Status: service.JobPending}, nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/methods/{methodName}/call", NewHandler(mockService).CallMethod)
+	}, http.MethodPost, "/buses/system/services/com.example.Flash/interfaces/com.example.Flash/methods/Write/call?path=/fw", `{"args": ["image.bin"], "async": true}`)
+
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	assert.Equal(t, "/jobs/0af1", rec.Header().Get("Location"))
+	assert.Contains(t, rec.Body.String(), `"id":"0af1"`)
+	mockService.AssertExpectations(t)
+	mockService.AssertNotCalled(t, "CallMethodWithFlags")
+}
+
+func TestHandler_CallMethod_PreferAsync(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("StartJob", mock.Anything, "system", "com.example.Flash", "/fw", "com.example.Flash", "Write", model.CallFlags{}, []interface{}{"image.bin"}).
+		Return(&model.Job{ID: "0af1", Status: service.JobPending}, nil)
+
+	server := fuego.NewServer(fuego.WithErrorHandler(ErrorHandler))
+	fuego.Post(server, "/buses/{busType}/services/{serviceName}/interfaces/{interfaceName}/methods/{methodName}/call", NewHandler(mockService).CallMethod)
+	req := httptest.NewRequest(http.MethodPost, "/buses/system/services/com.example.Flash/interfaces/com.example.Flash/methods/Write/call?path=/fw", strings.NewReader(`{"args": ["image.bin"]}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Prefer", "wait=10, respond-async")
+	rec := httptest.NewRecorder()
+
+	server.Mux.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	assert.Equal(t, "/jobs/0af1", rec.Header().Get("Location"))
+	assert.Equal(t, "respond-async", rec.Header().Get("Preference-Applied"))
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_GetJob_NotFound(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("GetJob", mock.Anything, "missing").Return(nil, fmt.Errorf("%w: missing", service.ErrJobNotFound))
+
+	h := NewHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/jobs/missing", nil)
+	req.SetPathValue("jobId", "missing")
+
+	_, err := h.GetJob(fuego.ContextNoBody{Req: req, Res: httptest.NewRecorder()})
+
+	var notFound fuego.NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestHandler_CancelJob(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CancelJob", mock.Anything, "0af1").Return(nil)
+
+	h := NewHandler(mockService)
+	req := httptest.NewRequest(http.MethodDelete, "/jobs/0af1", nil)
+	req.SetPathValue("jobId", "0af1")
+	rec := httptest.NewRecorder()
+
+	_, err := h.CancelJob(fuego.ContextNoBody{Req: req, Res: rec})
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_StreamJob(t *testing.T) {
+	jobs := make(chan *model.Job, 1)
+	jobs <- &model.Job{ID: "0af1", Status: service.JobSucceeded, Result: &model.MethodCallResult{Success: true}}
+	close(jobs)
+
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("WatchJob", mock.Anything, "0af1").Return(jobs, nil)
+
+	h := NewHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/jobs/0af1/events", nil)
+	req.SetPathValue("jobId", "0af1")
+	rec := httptest.NewRecorder()
+
+	h.StreamJob(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(rec.Body.String(), "event: done\ndata: {\"id\":\"0af1\",\"status\":\"succeeded\""))
+}
+
+func TestHandler_StreamJob_Closed(t *testing.T) {
+	jobs := make(chan *model.Job)
+	close(jobs)
+
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("WatchJob", mock.Anything, "0af1").Return(jobs, nil)
+
+	h := NewHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/jobs/0af1/events", nil)
+	req.SetPathValue("jobId", "0af1")
+	rec := httptest.NewRecorder()
+
+	h.StreamJob(rec, req)
+
+	assert.Equal(t, "event: close\ndata: {}\n\n", rec.Body.String())
+}
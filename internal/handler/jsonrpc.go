@@ -0,0 +1,292 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-fuego/fuego"
+	"github.com/godbus/dbus/v5"
+
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+	rpcDBusError      = -32000 // Failed D-Bus call, the error name is given as data
+)
+
+// rpcMaxBody bounds the size of a JSON-RPC request or batch
+const rpcMaxBody = 4 << 20
+
+// rpcCall is the D-Bus method call of a JSON-RPC request
+type rpcCall struct {
+	bus, service, path, iface, method string
+	args                              []interface{}
+	named                             map[string]any // Arguments keyed by name, resolved from the introspection data
+	flags                             model.CallFlags
+}
+
+// JSONRPC serves JSON-RPC 2.0 requests and batches calling D-Bus methods.
+// Requests without an ID are notifications, sent as NoReplyExpected calls.
+func (h *Handler) JSONRPC(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, rpcMaxBody))
+	if err != nil {
+		writeRPC(w, rpcFailure(nil, &model.RPCError{Code: rpcParseError, Message: "Parse error", Data: err.Error()}))
+		return
+	}
+	payload = bytes.TrimSpace(payload)
+	if !json.Valid(payload) {
+		writeRPC(w, rpcFailure(nil, &model.RPCError{Code: rpcParseError, Message: "Parse error"}))
+		return
+	}
+
+	if payload[0] != '[' {
+		if response := h.serveRPC(r.Context(), payload); response != nil {
+			writeRPC(w, response)
+		} else {
+			w.WriteHeader(http.StatusNoContent)
+		}
+		return
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(payload, &batch); err != nil || len(batch) == 0 {
+		writeRPC(w, rpcFailure(nil, &model.RPCError{Code: rpcInvalidRequest, Message: "Invalid Request", Data: "empty batch"}))
+		return
+	}
+
+	responses := make([]*model.RPCResponse, 0, len(batch))
+	for _, request := range batch {
+		if response := h.serveRPC(r.Context(), request); response != nil {
+			responses = append(responses, response)
+		}
+	}
+	if len(responses) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeRPC(w, responses)
+}
+
+// serveRPC runs a single request, returning no response for notifications
+func (h *Handler) serveRPC(ctx context.Context, payload json.RawMessage) *model.RPCResponse {
+	var request model.RPCRequest
+	if err := json.Unmarshal(payload, &request); err != nil {
+		return rpcFailure(nil, &model.RPCError{Code: rpcInvalidRequest, Message: "Invalid Request", Data: err.Error()})
+	}
+	if request.JSONRPC != "2.0" || request.Method == "" {
+		return rpcFailure(request.ID, &model.RPCError{Code: rpcInvalidRequest, Message: "Invalid Request", Data: `jsonrpc must be "2.0" and method is required`})
+	}
+
+	notification := request.ID == nil
+	result, err := h.rpcMethodCall(ctx, &request, notification)
+	if notification {
+		if err != nil {
+			slog.WarnContext(ctx, "JSON-RPC notification failed", "method", request.Method, "error", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return rpcFailure(request.ID, rpcError(err))
+	}
+
+	encoded, err := json.Marshal(result)
+	if err != nil {
+		return rpcFailure(request.ID, &model.RPCError{Code: rpcInternalError, Message: "Internal error", Data: err.Error()})
+	}
+	return &model.RPCResponse{JSONRPC: "2.0", Result: encoded, ID: request.ID}
+}
+
+// rpcMethodCall calls the D-Bus method of a request. Positional arguments return
+// the array of return values, named arguments an object keyed by their names.
+func (h *Handler) rpcMethodCall(ctx context.Context, request *model.RPCRequest, noReply bool) (any, error) {
+	end := startConversion(ctx)
+	call, err := parseRPCCall(request)
+	end(err)
+	if err != nil {
+		return nil, err
+	}
+
+	var method *model.MethodInfo
+	if call.named != nil {
+		iface, err := h.dbusService.GetInterfaceInfo(ctx, call.bus, call.service, call.path, call.iface)
+		if err != nil {
+			if errors.Is(err, service.ErrInterfaceNotFound) {
+				return nil, fuego.NotFoundError{Title: "Interface not found", Detail: err.Error(), Err: err}
+			}
+			return nil, err
+		}
+		if method = findMethod(iface, call.method); method == nil {
+			return nil, fuego.NotFoundError{
+				Title:  "Method not found",
+				Detail: fmt.Sprintf("%s.%s on %s", call.iface, call.method, call.path),
+				Err:    fmt.Errorf("method %s.%s not found on %s", call.iface, call.method, call.path),
+			}
+		}
+		end := startConversion(ctx)
+		call.args, err = namedArgs(method.InArgs, call.named)
+		end(err)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	slog.InfoContext(ctx, "Calling D-Bus method",
+		"bus", call.bus, "service", call.service, "path", call.path, "interface", call.iface, "method", call.method,
+		"args", len(call.args), "no_reply", noReply)
+
+	if noReply {
+		call.flags.NoReply = true
+	}
+
+	result, err := h.dbusService.CallMethodWithFlags(ctx, call.bus, call.service, call.path, call.iface, call.method, call.flags, call.args)
+	if err != nil {
+		return nil, err
+	}
+	if err := resultError(result); err != nil {
+		return nil, err
+	}
+
+	if method != nil {
+		return namedValues(method.OutArgs, result.ReturnValues), nil
+	}
+	if result.ReturnValues == nil {
+		return []interface{}{}, nil
+	}
+	return result.ReturnValues, nil
+}
+
+// parseRPCCall resolves the target and arguments of a request. The method is
+// either bus/service/object/path/interface.Member, with positional arguments as
+// an array of params or named arguments as an object, or "call" with params
+// holding the target and the arguments as in a method call request.
+func parseRPCCall(request *model.RPCRequest) (*rpcCall, error) {
+	if request.Method == "call" {
+		var params model.RPCCallParams
+		if err := json.Unmarshal(request.Params, &params); err != nil {
+			return nil, rpcParamsError(fmt.Errorf("call params must be an object: %w", err))
+		}
+		if params.Bus == "" || params.Service == "" || params.Interface == "" || params.Method == "" {
+			return nil, rpcParamsError(errors.New("bus, service, interface and method are required"))
+		}
+		call := &rpcCall{bus: params.Bus, service: params.Service, path: params.Path, iface: params.Interface, method: params.Method, flags: params.CallFlags}
+		if call.path == "" {
+			call.path = "/"
+		}
+		if !dbus.ObjectPath(call.path).IsValid() {
+			return nil, rpcParamsError(fmt.Errorf("invalid object path: %s", call.path))
+		}
+
+		args, err := methodArgs(CallMethodRequest{Args: params.Args, Syntax: params.Syntax, Signature: params.Signature, TextArgs: params.TextArgs})
+		if err != nil {
+			return nil, err
+		}
+		call.args = args
+		return call, nil
+	}
+
+	parts := strings.Split(request.Method, "/")
+	if len(parts) < 3 {
+		return nil, rpcMethodError(request.Method)
+	}
+	member := parts[len(parts)-1]
+	dot := strings.LastIndex(member, ".")
+	path := "/" + strings.Join(parts[2:len(parts)-1], "/")
+	if parts[0] == "" || parts[1] == "" || dot <= 0 || dot == len(member)-1 || !dbus.ObjectPath(path).IsValid() {
+		return nil, rpcMethodError(request.Method)
+	}
+	call := &rpcCall{bus: parts[0], service: parts[1], path: path, iface: member[:dot], method: member[dot+1:]}
+
+	params := bytes.TrimSpace(request.Params)
+	switch {
+	case len(params) == 0 || bytes.Equal(params, []byte("null")):
+	case params[0] == '[':
+		if err := json.Unmarshal(params, &call.args); err != nil {
+			return nil, rpcParamsError(err)
+		}
+	case params[0] == '{':
+		if err := json.Unmarshal(params, &call.named); err != nil {
+			return nil, rpcParamsError(err)
+		}
+	default:
+		return nil, rpcParamsError(errors.New("params must be an array or an object"))
+	}
+	return call, nil
+}
+
+// rpcMethodError reports a method that does not name a D-Bus method
+func rpcMethodError(method string) error {
+	return fuego.NotFoundError{
+		Title:  "Method not found",
+		Detail: fmt.Sprintf("%q is not bus/service/object/path/interface.Member or call", method),
+		Err:    fmt.Errorf("invalid JSON-RPC method: %s", method),
+	}
+}
+
+// rpcParamsError reports invalid params
+func rpcParamsError(err error) error {
+	return fuego.BadRequestError{Title: "Invalid params", Detail: err.Error(), Err: err}
+}
+
+// rpcError converts an error to a JSON-RPC error: request errors to the invalid
+// params and method not found codes, and D-Bus errors to the same codes or to
+// the server error code, with the D-Bus error name as data
+func rpcError(err error) *model.RPCError {
+	var errorStatus fuego.ErrorWithStatus
+	if errors.As(err, &errorStatus) {
+		rpcErr := &model.RPCError{Code: rpcInternalError, Message: err.Error()}
+		switch errorStatus.StatusCode() {
+		case http.StatusBadRequest:
+			rpcErr.Code = rpcInvalidParams
+		case http.StatusNotFound:
+			rpcErr.Code = rpcMethodNotFound
+		}
+		var httpErr fuego.HTTPError
+		if errors.As(err, &httpErr) {
+			rpcErr.Message = httpErr.Detail
+			if len(httpErr.Errors) > 0 {
+				rpcErr.Data = httpErr.Errors
+			}
+		}
+		return rpcErr
+	}
+
+	if name := service.ErrorName(err); name != "" {
+		rpcErr := &model.RPCError{Code: rpcDBusError, Message: dbusErrorMessage(err), Data: map[string]string{"name": name}}
+		switch dbusErrorStatus(name) {
+		case http.StatusBadRequest:
+			rpcErr.Code = rpcInvalidParams
+		case http.StatusNotFound:
+			rpcErr.Code = rpcMethodNotFound
+		}
+		return rpcErr
+	}
+
+	return &model.RPCError{Code: rpcInternalError, Message: err.Error()}
+}
+
+// rpcFailure returns the error response of a request
+func rpcFailure(id json.RawMessage, err *model.RPCError) *model.RPCResponse {
+	return &model.RPCResponse{JSONRPC: "2.0", Error: err, ID: id}
+}
+
+// writeRPC writes a JSON-RPC response or batch of responses
+func writeRPC(w http.ResponseWriter, response any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		slog.Warn("Failed to write JSON-RPC response", "error", err)
+	}
+}
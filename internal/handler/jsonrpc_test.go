@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbrj/dbus-controller/internal/handler/handlertest"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+func serveJSONRPC(mockService *handlertest.MockDBusService, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/jsonrpc", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	NewHandler(mockService).JSONRPC(rec, req)
+	return rec
+}
+
+func TestHandler_JSONRPC_PositionalParams(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethodWithFlags", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Hello",
+		model.CallFlags{}, []interface{}{"World"}).
+		Return(&model.MethodCallResult{Success: true, ReturnValues: []interface{}{"Hello, World!"}}, nil)
+
+	rec := serveJSONRPC(mockService, `{"jsonrpc": "2.0", "id": 1,
+		"method": "session/com.example.HelloWorld/com/example/HelloWorld/com.example.HelloWorld.Hello", "params": ["World"]}`)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "id": 1, "result": ["Hello, World!"]}`, rec.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_JSONRPC_NamedParams(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("GetInterfaceInfo", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld").
+		Return(helloInterface, nil)
+	mockService.On("CallMethodWithFlags", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld", "Hello",
+		model.CallFlags{}, []interface{}{"World", uint32(3)}).
+		Return(&model.MethodCallResult{Success: true, ReturnValues: []interface{}{"Hello, World!", true}}, nil)
+
+	rec := serveJSONRPC(mockService, `{"jsonrpc": "2.0", "id": "a",
+		"method": "session/com.example.HelloWorld/com.example.HelloWorld.Hello", "params": {"name": "World", "arg1": 3}}`)
+
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "id": "a", "result": {"greeting": "Hello, World!", "arg1": true}}`, rec.Body.String())
+}
+
+func TestHandler_JSONRPC_StructuredParams(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethodWithFlags", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Add",
+		model.CallFlags{}, []interface{}{uint32(1), int64(-2)}).
+		Return(&model.MethodCallResult{Success: true}, nil)
+
+	rec := serveJSONRPC(mockService, `{"jsonrpc": "2.0", "id": 2, "method": "call", "params": {
+		"bus": "session", "service": "com.example.HelloWorld", "path": "/com/example/HelloWorld",
+		"interface": "com.example.HelloWorld", "method": "Add", "signature": "ux", "text_args": ["1", "-2"]}}`)
+
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "id": 2, "result": []}`, rec.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_JSONRPC_Batch(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethodWithFlags", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld", "Hello", model.CallFlags{}, []interface{}{"A"}).
+		Return(&model.MethodCallResult{Success: true, ReturnValues: []interface{}{"Hello, A!"}}, nil)
+	mockService.On("CallMethodWithFlags", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld", "Hello", model.CallFlags{}, []interface{}{"B"}).
+		Return(&model.MethodCallResult{Success: false, Error: "denied", ErrorName: "org.freedesktop.DBus.Error.AccessDenied"}, nil)
+	mockService.On("CallMethodWithFlags", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld", "Hello", model.CallFlags{NoReply: true}, []interface{}{"C"}).
+		Return(&model.MethodCallResult{Success: true, NoReply: true}, nil)
+
+	rec := serveJSONRPC(mockService, `[
+		{"jsonrpc": "2.0", "id": 1, "method": "session/com.example.HelloWorld/com.example.HelloWorld.Hello", "params": ["A"]},
+		{"jsonrpc": "2.0", "id": 2, "method": "session/com.example.HelloWorld/com.example.HelloWorld.Hello", "params": ["B"]},
+		{"jsonrpc": "2.0", "method": "session/com.example.HelloWorld/com.example.HelloWorld.Hello", "params": ["C"]},
+		{"jsonrpc": "2.0", "id": 3, "method": "Hello"},
+		1
+	]`)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `[
+		{"jsonrpc": "2.0", "id": 1, "result": ["Hello, A!"]},
+		{"jsonrpc": "2.0", "id": 2, "error": {"code": -32000, "message": "denied", "data": {"name": "org.freedesktop.DBus.Error.AccessDenied"}}},
+		{"jsonrpc": "2.0", "id": 3, "error": {"code": -32601, "message": "\"Hello\" is not bus/service/object/path/interface.Member or call"}},
+		{"jsonrpc": "2.0", "id": null, "error": {"code": -32600, "message": "Invalid Request", "data": "json: cannot unmarshal number into Go value of type model.RPCRequest"}}
+	]`, rec.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_JSONRPC_Notifications(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethodWithFlags", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld", "Hello", model.CallFlags{NoReply: true}, []interface{}(nil)).
+		Return(&model.MethodCallResult{Success: true, NoReply: true}, nil)
+
+	rec := serveJSONRPC(mockService, `[{"jsonrpc": "2.0", "method": "session/com.example.HelloWorld/com.example.HelloWorld.Hello"}]`)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_JSONRPC_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"parse error", `{"jsonrpc": "2.0",`, rpcParseError},
+		{"empty batch", `[]`, rpcInvalidRequest},
+		{"missing version", `{"id": 1, "method": "call"}`, rpcInvalidRequest},
+		{"invalid path", `{"jsonrpc": "2.0", "id": 1, "method": "session/com.example.HelloWorld/a-b/com.example.HelloWorld.Hello"}`, rpcMethodNotFound},
+		{"scalar params", `{"jsonrpc": "2.0", "id": 1, "method": "session/com.example.HelloWorld/com.example.HelloWorld.Hello", "params": 1}`, rpcInvalidParams},
+		{"incomplete call", `{"jsonrpc": "2.0", "id": 1, "method": "call", "params": {"bus": "session"}}`, rpcInvalidParams},
+		{"args and text args", `{"jsonrpc": "2.0", "id": 1, "method": "call", "params": {"bus": "session", "service": "a.b", "interface": "a.b", "method": "M",
+			"args": [1], "signature": "u", "text_args": ["1"]}}`, rpcInvalidParams},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serveJSONRPC(new(handlertest.MockDBusService), tt.body)
+
+			var response model.RPCResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			require.NotNil(t, response.Error)
+			assert.Equal(t, tt.code, response.Error.Code)
+		})
+	}
+}
+
+func TestRPCError(t *testing.T) {
+	err := rpcError(dbus.Error{Name: "org.freedesktop.DBus.Error.UnknownMethod", Body: []interface{}{"no such method"}})
+	assert.Equal(t, rpcMethodNotFound, err.Code)
+	assert.Equal(t, map[string]string{"name": "org.freedesktop.DBus.Error.UnknownMethod"}, err.Data)
+
+	err = rpcError(dbus.Error{Name: "org.freedesktop.DBus.Error.InvalidArgs", Body: []interface{}{"bad"}})
+	assert.Equal(t, rpcInvalidParams, err.Code)
+
+	_, parseErr := parseRPCCall(&model.RPCRequest{Method: "call", Params: []byte(`{"bus": "session", "service": "a.b", "interface": "a.b", "method": "M", "signature": "u", "text_args": ["x"]}`)})
+	require.Error(t, parseErr)
+	err = rpcError(parseErr)
+	assert.Equal(t, rpcInvalidParams, err.Code)
+	assert.NotNil(t, err.Data)
+}
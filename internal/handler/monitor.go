@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-fuego/fuego"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// StreamMonitor streams the messages of a bus as Server-Sent Events named
+// after the message type, selected by the match query parameters. The stream
+// ends with a "close" event when the bus closes the monitor or the server
+// shuts down.
+func (h *Handler) StreamMonitor(w http.ResponseWriter, r *http.Request) {
+	busType := r.PathValue("busType")
+	rules := r.URL.Query()["match"]
+
+	messages, err := h.dbusService.Monitor(r.Context(), busType, rules)
+	if err != nil {
+		if errors.Is(err, service.ErrTooManyMonitors) {
+			err = fuego.HTTPError{Title: "Too many monitors", Status: http.StatusTooManyRequests, Detail: err.Error(), Err: err}
+		}
+		fuego.SendJSONError(w, r, ErrorHandler(err))
+		return
+	}
+
+	serveEvents(w, r, messages, func(message *model.MonitorMessage) (sseEvent, bool) {
+		return sseEvent{name: message.Type, data: message}, false
+	}, closeEvent)
+}
+
+// defaultCaptureDuration and maxCaptureDuration bound the captures
+const (
+	defaultCaptureDuration = 30 * time.Second
+	maxCaptureDuration     = 10 * time.Minute
+)
+
+// StreamCapture records the messages of a bus selected by the match query
+// parameters for the duration query parameter, and sends them as a pcap file
+// with the DLT_DBUS link type. Records are sent as they are captured, and the
+// Capture-Messages and Capture-Dropped trailers count them once it ends.
+func (h *Handler) StreamCapture(w http.ResponseWriter, r *http.Request) {
+	busType := r.PathValue("busType")
+	rules := r.URL.Query()["match"]
+
+	duration, err := queryDuration("duration", r.URL.Query().Get("duration"), defaultCaptureDuration, maxCaptureDuration)
+	if err != nil {
+		fuego.SendJSONError(w, r, err)
+		return
+	}
+
+	// The capture may outlast the server write timeout
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(duration + streamKeepAlive)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		slog.WarnContext(r.Context(), "Failed to extend write deadline", "error", err)
+	}
+
+	filename := fmt.Sprintf("%s-%s.pcap", busType, time.Now().UTC().Format("20060102T150405Z"))
+	out := &captureWriter{w: w, rc: rc, filename: filename}
+
+	stats, err := h.dbusService.Capture(r.Context(), busType, rules, duration, out)
+	if err != nil && !out.started {
+		if errors.Is(err, service.ErrTooManyMonitors) {
+			err = fuego.HTTPError{Title: "Too many monitors", Status: http.StatusTooManyRequests, Detail: err.Error(), Err: err}
+		}
+		fuego.SendJSONError(w, r, ErrorHandler(err))
+		return
+	}
+	if err != nil && r.Context().Err() == nil {
+		slog.WarnContext(r.Context(), "Capture ended early", "bus", busType, "error", err)
+	}
+	if out.started && stats != nil {
+		w.Header().Set(captureMessagesTrailer, strconv.Itoa(stats.Messages))
+		w.Header().Set(captureDroppedTrailer, strconv.FormatUint(stats.Dropped, 10))
+	}
+}
+
+// Trailers of a capture counting its messages
+const (
+	captureMessagesTrailer = "Capture-Messages"
+	captureDroppedTrailer  = "Capture-Dropped"
+)
+
+// captureWriter sends the headers of a capture with its first write, once the
+// monitor is open, and flushes every write
+type captureWriter struct {
+	w        http.ResponseWriter
+	rc       *http.ResponseController
+	filename string
+	started  bool
+}
+
+func (c *captureWriter) Write(p []byte) (int, error) {
+	if !c.started {
+		c.w.Header().Set("Content-Type", "application/vnd.tcpdump.pcap")
+		c.w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", c.filename))
+		c.w.Header().Set("Cache-Control", "no-cache")
+		c.w.Header().Set("Trailer", captureMessagesTrailer+", "+captureDroppedTrailer)
+		c.w.WriteHeader(http.StatusOK)
+		c.started = true
+	}
+
+	n, err := c.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	return n, c.rc.Flush()
+}
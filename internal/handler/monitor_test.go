@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mesbrj/dbus-controller/internal/handler/handlertest"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+func TestHandler_StreamMonitor(t *testing.T) {
+	messages := make(chan *model.MonitorMessage, 2)
+	messages <- &model.MonitorMessage{Type: "method_call", Serial: 4, Sender: ":1.9", Destination: "com.example.Door", Path: "/", Interface: "com.example.Door", Member: "Open"}
+	messages <- &model.MonitorMessage{Type: "method_return", Serial: 9, ReplySerial: 4, Sender: ":1.3", Destination: ":1.9", Dropped: 2}
+	close(messages)
+
+	rules := []string{"destination='com.example.Door'", "sender='com.example.Door'"}
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("Monitor", mock.Anything, "session", rules).Return(messages, nil)
+
+	h := NewHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/buses/session/monitor?match=destination%3D%27com.example.Door%27&match=sender%3D%27com.example.Door%27", nil)
+	req.SetPathValue("busType", "session")
+	rec := httptest.NewRecorder()
+
+	h.StreamMonitor(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	assert.Contains(t, body, "event: method_call\ndata: {\"type\":\"method_call\",\"serial\":4,\"sender\":\":1.9\"")
+	assert.Contains(t, body, "event: method_return\ndata: {\"type\":\"method_return\",\"serial\":9,\"reply_serial\":4,")
+	assert.Contains(t, body, `"dropped":2`)
+	assert.True(t, strings.HasSuffix(body, "event: close\ndata: {}\n\n"))
+}
+
+func TestHandler_StreamMonitor_TooMany(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("Monitor", mock.Anything, "session", []string(nil)).
+		Return(nil, fmt.Errorf("%w: 4 monitors open", service.ErrTooManyMonitors))
+
+	h := NewHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/buses/session/monitor", nil)
+	req.SetPathValue("busType", "session")
+	rec := httptest.NewRecorder()
+
+	h.StreamMonitor(rec, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+}
+
+func TestHandler_StreamCapture(t *testing.T) {
+	rules := []string{"type='signal'"}
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("Capture", mock.Anything, "session", rules, 2*time.Second, mock.Anything).
+		Run(func(args mock.Arguments) {
+			w := args.Get(4).(io.Writer)
+			_, _ = w.Write([]byte("header"))
+			_, _ = w.Write([]byte("record"))
+		}).
+		Return(&model.CaptureStats{Messages: 1, Dropped: 4}, nil)
+
+	h := NewHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/buses/session/capture?duration=2s&match=type%3D%27signal%27", nil)
+	req.SetPathValue("busType", "session")
+	rec := httptest.NewRecorder()
+
+	h.StreamCapture(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/vnd.tcpdump.pcap", rec.Header().Get("Content-Type"))
+	assert.Regexp(t, `^attachment; filename="session-\d{8}T\d{6}Z\.pcap"$`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, "headerrecord", rec.Body.String())
+	assert.Equal(t, "1", rec.Result().Trailer.Get("Capture-Messages"))
+	assert.Equal(t, "4", rec.Result().Trailer.Get("Capture-Dropped"))
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_StreamCapture_Errors(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("Capture", mock.Anything, "session", []string(nil), defaultCaptureDuration, mock.Anything).
+		Return(nil, fmt.Errorf("%w: 4 monitors open", service.ErrTooManyMonitors))
+	h := NewHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodGet, "/buses/session/capture", nil)
+	req.SetPathValue("busType", "session")
+	rec := httptest.NewRecorder()
+	h.StreamCapture(rec, req)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	for _, duration := range []string{"forever", "-1s", "1h"} {
+		req = httptest.NewRequest(http.MethodGet, "/buses/session/capture?duration="+duration, nil)
+		req.SetPathValue("busType", "session")
+		rec = httptest.NewRecorder()
+		h.StreamCapture(rec, req)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, duration)
+	}
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-fuego/fuego"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// ListNames returns the running and activatable names on a bus
+func (h *Handler) ListNames(c fuego.ContextNoBody) ([]model.NameInfo, error) {
+	busType := c.PathParam("busType")
+	return h.dbusService.ListNames(c.Context(), busType)
+}
+
+// RequestName requests a well-known name for the connection of the controller
+func (h *Handler) RequestName(c *fuego.ContextWithBody[model.NameFlags]) (*model.NameOwnership, error) {
+	busType := c.PathParam("busType")
+	name := c.PathParam("name")
+
+	flags, err := c.Body()
+	if err != nil {
+		slog.WarnContext(c.Context(), "Invalid name request body", "error", err)
+		return nil, fuego.BadRequestError{Title: "Invalid request body", Detail: err.Error()}
+	}
+
+	return h.dbusService.RequestName(c.Context(), busType, name, flags)
+}
+
+// ReleaseName releases a well-known name owned or queued for by the controller
+func (h *Handler) ReleaseName(c fuego.ContextNoBody) (*model.NameOwnership, error) {
+	busType := c.PathParam("busType")
+	name := c.PathParam("name")
+	return h.dbusService.ReleaseName(c.Context(), busType, name)
+}
+
+// ListQueuedOwners returns the connections queued for a well-known name
+func (h *Handler) ListQueuedOwners(c fuego.ContextNoBody) (*model.NameQueue, error) {
+	busType := c.PathParam("busType")
+	name := c.PathParam("name")
+	return h.dbusService.ListQueuedOwners(c.Context(), busType, name)
+}
+
+// StreamNameOwners streams the name owner changes of a bus as Server-Sent
+// Events, selected by the pattern and unique query parameters. The stream ends
+// with a "close" event when the server shuts down.
+func (h *Handler) StreamNameOwners(w http.ResponseWriter, r *http.Request) {
+	busType := r.PathValue("busType")
+	pattern := r.URL.Query().Get("pattern")
+	unique := r.URL.Query().Get("unique") == "true"
+
+	events, err := h.dbusService.WatchNameOwners(r.Context(), busType, pattern, unique)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidNamePattern) {
+			err = fuego.BadRequestError{Title: "Invalid name pattern", Detail: err.Error(), Err: err}
+		}
+		fuego.SendJSONError(w, r, ErrorHandler(err))
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Name owner stream opened", "bus", busType, "pattern", pattern)
+	serveEvents(w, r, events, func(event *model.NameOwnerEvent) (sseEvent, bool) {
+		return sseEvent{name: event.Change, data: event}, false
+	}, closeEvent)
+	slog.InfoContext(r.Context(), "Name owner stream closed", "bus", busType, "pattern", pattern)
+}
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-fuego/fuego"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbrj/dbus-controller/internal/handler/handlertest"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+func TestHandler_ListNames(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("ListNames", mock.Anything, "session").Return([]model.NameInfo{
+		{Name: "com.example.Flash", Activatable: true},
+		{Name: "org.freedesktop.DBus", Running: true, Activatable: true},
+	}, nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Get(s, "/buses/{busType}/names", NewHandler(mockService).ListNames)
+	}, http.MethodGet, "/buses/session/names", "")
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `[{"name":"com.example.Flash","running":false,"activatable":true},{"name":"org.freedesktop.DBus","running":true,"activatable":true}]`, rec.Body.String())
+}
+
+func TestHandler_RequestName(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("RequestName", mock.Anything, "session", "com.example.Test", model.NameFlags{ReplaceExisting: true, DoNotQueue: true}).
+		Return(&model.NameOwnership{Name: "com.example.Test", Reply: "primary_owner", Owner: ":1.7", Connection: ":1.7"}, nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/names/{name}/request", NewHandler(mockService).RequestName)
+	}, http.MethodPost, "/buses/session/names/com.example.Test/request", `{"replace_existing": true, "do_not_queue": true}`)
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `{"name":"com.example.Test","reply":"primary_owner","owner":":1.7","connection":":1.7"}`, rec.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_RequestName_InvalidName(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("RequestName", mock.Anything, "session", ":1.3", model.NameFlags{}).
+		Return(nil, dbus.Error{Name: "org.freedesktop.DBus.Error.InvalidArgs", Body: []interface{}{"Cannot acquire a service starting with ':'"}})
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/names/{name}/request", NewHandler(mockService).RequestName)
+	}, http.MethodPost, "/buses/session/names/:1.3/request", `{}`)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "org.freedesktop.DBus.Error.InvalidArgs")
+}
+
+func TestHandler_ReleaseName(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("ReleaseName", mock.Anything, "session", "com.example.Test").
+		Return(&model.NameOwnership{Name: "com.example.Test", Reply: "not_owner", Owner: ":1.9", Connection: ":1.7"}, nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/names/{name}/release", NewHandler(mockService).ReleaseName)
+	}, http.MethodPost, "/buses/session/names/com.example.Test/release", "")
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"reply":"not_owner"`)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_ListQueuedOwners(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("ListQueuedOwners", mock.Anything, "session", "com.example.Test").
+		Return(&model.NameQueue{Name: "com.example.Test", Owners: []string{":1.9", ":1.7"}, Connection: ":1.7"}, nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Get(s, "/buses/{busType}/names/{name}/owners", NewHandler(mockService).ListQueuedOwners)
+	}, http.MethodGet, "/buses/session/names/com.example.Test/owners", "")
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"owners":[":1.9",":1.7"]`)
+}
+
+func TestHandler_ListServices_Wait(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("WaitForName", mock.Anything, "session", "com.example.Test").Return(":1.9", nil)
+	mockService.On("ListServices", mock.Anything, "session").Return([]string{"com.example.Test"}, nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Get(s, "/buses/{busType}/services", NewHandler(mockService).ListServices)
+	}, http.MethodGet, "/buses/session/services?wait=com.example.Test&timeout=2s", "")
+
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.JSONEq(t, `["com.example.Test"]`, rec.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_ListServices_WaitTimeout(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("WaitForName", mock.Anything, "session", "com.example.Test").
+		Run(func(args mock.Arguments) { <-args.Get(0).(context.Context).Done() }).
+		Return("", context.DeadlineExceeded)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Get(s, "/buses/{busType}/services", NewHandler(mockService).ListServices)
+	}, http.MethodGet, "/buses/session/services?wait=com.example.Test&timeout=10ms", "")
+
+	assert.Equal(t, http.StatusRequestTimeout, rec.Code)
+	assert.Contains(t, rec.Body.String(), "com.example.Test has no owner after 10ms")
+	mockService.AssertNotCalled(t, "ListServices", mock.Anything, mock.Anything)
+}
+
+func TestHandler_ListServices_InvalidTimeout(t *testing.T) {
+	for _, timeout := range []string{"soon", "0s", "1h"} {
+		rec := serveRoute(func(s *fuego.Server) {
+			fuego.Get(s, "/buses/{busType}/services", NewHandler(new(handlertest.MockDBusService)).ListServices)
+		}, http.MethodGet, "/buses/session/services?wait=com.example.Test&timeout="+timeout, "")
+		assert.Equal(t, http.StatusBadRequest, rec.Code, timeout)
+	}
+}
+
+func TestHandler_StreamNameOwners(t *testing.T) {
+	events := make(chan *model.NameOwnerEvent, 2)
+	events <- &model.NameOwnerEvent{Bus: "session", Name: "com.example.Test", Change: "appeared", NewOwner: ":1.9"}
+	events <- &model.NameOwnerEvent{Bus: "session", Name: "com.example.Test", Change: "replaced", OldOwner: ":1.9", NewOwner: ":1.12"}
+	close(events)
+
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("WatchNameOwners", mock.Anything, "session", "com.example.*", false).Return(events, nil)
+
+	h := NewHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/buses/session/names/events?pattern=com.example.*", nil)
+	req.SetPathValue("busType", "session")
+	rec := httptest.NewRecorder()
+
+	h.StreamNameOwners(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	assert.Contains(t, body, "event: appeared\ndata: {\"bus\":\"session\",\"name\":\"com.example.Test\",\"change\":\"appeared\",\"new_owner\":\":1.9\"")
+	assert.Contains(t, body, "event: replaced\ndata: ")
+	assert.True(t, strings.HasSuffix(body, "event: close\ndata: {}\n\n"))
+}
+
+func TestHandler_StreamNameOwners_InvalidPattern(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("WatchNameOwners", mock.Anything, "session", "com.[example", true).
+		Return(nil, fmt.Errorf("%w: com.[example", service.ErrInvalidNamePattern))
+
+	h := NewHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/buses/session/names/events?pattern=com.%5Bexample&unique=true", nil)
+	req.SetPathValue("busType", "session")
+	rec := httptest.NewRecorder()
+
+	h.StreamNameOwners(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/go-fuego/fuego"
+	"github.com/godbus/dbus/v5"
+
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// splitObjectRoute splits the object route wildcard OBJECT/PATH/INTERFACE/METHOD.
+// Interface names contain dots, which object path elements cannot.
+func splitObjectRoute(route string) (objectPath, interfaceName, methodName string, err error) {
+	segments := strings.Split(strings.Trim(route, "/"), "/")
+	if len(segments) < 2 || !strings.Contains(segments[len(segments)-2], ".") {
+		return "", "", "", fuego.BadRequestError{
+			Title:  "Invalid object route",
+			Detail: "expected /objects/{object path}/{interface}/{method}",
+			Err:    fmt.Errorf("invalid object route: %s", route),
+		}
+	}
+
+	objectPath = "/" + strings.Join(segments[:len(segments)-2], "/")
+	if !dbus.ObjectPath(objectPath).IsValid() {
+		return "", "", "", fuego.BadRequestError{Title: "Invalid object path", Detail: objectPath, Err: fmt.Errorf("invalid object path: %s", objectPath)}
+	}
+
+	return objectPath, segments[len(segments)-2], segments[len(segments)-1], nil
+}
+
+// CallObjectMethod calls a method with arguments given as a JSON object keyed by the
+// argument names of its introspection data, and names its return values the same way.
+// Unnamed arguments are keyed argN.
+func (h *Handler) CallObjectMethod(c *fuego.ContextWithBody[any]) (any, error) {
+	busType := c.PathParam("busType")
+	serviceName := c.PathParam("serviceName")
+	objectPath, interfaceName, methodName, err := splitObjectRoute(c.PathParam("objectRoute"))
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := c.Body()
+	if err != nil {
+		slog.WarnContext(c.Context(), "Invalid method call body", "error", err)
+		return nil, fuego.BadRequestError{Title: "Invalid request body", Detail: err.Error(), Err: err}
+	}
+	// Body and result are typed any, as fuego cannot name map types in the OpenAPI spec
+	body, ok := decoded.(map[string]any)
+	if !ok {
+		return nil, fuego.BadRequestError{
+			Title:  "Invalid request body",
+			Detail: "expected an object of named arguments",
+			Err:    errors.New("method call body is not an object"),
+		}
+	}
+
+	iface, err := h.dbusService.GetInterfaceInfo(c.Context(), busType, serviceName, objectPath, interfaceName)
+	if err != nil {
+		if errors.Is(err, service.ErrInterfaceNotFound) {
+			return nil, fuego.NotFoundError{Title: "Interface not found", Detail: err.Error(), Err: err}
+		}
+		return nil, err
+	}
+	method := findMethod(iface, methodName)
+	if method == nil {
+		return nil, fuego.NotFoundError{
+			Title:  "Method not found",
+			Detail: fmt.Sprintf("%s.%s on %s", interfaceName, methodName, objectPath),
+			Err:    fmt.Errorf("method %s.%s not found on %s", interfaceName, methodName, objectPath),
+		}
+	}
+
+	end := startConversion(c.Context())
+	args, err := namedArgs(method.InArgs, body)
+	end(err)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(c.Context(), "Calling D-Bus method",
+		"bus", busType, "service", serviceName, "path", objectPath, "interface", interfaceName, "method", methodName, "args", len(args))
+
+	result, err := h.dbusService.CallMethod(c.Context(), busType, serviceName, objectPath, interfaceName, methodName, args)
+	if err != nil {
+		return nil, err
+	}
+	if err := resultError(result); err != nil {
+		return nil, err
+	}
+
+	return namedValues(method.OutArgs, result.ReturnValues), nil
+}
+
+// namedValues keys the return values of a method call by their argument names
+func namedValues(outArgs []model.ArgumentInfo, returnValues []interface{}) map[string]any {
+	values := make(map[string]any, len(returnValues))
+	for i, value := range returnValues {
+		name := fmt.Sprintf("arg%d", i)
+		if i < len(outArgs) {
+			name = service.ArgName(outArgs[i], i)
+		}
+		values[name] = value
+	}
+	return values
+}
+
+// findMethod returns the method of an interface by name
+func findMethod(iface *model.InterfaceInfo, name string) *model.MethodInfo {
+	for i := range iface.Methods {
+		if iface.Methods[i].Name == name {
+			return &iface.Methods[i]
+		}
+	}
+	return nil
+}
+
+// namedArgs converts the JSON object of named arguments to positional D-Bus values
+func namedArgs(inArgs []model.ArgumentInfo, body map[string]any) ([]interface{}, error) {
+	args := make([]interface{}, len(inArgs))
+	problems := make([]fuego.ErrorItem, 0)
+	known := make(map[string]bool, len(inArgs))
+
+	for i, arg := range inArgs {
+		name := service.ArgName(arg, i)
+		known[name] = true
+
+		value, ok := body[name]
+		if !ok {
+			problems = append(problems, fuego.ErrorItem{Name: name, Reason: fmt.Sprintf("missing argument of type %s", arg.Type)})
+			continue
+		}
+
+		parsed, err := service.ParseJSONArg(name, arg.Type, value)
+		if err != nil {
+			var valueErr *service.ValueError
+			if errors.As(err, &valueErr) {
+				problems = append(problems, fuego.ErrorItem{Name: valueErr.Path, Reason: valueErr.Msg})
+				continue
+			}
+			return nil, err
+		}
+		args[i] = parsed
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(body)) {
+		if !known[name] {
+			problems = append(problems, fuego.ErrorItem{Name: name, Reason: "unknown argument"})
+		}
+	}
+
+	if len(problems) > 0 {
+		names := make([]string, len(problems))
+		for i, problem := range problems {
+			names[i] = problem.Name
+		}
+		return nil, fuego.BadRequestError{
+			Title:  "Invalid arguments",
+			Detail: "invalid arguments: " + strings.Join(names, ", "),
+			Err:    errors.New("invalid named arguments"),
+			Errors: problems,
+		}
+	}
+
+	return args, nil
+}
@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/go-fuego/fuego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbrj/dbus-controller/internal/handler/handlertest"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// helloInterface is the introspection data of the example service
+var helloInterface = &model.InterfaceInfo{
+	Name: "com.example.HelloWorld",
+	Methods: []model.MethodInfo{{
+		Name:    "Hello",
+		InArgs:  []model.ArgumentInfo{{Name: "name", Type: "s", Direction: "in"}, {Type: "u", Direction: "in"}},
+		OutArgs: []model.ArgumentInfo{{Name: "greeting", Type: "s", Direction: "out"}, {Type: "b", Direction: "out"}},
+	}},
+}
+
+func TestHandler_CallObjectMethod(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("GetInterfaceInfo", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld").
+		Return(helloInterface, nil)
+	mockService.On("CallMethod", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Hello",
+		[]interface{}{"World", uint32(3)}).
+		Return(&model.MethodCallResult{Success: true, ReturnValues: []interface{}{"Hello, World!", true}}, nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/services/{serviceName}/objects/{objectRoute...}", NewHandler(mockService).CallObjectMethod)
+	}, http.MethodPost, "/buses/session/services/com.example.HelloWorld/objects/com/example/HelloWorld/com.example.HelloWorld/Hello", `{"name": "World", "arg1": 3}`)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"greeting": "Hello, World!", "arg1": true}`, rec.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_CallObjectMethod_InvalidArgs(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("GetInterfaceInfo", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld").
+		Return(helloInterface, nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/services/{serviceName}/objects/{objectRoute...}", NewHandler(mockService).CallObjectMethod)
+	}, http.MethodPost, "/buses/session/services/com.example.HelloWorld/objects/com.example.HelloWorld/Hello", `{"arg1": -1, "extra": 1}`)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "invalid arguments: name, arg1, extra")
+	mockService.AssertNotCalled(t, "CallMethod")
+}
+
+func TestHandler_CallObjectMethod_NotObject(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/services/{serviceName}/objects/{objectRoute...}", NewHandler(mockService).CallObjectMethod)
+	}, http.MethodPost, "/buses/session/services/com.example.HelloWorld/objects/com.example.HelloWorld/Hello", `["World", 1]`)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockService.AssertNotCalled(t, "GetInterfaceInfo")
+}
+
+func TestHandler_CallObjectMethod_NotFound(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("GetInterfaceInfo", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld").
+		Return(helloInterface, nil)
+	mockService.On("GetInterfaceInfo", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.Missing").
+		Return((*model.InterfaceInfo)(nil), service.ErrInterfaceNotFound)
+
+	assert.Equal(t, http.StatusNotFound, serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/services/{serviceName}/objects/{objectRoute...}", NewHandler(mockService).CallObjectMethod)
+	}, http.MethodPost, "/buses/session/services/com.example.HelloWorld/objects/com.example.HelloWorld/Missing", `{}`).Code)
+	assert.Equal(t, http.StatusNotFound, serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/services/{serviceName}/objects/{objectRoute...}", NewHandler(mockService).CallObjectMethod)
+	}, http.MethodPost, "/buses/session/services/com.example.HelloWorld/objects/com.example.Missing/Hello", `{}`).Code)
+}
+
+func TestHandler_CallObjectMethod_DBusError(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("GetInterfaceInfo", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld").
+		Return(helloInterface, nil)
+	mockService.On("CallMethod", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld", "Hello", mock.Anything).
+		Return(&model.MethodCallResult{Success: false, Error: "denied", ErrorName: "org.freedesktop.DBus.Error.AccessDenied"}, nil)
+
+	rec := serveRoute(func(s *fuego.Server) {
+		fuego.Post(s, "/buses/{busType}/services/{serviceName}/objects/{objectRoute...}", NewHandler(mockService).CallObjectMethod)
+	}, http.MethodPost, "/buses/session/services/com.example.HelloWorld/objects/com.example.HelloWorld/Hello", `{"name": "World", "arg1": 1}`)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), "org.freedesktop.DBus.Error.AccessDenied")
+}
+
+func TestSplitObjectRoute(t *testing.T) {
+	objectPath, interfaceName, methodName, err := splitObjectRoute("com/example/HelloWorld/com.example.HelloWorld/Hello")
+	require.NoError(t, err)
+	assert.Equal(t, "/com/example/HelloWorld", objectPath)
+	assert.Equal(t, "com.example.HelloWorld", interfaceName)
+	assert.Equal(t, "Hello", methodName)
+
+	objectPath, _, _, err = splitObjectRoute("com.example.HelloWorld/Hello")
+	require.NoError(t, err)
+	assert.Equal(t, "/", objectPath)
+
+	_, _, _, err = splitObjectRoute("com/example/Hello")
+	assert.Error(t, err)
+
+	_, _, _, err = splitObjectRoute("com/ex-ample/com.example.HelloWorld/Hello")
+	assert.Error(t, err)
+}
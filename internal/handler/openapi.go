@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"log/slog"
+
+	"github.com/go-fuego/fuego"
+
+	"github.com/mesbrj/dbus-controller/internal/openapi"
+	"github.com/mesbrj/dbus-controller/internal/service"
+)
+
+// specMaxObjects bounds the number of objects introspected for a service document
+const specMaxObjects = 512
+
+// ServiceOpenAPI returns an OpenAPI document generated from the introspection data
+// of the objects of a service, walking the object tree from the path query parameter
+func (h *Handler) ServiceOpenAPI(c fuego.ContextNoBody) (any, error) {
+	busType := c.QueryParam("bus")
+	if busType == "" {
+		busType = "system"
+	}
+	serviceName := c.PathParam("serviceName")
+	root, err := queryObjectPath(c)
+	if err != nil {
+		return nil, err
+	}
+
+	objects, err := service.IntrospectTree(c.Context(), h.dbusService, busType, serviceName, root, specMaxObjects)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.InfoContext(c.Context(), "Generated service OpenAPI document",
+		"bus", busType, "service", serviceName, "path", root, "objects", len(objects))
+
+	return openapi.ServiceSpec(busType, serviceName, objects), nil
+}
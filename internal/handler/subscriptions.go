@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-fuego/fuego"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// ListSubscriptions returns the active signal subscriptions
+func (h *Handler) ListSubscriptions(c fuego.ContextNoBody) ([]model.SignalSubscription, error) {
+	return h.dbusService.ListSubscriptions(c.Context())
+}
+
+// Unsubscribe removes a signal subscription and ends its event streams
+func (h *Handler) Unsubscribe(c fuego.ContextNoBody) (any, error) {
+	subscriptionID := c.PathParam("subscriptionId")
+
+	if err := h.dbusService.Unsubscribe(c.Context(), subscriptionID); err != nil {
+		if errors.Is(err, service.ErrSubscriptionNotFound) {
+			return nil, fuego.NotFoundError{Title: "Subscription not found", Detail: subscriptionID, Err: err}
+		}
+		return nil, err
+	}
+
+	c.SetStatus(http.StatusNoContent)
+	return nil, nil
+}
+
+// StreamSubscription streams the signals of a subscription as Server-Sent Events.
+// The stream ends with a "close" event when the subscription is removed or the server shuts down.
+func (h *Handler) StreamSubscription(w http.ResponseWriter, r *http.Request) {
+	subscriptionID := r.PathValue("subscriptionId")
+
+	events, err := h.dbusService.StreamSubscription(r.Context(), subscriptionID)
+	if err != nil {
+		if errors.Is(err, service.ErrSubscriptionNotFound) {
+			err = fuego.NotFoundError{Title: "Subscription not found", Detail: subscriptionID, Err: err}
+		}
+		fuego.SendJSONError(w, r, err)
+		return
+	}
+
+	slog.InfoContext(r.Context(), "Signal stream opened", "subscription", subscriptionID)
+	serveEvents(w, r, events, func(event *model.SignalEvent) (sseEvent, bool) {
+		return sseEvent{name: "signal", data: event}, false
+	}, closeEvent)
+	slog.InfoContext(r.Context(), "Signal stream closed", "subscription", subscriptionID)
+}
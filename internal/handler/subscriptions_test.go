@@ -0,0 +1,85 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-fuego/fuego"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"github.com/mesbrj/dbus-controller/internal/handler/handlertest"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+func TestHandler_Unsubscribe(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("Unsubscribe", mock.Anything, "session:a:b:c").Return(nil)
+
+	h := NewHandler(mockService)
+	req := httptest.NewRequest(http.MethodDelete, "/subscriptions/session:a:b:c", nil)
+	req.SetPathValue("subscriptionId", "session:a:b:c")
+	rec := httptest.NewRecorder()
+
+	_, err := h.Unsubscribe(fuego.ContextNoBody{Req: req, Res: rec})
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestHandler_Unsubscribe_NotFound(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("Unsubscribe", mock.Anything, "missing").
+		Return(fmt.Errorf("%w: missing", service.ErrSubscriptionNotFound))
+
+	h := NewHandler(mockService)
+	req := httptest.NewRequest(http.MethodDelete, "/subscriptions/missing", nil)
+	req.SetPathValue("subscriptionId", "missing")
+
+	_, err := h.Unsubscribe(fuego.ContextNoBody{Req: req, Res: httptest.NewRecorder()})
+
+	var notFound fuego.NotFoundError
+	assert.ErrorAs(t, err, &notFound)
+}
+
+func TestHandler_StreamSubscription(t *testing.T) {
+	events := make(chan *model.SignalEvent, 1)
+	events <- &model.SignalEvent{SubscriptionID: "session:a:b:c", Signal: "c", Body: []interface{}{"x"}}
+	close(events)
+
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("StreamSubscription", mock.Anything, "session:a:b:c").Return(events, nil)
+
+	h := NewHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/subscriptions/session:a:b:c/events", nil)
+	req.SetPathValue("subscriptionId", "session:a:b:c")
+	rec := httptest.NewRecorder()
+
+	h.StreamSubscription(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+	body := rec.Body.String()
+	assert.Contains(t, body, "event: signal\ndata: {\"subscription_id\":\"session:a:b:c\"")
+	assert.True(t, strings.HasSuffix(body, "event: close\ndata: {}\n\n"))
+}
+
+func TestHandler_StreamSubscription_NotFound(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("StreamSubscription", mock.Anything, "missing").
+		Return(nil, fmt.Errorf("%w: missing", service.ErrSubscriptionNotFound))
+
+	h := NewHandler(mockService)
+	req := httptest.NewRequest(http.MethodGet, "/subscriptions/missing/events", nil)
+	req.SetPathValue("subscriptionId", "missing")
+	rec := httptest.NewRecorder()
+
+	h.StreamSubscription(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
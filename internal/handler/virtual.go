@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-fuego/fuego"
+	"github.com/godbus/dbus/v5"
+
+	"github.com/mesbrj/dbus-controller/internal/config"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// templateRef matches the {source.name} references of route templates
+var templateRef = regexp.MustCompile(`\{([a-z]+(?:\.[^{}.]+)*)\}`)
+
+// routeTemplate is a string with references to request or result values
+type routeTemplate struct {
+	text string
+	refs [][]string // Reference paths in order, e.g. [query name]
+	raw  bool       // The template is a single reference, keeping the referenced value
+}
+
+// parseRouteTemplate parses a template, accepting only references to the given sources
+func parseRouteTemplate(text string, sources ...string) (*routeTemplate, error) {
+	t := &routeTemplate{text: text}
+	for _, match := range templateRef.FindAllStringSubmatch(text, -1) {
+		ref := strings.Split(match[1], ".")
+		if !slices.Contains(sources, ref[0]) {
+			return nil, fmt.Errorf("template %q: unknown reference {%s}, expected one of %s", text, match[1], strings.Join(sources, ", "))
+		}
+		if (ref[0] == "path" || ref[0] == "query") && len(ref) != 2 {
+			return nil, fmt.Errorf("template %q: {%s} must name one parameter", text, match[1])
+		}
+		t.refs = append(t.refs, ref)
+	}
+	t.raw = len(t.refs) == 1 && templateRef.FindString(text) == text
+	return t, nil
+}
+
+// evaluate resolves the template against scope. A single reference returns the
+// referenced value; otherwise the references are formatted into the text.
+func (t *routeTemplate) evaluate(scope map[string]any) (any, error) {
+	if t.raw {
+		return lookup(scope, t.refs[0])
+	}
+
+	var err error
+	text := templateRef.ReplaceAllStringFunc(t.text, func(match string) string {
+		value, lookupErr := lookup(scope, strings.Split(match[1:len(match)-1], "."))
+		if lookupErr != nil {
+			err = lookupErr
+			return ""
+		}
+		return fmt.Sprint(value)
+	})
+	return text, err
+}
+
+// lookup follows a reference path through maps, slices and variants
+func lookup(scope map[string]any, ref []string) (any, error) {
+	var value any = scope
+	for i, key := range ref {
+		if variant, ok := value.(dbus.Variant); ok {
+			value = variant.Value()
+		}
+
+		v := reflect.ValueOf(value)
+		switch v.Kind() {
+		case reflect.Map:
+			if v.Type().Key().Kind() != reflect.String {
+				return nil, fmt.Errorf("{%s}: not an object", strings.Join(ref[:i], "."))
+			}
+			elem := v.MapIndex(reflect.ValueOf(key).Convert(v.Type().Key()))
+			if !elem.IsValid() {
+				return nil, fmt.Errorf("{%s} is missing", strings.Join(ref[:i+1], "."))
+			}
+			value = elem.Interface()
+		case reflect.Slice, reflect.Array:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= v.Len() {
+				return nil, fmt.Errorf("{%s} is missing", strings.Join(ref[:i+1], "."))
+			}
+			value = v.Index(index).Interface()
+		default:
+			return nil, fmt.Errorf("{%s} is missing", strings.Join(ref[:i+1], "."))
+		}
+	}
+
+	if variant, ok := value.(dbus.Variant); ok {
+		value = variant.Value()
+	}
+	return value, nil
+}
+
+// VirtualHandler serves a configured route mapped onto a D-Bus operation
+type VirtualHandler struct {
+	dbusService service.DBusServiceInterface
+	route       config.RouteConfig
+	argTypes    []string
+	object      *routeTemplate
+	args        []*routeTemplate
+	response    map[string]*routeTemplate
+	usesBody    bool
+	queryParams []string
+}
+
+// NewVirtualHandler parses the templates of a route
+func NewVirtualHandler(dbusService service.DBusServiceInterface, route config.RouteConfig) (*VirtualHandler, error) {
+	h := &VirtualHandler{
+		dbusService: dbusService,
+		route:       route,
+		response:    make(map[string]*routeTemplate, len(route.Response)),
+	}
+
+	argTypes, err := service.SplitSignature(route.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("route %s %s: signature: %w", route.Method, route.Path, err)
+	}
+	if len(argTypes) != len(route.Args) {
+		return nil, fmt.Errorf("route %s %s: signature %q has %d types for %d args", route.Method, route.Path, route.Signature, len(argTypes), len(route.Args))
+	}
+	h.argTypes = argTypes
+
+	requestSources := []string{"path", "query", "body"}
+	if h.object, err = parseRouteTemplate(route.Object, requestSources...); err != nil {
+		return nil, fmt.Errorf("route %s %s: object: %w", route.Method, route.Path, err)
+	}
+	templates := []*routeTemplate{h.object}
+
+	for _, arg := range route.Args {
+		t, err := parseRouteTemplate(arg, requestSources...)
+		if err != nil {
+			return nil, fmt.Errorf("route %s %s: args: %w", route.Method, route.Path, err)
+		}
+		h.args = append(h.args, t)
+		templates = append(templates, t)
+	}
+
+	for field, value := range route.Response {
+		t, err := parseRouteTemplate(value, append(requestSources, "result")...)
+		if err != nil {
+			return nil, fmt.Errorf("route %s %s: response.%s: %w", route.Method, route.Path, field, err)
+		}
+		h.response[field] = t
+		templates = append(templates, t)
+	}
+
+	for _, t := range templates {
+		for _, ref := range t.refs {
+			switch ref[0] {
+			case "body":
+				h.usesBody = true
+			case "query":
+				if !slices.Contains(h.queryParams, ref[1]) {
+					h.queryParams = append(h.queryParams, ref[1])
+				}
+			}
+		}
+	}
+
+	return h, nil
+}
+
+// QueryParams returns the query parameters referenced by the route templates
+func (h *VirtualHandler) QueryParams() []string {
+	return h.queryParams
+}
+
+// Serve runs the D-Bus operation of the route and shapes its result
+func (h *VirtualHandler) Serve(c *fuego.ContextWithBody[any]) (any, error) {
+	scope, err := h.requestScope(c)
+	if err != nil {
+		return nil, err
+	}
+
+	objectValue, err := h.object.evaluate(scope)
+	if err != nil {
+		return nil, templateError(err)
+	}
+	objectPath := fmt.Sprint(objectValue)
+	if !dbus.ObjectPath(objectPath).IsValid() {
+		return nil, fuego.BadRequestError{Title: "Invalid object path", Detail: objectPath, Err: fmt.Errorf("invalid object path: %s", objectPath)}
+	}
+
+	var result any
+	if h.route.Property != "" {
+		value, err := h.dbusService.GetProperty(c.Context(), h.route.Bus, h.route.Service, objectPath, h.route.Interface, h.route.Property)
+		if err != nil {
+			return nil, err
+		}
+		result = value.Value
+	} else {
+		args := make([]interface{}, len(h.args))
+		for i, t := range h.args {
+			value, err := t.evaluate(scope)
+			if err != nil {
+				return nil, templateError(err)
+			}
+			if args[i], err = service.ParseJSONArg(fmt.Sprintf("args[%d]", i), h.argTypes[i], value); err != nil {
+				return nil, fuego.BadRequestError{Title: "Invalid arguments", Detail: err.Error(), Err: err}
+			}
+		}
+
+		slog.InfoContext(c.Context(), "Calling D-Bus method for route",
+			"route", h.route.Path, "bus", h.route.Bus, "service", h.route.Service, "path", objectPath,
+			"interface", h.route.Interface, "method", h.route.Call)
+
+		callResult, err := h.dbusService.CallMethod(c.Context(), h.route.Bus, h.route.Service, objectPath, h.route.Interface, h.route.Call, args)
+		if err != nil {
+			return nil, err
+		}
+		if err := resultError(callResult); err != nil {
+			return nil, err
+		}
+		result = callResult.ReturnValues
+	}
+
+	if len(h.response) == 0 {
+		return result, nil
+	}
+
+	scope["result"] = result
+	shaped := make(map[string]any, len(h.response))
+	for field, t := range h.response {
+		// Missing values are returned as null
+		value, _ := t.evaluate(scope)
+		shaped[field] = value
+	}
+	return shaped, nil
+}
+
+// requestScope collects the path parameters, query parameters and body of the request
+func (h *VirtualHandler) requestScope(c *fuego.ContextWithBody[any]) (map[string]any, error) {
+	r := c.Request()
+
+	pathParams := make(map[string]any)
+	for _, match := range pathWildcard.FindAllStringSubmatch(h.route.Path, -1) {
+		pathParams[match[1]] = r.PathValue(match[1])
+	}
+
+	queryParams := make(map[string]any)
+	for name, values := range r.URL.Query() {
+		queryParams[name] = values[0]
+	}
+
+	scope := map[string]any{"path": pathParams, "query": queryParams}
+	if h.usesBody {
+		body, err := c.Body()
+		if err != nil {
+			return nil, fuego.BadRequestError{Title: "Invalid request body", Detail: err.Error(), Err: err}
+		}
+		scope["body"] = body
+	}
+	return scope, nil
+}
+
+// pathWildcard matches the wildcards of a route pattern
+var pathWildcard = regexp.MustCompile(`\{([^{}.]+)(?:\.\.\.)?\}`)
+
+// templateError reports a request value missing from a template
+func templateError(err error) error {
+	return fuego.BadRequestError{Title: "Missing request value", Detail: err.Error(), Err: err}
+}
+
+// resultError returns the D-Bus error of a failed method call, reported as an
+// error so the error name selects the status code
+func resultError(result *model.MethodCallResult) error {
+	if result.Success {
+		return nil
+	}
+	return dbus.Error{Name: result.ErrorName, Body: []interface{}{result.Error}}
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-fuego/fuego"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbrj/dbus-controller/internal/config"
+	"github.com/mesbrj/dbus-controller/internal/handler/handlertest"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// helloRoute maps a route onto the Hello method of the example service
+var helloRoute = config.RouteConfig{
+	Method:    http.MethodPost,
+	Path:      "/api/greeter/{first}",
+	Bus:       "session",
+	Service:   "com.example.HelloWorld",
+	Object:    "/com/example/HelloWorld",
+	Interface: "com.example.HelloWorld",
+	Call:      "Hello",
+	Signature: "s",
+	Args:      []string{"{path.first} and {body.second}"},
+}
+
+func serveVirtualRoute(t *testing.T, mockService *handlertest.MockDBusService, route config.RouteConfig, target, body string) *httptest.ResponseRecorder {
+	h, err := NewVirtualHandler(mockService, route)
+	require.NoError(t, err)
+
+	server := fuego.NewServer(fuego.WithErrorHandler(ErrorHandler))
+	switch route.Method {
+	case http.MethodPost:
+		fuego.Post(server, route.Path, h.Serve)
+	default:
+		fuego.Get(server, route.Path, h.Serve)
+	}
+
+	req := httptest.NewRequest(route.Method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	server.Mux.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestVirtualHandler_Call(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethod", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Hello",
+		[]interface{}{"Ann and Bob"}).
+		Return(&model.MethodCallResult{Success: true, ReturnValues: []interface{}{"Hello, Ann and Bob!"}}, nil)
+
+	rec := serveVirtualRoute(t, mockService, helloRoute, "/api/greeter/Ann", `{"second": "Bob"}`)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `["Hello, Ann and Bob!"]`, rec.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestVirtualHandler_BooleanQuery(t *testing.T) {
+	route := config.RouteConfig{
+		Method:    http.MethodGet,
+		Path:      "/api/greeter/{name}",
+		Bus:       "session",
+		Service:   "com.example.HelloWorld",
+		Object:    "/com/example/HelloWorld",
+		Interface: "com.example.HelloWorld",
+		Call:      "Greet",
+		Signature: "sb",
+		Args:      []string{"{path.name}", "{query.loud}"},
+	}
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethod", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Greet",
+		[]interface{}{"Ann", true}).
+		Return(&model.MethodCallResult{Success: true, ReturnValues: []interface{}{"HELLO, ANN!"}}, nil)
+
+	rec := serveVirtualRoute(t, mockService, route, "/api/greeter/Ann?loud=true", "")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `["HELLO, ANN!"]`, rec.Body.String())
+	mockService.AssertExpectations(t)
+
+	rec = serveVirtualRoute(t, new(handlertest.MockDBusService), route, "/api/greeter/Ann?loud=maybe", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestVirtualHandler_ResponseShaping(t *testing.T) {
+	route := config.RouteConfig{
+		Method:    http.MethodGet,
+		Path:      "/api/greeter/hello",
+		Bus:       "session",
+		Service:   "com.example.HelloWorld",
+		Object:    "/",
+		Interface: "com.example.HelloWorld",
+		Call:      "Hello",
+		Signature: "su",
+		Args:      []string{"{query.name}", "{query.count}"},
+		Response:  map[string]string{"greeting": "{result.0}", "who": "{query.name}", "extra": "{result.1}"},
+	}
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethod", mock.Anything, "session", "com.example.HelloWorld", "/", "com.example.HelloWorld", "Hello",
+		[]interface{}{"x", uint32(2)}).
+		Return(&model.MethodCallResult{Success: true, ReturnValues: []interface{}{"Hello, x!"}}, nil)
+
+	rec := serveVirtualRoute(t, mockService, route, "/api/greeter/hello?name=x&count=2", "")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"greeting": "Hello, x!", "who": "x", "extra": null}`, rec.Body.String())
+}
+
+func TestVirtualHandler_Property(t *testing.T) {
+	route := config.RouteConfig{
+		Method:    http.MethodGet,
+		Path:      "/api/greeter/{object...}",
+		Bus:       "session",
+		Service:   "com.example.HelloWorld",
+		Object:    "/{path.object}",
+		Interface: "com.example.HelloWorld",
+		Property:  "PID",
+		Response:  map[string]string{"pid": "{result}"},
+	}
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("GetProperty", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "PID").
+		Return(&model.PropertyValue{Name: "PID", Value: int32(42)}, nil)
+
+	rec := serveVirtualRoute(t, mockService, route, "/api/greeter/com/example/HelloWorld", "")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"pid": 42}`, rec.Body.String())
+}
+
+func TestVirtualHandler_MissingValue(t *testing.T) {
+	route := helloRoute
+	route.Method = http.MethodGet
+	route.Path = "/api/greeter/hello"
+	route.Args = []string{"{query.name}"}
+	mockService := new(handlertest.MockDBusService)
+
+	rec := serveVirtualRoute(t, mockService, route, "/api/greeter/hello", "")
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "{query.name} is missing")
+	mockService.AssertNotCalled(t, "CallMethod")
+}
+
+func TestVirtualHandler_DBusError(t *testing.T) {
+	mockService := new(handlertest.MockDBusService)
+	mockService.On("CallMethod", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Hello", mock.Anything).
+		Return(&model.MethodCallResult{Success: false, Error: "denied", ErrorName: "org.freedesktop.DBus.Error.AccessDenied"}, nil)
+
+	rec := serveVirtualRoute(t, mockService, helloRoute, "/api/greeter/Ann", `{"second": "Bob"}`)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
+
+func TestNewVirtualHandler_Errors(t *testing.T) {
+	tests := map[string]func(*config.RouteConfig){
+		"args count":       func(r *config.RouteConfig) { r.Signature = "ss" },
+		"invalid type":     func(r *config.RouteConfig) { r.Signature = "a" },
+		"unknown source":   func(r *config.RouteConfig) { r.Args = []string{"{header.name}"} },
+		"result in args":   func(r *config.RouteConfig) { r.Args = []string{"{result}"} },
+		"nested query ref": func(r *config.RouteConfig) { r.Object = "/{query.a.b}" },
+	}
+
+	for name, modify := range tests {
+		t.Run(name, func(t *testing.T) {
+			route := helloRoute
+			modify(&route)
+
+			_, err := NewVirtualHandler(new(handlertest.MockDBusService), route)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRouteTemplate(t *testing.T) {
+	scope := map[string]any{
+		"query":  map[string]any{"name": "x"},
+		"result": []interface{}{map[string]dbus.Variant{"count": dbus.MakeVariant(uint32(3))}},
+	}
+
+	raw, err := parseRouteTemplate("{result.0.count}", "query", "result")
+	require.NoError(t, err)
+	value, err := raw.evaluate(scope)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(3), value)
+
+	text, err := parseRouteTemplate("{query.name} has {result.0.count}", "query", "result")
+	require.NoError(t, err)
+	value, err = text.evaluate(scope)
+	require.NoError(t, err)
+	assert.Equal(t, "x has 3", value)
+
+	_, err = text.evaluate(map[string]any{"query": map[string]any{}})
+	assert.Error(t, err)
+}
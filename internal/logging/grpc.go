@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDMetadata is the gRPC metadata key carrying the request ID
+var requestIDMetadata = strings.ToLower(RequestIDHeader)
+
+// UnaryServerInterceptor propagates the x-request-id metadata of the call, or
+// generates one, and logs the outcome of every unary gRPC call
+func UnaryServerInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx = grpcRequestID(ctx)
+	start := time.Now()
+
+	resp, err := handler(ctx, req)
+
+	logCall(ctx, info.FullMethod, err, start)
+	return resp, err
+}
+
+// StreamServerInterceptor propagates the request ID like UnaryServerInterceptor
+// and logs the outcome of every streaming gRPC call once it ends
+func StreamServerInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx := grpcRequestID(stream.Context())
+	start := time.Now()
+
+	err := handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+
+	logCall(ctx, info.FullMethod, err, start)
+	return err
+}
+
+// grpcRequestID returns ctx carrying the request ID of the call, sent back in the response header
+func grpcRequestID(ctx context.Context) context.Context {
+	var id string
+	if values := metadata.ValueFromIncomingContext(ctx, requestIDMetadata); len(values) > 0 {
+		id = values[0]
+	}
+	if !validRequestID(id) {
+		id = newRequestID()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, id))
+	return WithRequestID(ctx, id)
+}
+
+func logCall(ctx context.Context, method string, err error, start time.Time) {
+	slog.InfoContext(ctx, "gRPC call",
+		slog.String("method", method),
+		slog.String("code", status.Code(err).String()),
+		slog.Duration("duration", time.Since(start)),
+	)
+}
+
+// contextStream overrides the context of a server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
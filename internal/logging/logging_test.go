@@ -0,0 +1,100 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/mesbrj/dbus-controller/internal/config"
+)
+
+func TestNew_JSONWithRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(config.LogConfig{Level: "debug", Format: "json"}, &buf)
+
+	logger.DebugContext(WithRequestID(context.Background(), "req-1"), "D-Bus call", "method", "org.freedesktop.DBus.ListNames")
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "D-Bus call", record["msg"])
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, "org.freedesktop.DBus.ListNames", record["method"])
+}
+
+func TestNew_Level(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(config.LogConfig{Level: "warn", Format: "text"}, &buf)
+
+	logger.Info("hidden")
+	logger.Warn("shown")
+
+	assert.NotContains(t, buf.String(), "hidden")
+	assert.Contains(t, buf.String(), "shown")
+}
+
+func TestMiddleware_PropagatesRequestID(t *testing.T) {
+	var seen string
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/buses", nil)
+	req.Header.Set(RequestIDHeader, "client-id-42")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, "client-id-42", seen)
+	assert.Equal(t, "client-id-42", rec.Header().Get(RequestIDHeader))
+	assert.Equal(t, http.StatusTeapot, rec.Code)
+}
+
+func TestMiddleware_GeneratesRequestID(t *testing.T) {
+	var seen string
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestID(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/buses", nil)
+	req.Header.Set(RequestIDHeader, "invalid id\n")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	assert.Len(t, seen, 32)
+	assert.Equal(t, seen, rec.Header().Get(RequestIDHeader))
+}
+
+func TestContextHandler_WithAttrs(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(config.LogConfig{Level: "info", Format: "text"}, &buf).With(slog.String("component", "metrics"))
+
+	logger.InfoContext(WithRequestID(context.Background(), "req-2"), "poll")
+
+	assert.Contains(t, buf.String(), "component=metrics")
+	assert.Contains(t, buf.String(), "request_id=req-2")
+}
+
+func TestUnaryServerInterceptor_PropagatesRequestID(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-request-id", "client-id-42"))
+
+	var seen string
+	_, err := UnaryServerInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/dbuscontroller.v1.DBusService/ListServices"},
+		func(ctx context.Context, req any) (any, error) {
+			seen = RequestID(ctx)
+			return nil, nil
+		})
+
+	require.NoError(t, err)
+	assert.Equal(t, "client-id-42", seen)
+}
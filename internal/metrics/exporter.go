@@ -0,0 +1,383 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/mesbrj/dbus-controller/internal/config"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+const propertiesInterface = "org.freedesktop.DBus.Properties"
+
+// Source provides the D-Bus operations needed by the exporter
+type Source interface {
+	ListServices(ctx context.Context, busType string) ([]string, error)
+	GetNameOwner(ctx context.Context, busType, name string) (string, error)
+	IntrospectObject(ctx context.Context, busType, serviceName, objectPath string) (*model.IntrospectionResult, error)
+	GetAllProperties(ctx context.Context, busType, serviceName, objectPath, interfaceName string) (map[string]interface{}, error)
+	WatchSignals(ctx context.Context, busType string, match service.SignalMatch, buffer int) (*service.SignalWatch, error)
+}
+
+// seriesKey identifies one exported property gauge
+type seriesKey struct {
+	bus      string
+	service  string
+	path     string
+	iface    string
+	property string
+}
+
+// Exporter exports numeric and boolean D-Bus property values as Prometheus gauges
+type Exporter struct {
+	source   Source
+	config   config.MetricsConfig
+	registry *prometheus.Registry
+
+	valueDesc  *prometheus.Desc
+	pollErrors prometheus.Counter
+	lastPoll   prometheus.Gauge
+
+	mu     sync.RWMutex
+	values map[seriesKey]float64
+	owners map[string]map[string][]string // bus -> unique name -> well-known names
+}
+
+// NewExporter creates a new exporter for the configured targets
+func NewExporter(source Source, cfg config.MetricsConfig) *Exporter {
+	e := &Exporter{
+		source:   source,
+		config:   cfg,
+		registry: prometheus.NewRegistry(),
+		valueDesc: prometheus.NewDesc(
+			"dbus_property_value",
+			"Value of a numeric or boolean D-Bus property.",
+			[]string{"bus", "service", "path", "interface", "property"},
+			nil,
+		),
+		pollErrors: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "dbus_exporter_poll_errors_total",
+			Help: "Number of D-Bus errors while polling property targets.",
+		}),
+		lastPoll: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "dbus_exporter_last_poll_timestamp_seconds",
+			Help: "Unix time of the last completed property poll.",
+		}),
+		values: make(map[seriesKey]float64),
+		owners: make(map[string]map[string][]string),
+	}
+
+	e.registry.MustRegister(e, e.pollErrors, e.lastPoll)
+
+	return e
+}
+
+// Describe implements prometheus.Collector
+func (e *Exporter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- e.valueDesc
+}
+
+// Collect implements prometheus.Collector
+func (e *Exporter) Collect(ch chan<- prometheus.Metric) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	for key, value := range e.values {
+		ch <- prometheus.MustNewConstMetric(e.valueDesc, prometheus.GaugeValue, value,
+			key.bus, key.service, key.path, key.iface, key.property)
+	}
+}
+
+// Handler returns the HTTP handler serving the metrics endpoint
+func (e *Exporter) Handler() http.Handler {
+	return promhttp.HandlerFor(e.registry, promhttp.HandlerOpts{})
+}
+
+// Run polls the targets at the configured interval until ctx is done
+func (e *Exporter) Run(ctx context.Context) {
+	e.Poll(ctx)
+
+	for _, target := range e.config.Targets {
+		if target.Watch {
+			e.watch(ctx, target)
+		}
+	}
+
+	ticker := time.NewTicker(e.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.Poll(ctx)
+		}
+	}
+}
+
+// Poll reads every target once and replaces the exported values
+func (e *Exporter) Poll(ctx context.Context) {
+	values := make(map[seriesKey]float64)
+	owners := make(map[string]map[string][]string)
+
+	for _, target := range e.config.Targets {
+		services, err := e.resolveServices(ctx, target)
+		if err != nil {
+			e.pollError(ctx, err)
+			continue
+		}
+
+		for _, serviceName := range services {
+			if owner, err := e.source.GetNameOwner(ctx, target.Bus, serviceName); err == nil {
+				if owners[target.Bus] == nil {
+					owners[target.Bus] = make(map[string][]string)
+				}
+				owners[target.Bus][owner] = append(owners[target.Bus][owner], serviceName)
+			}
+
+			for _, object := range e.resolvePaths(ctx, target, serviceName) {
+				objectPath := object.path
+				for _, iface := range e.resolveInterfaces(ctx, target, serviceName, object) {
+					properties, err := e.source.GetAllProperties(ctx, target.Bus, serviceName, objectPath, iface)
+					if err != nil {
+						e.pollError(ctx, err)
+						continue
+					}
+					for name, value := range properties {
+						if !matchGlob(target.Property, name) {
+							continue
+						}
+						if number, ok := toFloat(value); ok {
+							values[seriesKey{target.Bus, serviceName, objectPath, iface, name}] = number
+						}
+					}
+				}
+			}
+		}
+	}
+
+	e.mu.Lock()
+	e.values = values
+	e.owners = owners
+	e.mu.Unlock()
+
+	e.lastPoll.SetToCurrentTime()
+}
+
+// resolveServices returns the service names selected by the target
+func (e *Exporter) resolveServices(ctx context.Context, target config.MetricsTarget) ([]string, error) {
+	if !hasMeta(target.Service) {
+		return []string{target.Service}, nil
+	}
+
+	names, err := e.source.ListServices(ctx, target.Bus)
+	if err != nil {
+		return nil, err
+	}
+
+	services := make([]string, 0)
+	for _, name := range names {
+		if matchGlob(target.Service, name) {
+			services = append(services, name)
+		}
+	}
+
+	return services, nil
+}
+
+// resolvedObject is an object selected by a target, with the result of its
+// introspection when it was found by walking the object tree
+type resolvedObject struct {
+	path          string
+	introspection *model.IntrospectionResult
+	err           error
+	introspected  bool
+}
+
+// resolvePaths returns the objects selected by the target, walking the object
+// tree of the service when the path is a glob
+func (e *Exporter) resolvePaths(ctx context.Context, target config.MetricsTarget, serviceName string) []resolvedObject {
+	if !hasMeta(target.Path) {
+		return []resolvedObject{{path: target.Path}}
+	}
+
+	objects := make([]resolvedObject, 0)
+	queue := []string{"/"}
+
+	for depth := 0; len(queue) > 0 && depth <= e.config.MaxDepth; depth++ {
+		next := make([]string, 0)
+		for _, objectPath := range queue {
+			result, err := e.source.IntrospectObject(ctx, target.Bus, serviceName, objectPath)
+			if matchGlob(target.Path, objectPath) {
+				objects = append(objects, resolvedObject{path: objectPath, introspection: result, err: err, introspected: true})
+			}
+			if err != nil || result.ParsedData == nil {
+				continue
+			}
+			for _, node := range result.ParsedData.Nodes {
+				next = append(next, node.Path)
+			}
+		}
+		queue = next
+	}
+
+	return objects
+}
+
+// resolveInterfaces returns the interfaces of an object selected by the
+// target, introspecting it unless the walk of the object tree already did
+func (e *Exporter) resolveInterfaces(ctx context.Context, target config.MetricsTarget, serviceName string, object resolvedObject) []string {
+	if !hasMeta(target.Interface) {
+		return []string{target.Interface}
+	}
+
+	result, err := object.introspection, object.err
+	if !object.introspected {
+		result, err = e.source.IntrospectObject(ctx, target.Bus, serviceName, object.path)
+	}
+	if err != nil {
+		e.pollError(ctx, err)
+		return nil
+	}
+	if result.ParsedData == nil {
+		return nil
+	}
+
+	interfaces := make([]string, 0)
+	for _, iface := range result.ParsedData.Interfaces {
+		if matchGlob(target.Interface, iface.Name) {
+			interfaces = append(interfaces, iface.Name)
+		}
+	}
+
+	return interfaces
+}
+
+// watch updates the exported values from PropertiesChanged signals of the target
+func (e *Exporter) watch(ctx context.Context, target config.MetricsTarget) {
+	match := service.SignalMatch{
+		Interface: propertiesInterface,
+		Member:    "PropertiesChanged",
+	}
+	if !hasMeta(target.Service) {
+		match.Sender = target.Service
+	}
+	if !hasMeta(target.Path) {
+		match.Path = target.Path
+	}
+
+	watch, err := e.source.WatchSignals(ctx, target.Bus, match, 64)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to watch property changes", "bus", target.Bus, "service", target.Service, "error", err)
+		return
+	}
+
+	go func() {
+		<-ctx.Done()
+		watch.Close()
+	}()
+
+	go func() {
+		for sig := range watch.C {
+			e.applyPropertiesChanged(target, sig)
+		}
+	}()
+}
+
+// applyPropertiesChanged updates the series of the services owned by the signal sender
+func (e *Exporter) applyPropertiesChanged(target config.MetricsTarget, sig *dbus.Signal) {
+	if len(sig.Body) < 2 {
+		return
+	}
+	iface, ok := sig.Body[0].(string)
+	if !ok || !matchGlob(target.Interface, iface) {
+		return
+	}
+	changed, ok := sig.Body[1].(map[string]dbus.Variant)
+	if !ok {
+		return
+	}
+
+	objectPath := string(sig.Path)
+	if !matchGlob(target.Path, objectPath) {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, serviceName := range e.owners[target.Bus][sig.Sender] {
+		if !matchGlob(target.Service, serviceName) {
+			continue
+		}
+		for name, variant := range changed {
+			if !matchGlob(target.Property, name) {
+				continue
+			}
+			if number, ok := toFloat(variant.Value()); ok {
+				e.values[seriesKey{target.Bus, serviceName, objectPath, iface, name}] = number
+			}
+		}
+	}
+}
+
+func (e *Exporter) pollError(ctx context.Context, err error) {
+	e.pollErrors.Inc()
+	slog.WarnContext(ctx, "Failed to poll metrics target", "error", err)
+}
+
+// toFloat converts numeric and boolean D-Bus values to a gauge value
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	case byte:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case dbus.Variant:
+		return toFloat(v.Value())
+	default:
+		return 0, false
+	}
+}
+
+// hasMeta reports whether pattern contains glob metacharacters
+func hasMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[\`)
+}
+
+// matchGlob reports whether name matches the glob pattern
+func matchGlob(pattern, name string) bool {
+	matched, err := path.Match(pattern, name)
+	return err == nil && matched
+}
+
+// Ensure DBusService provides the exporter operations
+var _ Source = (*service.DBusService)(nil)
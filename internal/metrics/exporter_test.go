@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mesbrj/dbus-controller/internal/config"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// fakeSource serves a fixed object tree
+type fakeSource struct {
+	services   []string
+	nodes      map[string][]string
+	interfaces map[string][]string
+	properties map[string]map[string]interface{}
+
+	introspected []string // Paths introspected, in order
+}
+
+func (f *fakeSource) ListServices(ctx context.Context, busType string) ([]string, error) {
+	return f.services, nil
+}
+
+func (f *fakeSource) GetNameOwner(ctx context.Context, busType, name string) (string, error) {
+	return ":1." + name, nil
+}
+
+func (f *fakeSource) IntrospectObject(ctx context.Context, busType, serviceName, objectPath string) (*model.IntrospectionResult, error) {
+	f.introspected = append(f.introspected, objectPath)
+	parsed := &model.ParsedIntrospection{}
+	for _, child := range f.nodes[objectPath] {
+		parsed.Nodes = append(parsed.Nodes, model.NodeInfo{Name: child, Path: path.Join(objectPath, child)})
+	}
+	for _, iface := range f.interfaces[objectPath] {
+		parsed.Interfaces = append(parsed.Interfaces, model.InterfaceInfo{Name: iface})
+	}
+	return &model.IntrospectionResult{Service: serviceName, ObjectPath: objectPath, ParsedData: parsed}, nil
+}
+
+func (f *fakeSource) GetAllProperties(ctx context.Context, busType, serviceName, objectPath, interfaceName string) (map[string]interface{}, error) {
+	properties, ok := f.properties[objectPath+":"+interfaceName]
+	if !ok {
+		return nil, fmt.Errorf("unknown interface %s", interfaceName)
+	}
+	return properties, nil
+}
+
+func (f *fakeSource) WatchSignals(ctx context.Context, busType string, match service.SignalMatch, buffer int) (*service.SignalWatch, error) {
+	return nil, fmt.Errorf("not supported")
+}
+
+func newFakeSource() *fakeSource {
+	return &fakeSource{
+		services: []string{"org.freedesktop.DBus", "com.example.Power", ":1.5"},
+		nodes: map[string][]string{
+			"/":            {"com"},
+			"/com":         {"example"},
+			"/com/example": {"BAT0", "BAT1"},
+		},
+		interfaces: map[string][]string{
+			"/com/example/BAT0": {"com.example.Battery", "org.freedesktop.DBus.Properties"},
+			"/com/example/BAT1": {"com.example.Battery"},
+		},
+		properties: map[string]map[string]interface{}{
+			"/com/example/BAT0:com.example.Battery": {"Percentage": float64(87.5), "Charging": true, "Model": "X1"},
+			"/com/example/BAT1:com.example.Battery": {"Percentage": uint32(40), "Charging": false},
+		},
+	}
+}
+
+func TestExporter_PollGlobTargets(t *testing.T) {
+	source := newFakeSource()
+	exporter := NewExporter(source, config.MetricsConfig{
+		MaxDepth: 8,
+		Targets: []config.MetricsTarget{{
+			Bus:       "session",
+			Service:   "com.example.*",
+			Path:      "/com/example/*",
+			Interface: "com.example.*",
+			Property:  "*",
+		}},
+	})
+
+	exporter.Poll(context.Background())
+
+	assert.Len(t, exporter.values, 4)
+	assert.Equal(t, 87.5, exporter.values[seriesKey{"session", "com.example.Power", "/com/example/BAT0", "com.example.Battery", "Percentage"}])
+	assert.Equal(t, 1.0, exporter.values[seriesKey{"session", "com.example.Power", "/com/example/BAT0", "com.example.Battery", "Charging"}])
+	assert.Equal(t, 40.0, exporter.values[seriesKey{"session", "com.example.Power", "/com/example/BAT1", "com.example.Battery", "Percentage"}])
+	assert.Equal(t, []string{"com.example.Power"}, exporter.owners["session"][":1.com.example.Power"])
+
+	// The interfaces are taken from the walk of the object tree
+	assert.Equal(t, []string{"/", "/com", "/com/example", "/com/example/BAT0", "/com/example/BAT1"}, source.introspected)
+}
+
+func TestExporter_PropertyFilterAndHandler(t *testing.T) {
+	exporter := NewExporter(newFakeSource(), config.MetricsConfig{
+		Targets: []config.MetricsTarget{{
+			Bus:       "session",
+			Service:   "com.example.Power",
+			Path:      "/com/example/BAT1",
+			Interface: "com.example.Battery",
+			Property:  "Perc*",
+		}},
+	})
+
+	exporter.Poll(context.Background())
+
+	rec := httptest.NewRecorder()
+	exporter.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(),
+		`dbus_property_value{bus="session",interface="com.example.Battery",path="/com/example/BAT1",property="Percentage",service="com.example.Power"} 40`)
+	assert.NotContains(t, rec.Body.String(), `property="Charging"`)
+}
+
+func TestExporter_ApplyPropertiesChanged(t *testing.T) {
+	target := config.MetricsTarget{
+		Bus:       "session",
+		Service:   "com.example.*",
+		Path:      "/com/example/*",
+		Interface: "com.example.Battery",
+		Property:  "*",
+	}
+	exporter := NewExporter(newFakeSource(), config.MetricsConfig{MaxDepth: 8, Targets: []config.MetricsTarget{target}})
+	exporter.Poll(context.Background())
+
+	exporter.applyPropertiesChanged(target, &dbus.Signal{
+		Sender: ":1.com.example.Power",
+		Path:   "/com/example/BAT1",
+		Name:   "org.freedesktop.DBus.Properties.PropertiesChanged",
+		Body: []interface{}{
+			"com.example.Battery",
+			map[string]dbus.Variant{"Percentage": dbus.MakeVariant(uint32(41)), "Model": dbus.MakeVariant("X2")},
+			[]string{},
+		},
+	})
+
+	assert.Equal(t, 41.0, exporter.values[seriesKey{"session", "com.example.Power", "/com/example/BAT1", "com.example.Battery", "Percentage"}])
+	assert.Len(t, exporter.values, 4)
+}
+
+func TestToFloat(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected float64
+		ok       bool
+	}{
+		{true, 1, true},
+		{false, 0, true},
+		{byte(7), 7, true},
+		{int16(-3), -3, true},
+		{uint64(12), 12, true},
+		{float64(1.5), 1.5, true},
+		{dbus.MakeVariant(int32(9)), 9, true},
+		{"text", 0, false},
+		{[]int32{1}, 0, false},
+	}
+
+	for _, tt := range tests {
+		value, ok := toFloat(tt.value)
+		assert.Equal(t, tt.ok, ok, "%v", tt.value)
+		assert.Equal(t, tt.expected, value, "%v", tt.value)
+	}
+}
@@ -0,0 +1,345 @@
+// Package openapi generates OpenAPI documents describing the D-Bus interfaces of a service
+// from its introspection data, with schemas following the D-Bus type signatures.
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// genericInterfaces are implemented by most objects and covered by the generic routes
+var genericInterfaces = map[string]bool{
+	"org.freedesktop.DBus.Introspectable": true,
+	"org.freedesktop.DBus.Peer":           true,
+	"org.freedesktop.DBus.Properties":     true,
+}
+
+// errorSchemaName names the shared schema of error responses
+const errorSchemaName = "HTTPError"
+
+// objectInterface is an interface together with the objects implementing it
+type objectInterface struct {
+	info  model.InterfaceInfo
+	paths []string
+}
+
+// ServiceSpec returns an OpenAPI document with one operation per method and
+// property of the interfaces implemented by the introspected objects.
+// Interfaces shared by several objects are documented once; the path query
+// parameter lists the objects implementing them.
+func ServiceSpec(busType, serviceName string, objects []*model.IntrospectionResult) *openapi3.T {
+	spec := &openapi3.T{
+		OpenAPI: "3.1.0",
+		Info: &openapi3.Info{
+			Title:       serviceName,
+			Description: fmt.Sprintf("D-Bus interfaces of %s on the %s bus, generated from introspection data", serviceName, busType),
+			Version:     "1.0.0",
+		},
+		Paths: openapi3.NewPaths(),
+		Components: &openapi3.Components{
+			Schemas: openapi3.Schemas{errorSchemaName: openapi3.NewSchemaRef("", errorSchema())},
+		},
+	}
+
+	for _, iface := range collectInterfaces(objects) {
+		spec.Tags = append(spec.Tags, &openapi3.Tag{Name: iface.info.Name})
+		spec.Components.Schemas[iface.info.Name] = openapi3.NewSchemaRef("", interfaceSchema(iface.info))
+
+		base := fmt.Sprintf("/buses/%s/services/%s/interfaces/%s", busType, serviceName, iface.info.Name)
+		pathParam := objectPathParameter(iface.paths)
+
+		for _, method := range iface.info.Methods {
+			spec.Paths.Set(base+"/methods/"+method.Name+"/call", &openapi3.PathItem{
+				Post: methodOperation(iface.info.Name, method, pathParam),
+			})
+		}
+
+		for _, property := range iface.info.Properties {
+			item := &openapi3.PathItem{}
+			if strings.Contains(property.Access, "read") {
+				item.Get = propertyOperation(iface.info.Name, property, pathParam, http.MethodGet)
+			}
+			if strings.Contains(property.Access, "write") {
+				item.Put = propertyOperation(iface.info.Name, property, pathParam, http.MethodPut)
+			}
+			spec.Paths.Set(base+"/properties/"+property.Name, item)
+		}
+	}
+
+	return spec
+}
+
+// collectInterfaces returns the specific interfaces of the objects in order of appearance
+func collectInterfaces(objects []*model.IntrospectionResult) []*objectInterface {
+	interfaces := make([]*objectInterface, 0)
+	byName := make(map[string]*objectInterface)
+
+	for _, object := range objects {
+		if object.ParsedData == nil {
+			continue
+		}
+		for _, info := range object.ParsedData.Interfaces {
+			if genericInterfaces[info.Name] {
+				continue
+			}
+			iface, ok := byName[info.Name]
+			if !ok {
+				iface = &objectInterface{info: info}
+				byName[info.Name] = iface
+				interfaces = append(interfaces, iface)
+			}
+			iface.paths = append(iface.paths, object.ObjectPath)
+		}
+	}
+
+	return interfaces
+}
+
+// objectPathParameter declares the path query parameter selecting one of the objects
+func objectPathParameter(paths []string) *openapi3.ParameterRef {
+	enum := make([]any, len(paths))
+	for i, objectPath := range paths {
+		enum[i] = objectPath
+	}
+
+	schema := openapi3.NewStringSchema()
+	schema.Enum = enum
+	schema.Default = paths[0]
+
+	param := openapi3.NewQueryParameter("path").
+		WithDescription("D-Bus object path").
+		WithSchema(schema)
+	return &openapi3.ParameterRef{Value: param}
+}
+
+// methodOperation documents the call route of a method
+func methodOperation(interfaceName string, method model.MethodInfo, pathParam *openapi3.ParameterRef) *openapi3.Operation {
+	body := openapi3.NewObjectSchema().
+		WithProperty("args", argsSchema(method.InArgs, false))
+	if len(method.InArgs) > 0 {
+		body.Required = []string{"args"}
+	}
+
+	result := openapi3.NewObjectSchema().
+		WithProperty("success", openapi3.NewBoolSchema()).
+		WithProperty("return_values", argsSchema(method.OutArgs, true)).
+		WithProperty("error", openapi3.NewStringSchema()).
+		WithProperty("error_name", openapi3.NewStringSchema()).
+		WithProperty("timestamp", openapi3.NewDateTimeSchema())
+	result.Required = []string{"success", "timestamp"}
+
+	return &openapi3.Operation{
+		OperationID: operationID(interfaceName, method.Name),
+		Tags:        []string{interfaceName},
+		Summary:     fmt.Sprintf("Call %s.%s", interfaceName, method.Name),
+		Description: fmt.Sprintf("D-Bus signature: (%s) → (%s)", argSignature(method.InArgs), argSignature(method.OutArgs)),
+		Deprecated:  method.Annotations["org.freedesktop.DBus.Deprecated"] == "true",
+		Parameters:  openapi3.Parameters{pathParam},
+		RequestBody: &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchema(body)},
+		Responses:   responses(result),
+	}
+}
+
+// propertyOperation documents the get or set route of a property
+func propertyOperation(interfaceName string, property model.PropertyInfo, pathParam *openapi3.ParameterRef, method string) *openapi3.Operation {
+	value := openapi3.NewObjectSchema().
+		WithProperty("name", openapi3.NewStringSchema()).
+		WithProperty("type", openapi3.NewStringSchema()).
+		WithPropertyRef("value", signatureSchema(property.Type, true)).
+		WithProperty("timestamp", openapi3.NewDateTimeSchema())
+	value.Required = []string{"name", "type", "value"}
+
+	op := &openapi3.Operation{
+		OperationID: operationID(interfaceName, "Get"+property.Name),
+		Tags:        []string{interfaceName},
+		Summary:     fmt.Sprintf("Get %s.%s", interfaceName, property.Name),
+		Description: fmt.Sprintf("D-Bus type: %s", property.Type),
+		Deprecated:  property.Annotations["org.freedesktop.DBus.Deprecated"] == "true",
+		Parameters:  openapi3.Parameters{pathParam},
+		Responses:   responses(value),
+	}
+
+	if method == http.MethodPut {
+		body := openapi3.NewObjectSchema().WithPropertyRef("value", signatureSchema(property.Type, false))
+		body.Required = []string{"value"}
+
+		op.OperationID = operationID(interfaceName, "Set"+property.Name)
+		op.Summary = fmt.Sprintf("Set %s.%s", interfaceName, property.Name)
+		op.RequestBody = &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().WithRequired(true).WithJSONSchema(body)}
+	}
+
+	return op
+}
+
+// responses returns the success response and the shared error response
+func responses(success *openapi3.Schema) *openapi3.Responses {
+	errorContent := openapi3.NewContent()
+	errorContent["application/problem+json"] = openapi3.NewMediaType().
+		WithSchemaRef(openapi3.NewSchemaRef("#/components/schemas/"+errorSchemaName, nil))
+
+	return openapi3.NewResponses(
+		openapi3.WithStatus(http.StatusOK, &openapi3.ResponseRef{Value: openapi3.NewResponse().
+			WithDescription("OK").
+			WithJSONSchema(success)}),
+		openapi3.WithName("default", openapi3.NewResponse().
+			WithDescription("Error").
+			WithContent(errorContent)),
+	)
+}
+
+// interfaceSchema describes the properties of an interface as an object
+func interfaceSchema(iface model.InterfaceInfo) *openapi3.Schema {
+	schema := openapi3.NewObjectSchema()
+	schema.Title = iface.Name
+	for _, property := range iface.Properties {
+		propertySchema := signatureSchema(property.Type, true).Value
+		propertySchema.ReadOnly = property.Access == "read"
+		propertySchema.WriteOnly = property.Access == "write"
+		schema.WithProperty(property.Name, propertySchema)
+	}
+	return schema
+}
+
+// errorSchema describes the problem details of error responses
+func errorSchema() *openapi3.Schema {
+	item := openapi3.NewObjectSchema().
+		WithProperty("name", openapi3.NewStringSchema()).
+		WithProperty("reason", openapi3.NewStringSchema()).
+		WithProperty("more", openapi3.NewObjectSchema())
+
+	return openapi3.NewObjectSchema().
+		WithProperty("title", openapi3.NewStringSchema()).
+		WithProperty("status", openapi3.NewIntegerSchema()).
+		WithProperty("detail", openapi3.NewStringSchema()).
+		WithProperty("errors", openapi3.NewArraySchema().WithItems(item))
+}
+
+// argsSchema describes positional arguments as a tuple named after the arguments
+func argsSchema(args []model.ArgumentInfo, out bool) *openapi3.Schema {
+	items := make([]*openapi3.SchemaRef, len(args))
+	for i, arg := range args {
+		items[i] = signatureSchema(arg.Type, out)
+		items[i].Value.Title = service.ArgName(arg, i)
+	}
+	return tupleSchema(items)
+}
+
+// tupleSchema describes an array with one schema per position
+func tupleSchema(items []*openapi3.SchemaRef) *openapi3.Schema {
+	count := uint64(len(items))
+	schema := openapi3.NewArraySchema()
+	schema.MinItems = count
+	schema.MaxItems = &count
+	if len(items) > 0 {
+		// prefixItems is a JSON Schema 2020-12 keyword of OpenAPI 3.1 without a field in openapi3
+		schema.Extensions = map[string]any{"prefixItems": items}
+	}
+	return schema
+}
+
+// signatureSchema describes the JSON form of one complete D-Bus type.
+// Byte arrays are encoded as base64 strings in responses (out) and as
+// arrays of numbers in requests.
+func signatureSchema(typ string, out bool) *openapi3.SchemaRef {
+	schema := typeSchema(typ, out)
+	if schema.Extensions == nil {
+		schema.Extensions = make(map[string]any)
+	}
+	schema.Extensions["x-dbus-signature"] = typ
+	return openapi3.NewSchemaRef("", schema)
+}
+
+// typeSchema describes one complete D-Bus type, any value for invalid types
+// and for file descriptors, which cannot be passed over HTTP
+func typeSchema(typ string, out bool) *openapi3.Schema {
+	if types, err := service.SplitSignature(typ); err != nil || len(types) != 1 {
+		return openapi3.NewSchema()
+	}
+
+	switch typ[0] {
+	case 'y':
+		return integerSchema("", 0, math.MaxUint8)
+	case 'n':
+		return integerSchema("int32", math.MinInt16, math.MaxInt16)
+	case 'q':
+		return integerSchema("int32", 0, math.MaxUint16)
+	case 'i':
+		return openapi3.NewInt32Schema()
+	case 'u':
+		return integerSchema("int64", 0, math.MaxUint32)
+	case 'x':
+		return openapi3.NewInt64Schema()
+	case 't':
+		schema := openapi3.NewIntegerSchema().WithMin(0)
+		schema.Format = "uint64"
+		return schema
+	case 'd':
+		schema := openapi3.NewFloat64Schema()
+		schema.Format = "double"
+		return schema
+	case 'b':
+		return openapi3.NewBoolSchema()
+	case 's':
+		return openapi3.NewStringSchema()
+	case 'o':
+		schema := openapi3.NewStringSchema().WithPattern(`^/([A-Za-z0-9_]+(/[A-Za-z0-9_]+)*)?$`)
+		schema.Description = "D-Bus object path"
+		return schema
+	case 'g':
+		schema := openapi3.NewStringSchema()
+		schema.Description = "D-Bus type signature"
+		return schema
+	case 'v':
+		schema := openapi3.NewSchema()
+		schema.Description = "D-Bus variant"
+		return schema
+	case 'a':
+		if typ[1] == '{' {
+			entry, _ := service.SplitSignature(typ[2 : len(typ)-1])
+			schema := openapi3.NewObjectSchema()
+			schema.AdditionalProperties = openapi3.AdditionalProperties{Schema: openapi3.NewSchemaRef("", typeSchema(entry[1], out))}
+			return schema
+		}
+		if typ == "ay" && out {
+			return openapi3.NewBytesSchema()
+		}
+		return openapi3.NewArraySchema().WithItems(typeSchema(typ[1:], out))
+	case '(':
+		members, _ := service.SplitSignature(typ[1 : len(typ)-1])
+		items := make([]*openapi3.SchemaRef, len(members))
+		for i, member := range members {
+			items[i] = openapi3.NewSchemaRef("", typeSchema(member, out))
+		}
+		return tupleSchema(items)
+	default:
+		return openapi3.NewSchema()
+	}
+}
+
+// integerSchema returns an integer schema bounded by the range of a D-Bus integer type
+func integerSchema(format string, minimum, maximum float64) *openapi3.Schema {
+	schema := openapi3.NewIntegerSchema().WithMin(minimum).WithMax(maximum)
+	schema.Format = format
+	return schema
+}
+
+// argSignature concatenates the signatures of the arguments
+func argSignature(args []model.ArgumentInfo) string {
+	var signature strings.Builder
+	for _, arg := range args {
+		signature.WriteString(arg.Type)
+	}
+	return signature.String()
+}
+
+// operationID derives a unique operation ID from an interface and member name
+func operationID(interfaceName, member string) string {
+	return strings.ReplaceAll(interfaceName, ".", "_") + "_" + member
+}
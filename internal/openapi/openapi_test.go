@@ -0,0 +1,104 @@
+package openapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+func helloObjects() []*model.IntrospectionResult {
+	hello := model.InterfaceInfo{
+		Name: "com.example.HelloWorld",
+		Methods: []model.MethodInfo{{
+			Name:    "Hello",
+			InArgs:  []model.ArgumentInfo{{Name: "name", Type: "s", Direction: "in"}, {Type: "a{sv}", Direction: "in"}},
+			OutArgs: []model.ArgumentInfo{{Name: "greeting", Type: "s", Direction: "out"}},
+		}},
+		Properties: []model.PropertyInfo{
+			{Name: "Data", Type: "ay", Access: "readwrite"},
+			{Name: "PID", Type: "u", Access: "read"},
+		},
+	}
+	introspectable := model.InterfaceInfo{Name: "org.freedesktop.DBus.Introspectable"}
+
+	return []*model.IntrospectionResult{
+		{ObjectPath: "/", ParsedData: &model.ParsedIntrospection{Interfaces: []model.InterfaceInfo{introspectable}}},
+		{ObjectPath: "/com/example/A", ParsedData: &model.ParsedIntrospection{Interfaces: []model.InterfaceInfo{introspectable, hello}}},
+		{ObjectPath: "/com/example/B", ParsedData: &model.ParsedIntrospection{Interfaces: []model.InterfaceInfo{hello}}},
+	}
+}
+
+func TestServiceSpec(t *testing.T) {
+	spec := ServiceSpec("session", "com.example.HelloWorld", helloObjects())
+
+	assert.Equal(t, "com.example.HelloWorld", spec.Info.Title)
+	require.Len(t, spec.Tags, 1)
+	assert.Equal(t, "com.example.HelloWorld", spec.Tags[0].Name)
+	assert.Equal(t, 3, spec.Paths.Len())
+
+	call := spec.Paths.Find("/buses/session/services/com.example.HelloWorld/interfaces/com.example.HelloWorld/methods/Hello/call")
+	require.NotNil(t, call)
+	require.NotNil(t, call.Post)
+	assert.Equal(t, "com_example_HelloWorld_Hello", call.Post.OperationID)
+	assert.Equal(t, "D-Bus signature: (sa{sv}) → (s)", call.Post.Description)
+	assert.Equal(t, []any{"/com/example/A", "/com/example/B"}, call.Post.Parameters[0].Value.Schema.Value.Enum)
+
+	args := call.Post.RequestBody.Value.Content.Get("application/json").Schema.Value.Properties["args"].Value
+	assert.EqualValues(t, 2, args.MinItems)
+	items := args.Extensions["prefixItems"].([]*openapi3.SchemaRef)
+	assert.Equal(t, "name", items[0].Value.Title)
+	assert.Equal(t, "arg1", items[1].Value.Title)
+	assert.True(t, items[1].Value.Type.Is("object"))
+
+	property := spec.Paths.Find("/buses/session/services/com.example.HelloWorld/interfaces/com.example.HelloWorld/properties/Data")
+	require.NotNil(t, property)
+	assert.NotNil(t, property.Get)
+	require.NotNil(t, property.Put)
+	assert.Equal(t, "com_example_HelloWorld_SetData", property.Put.OperationID)
+
+	readOnly := spec.Paths.Find("/buses/session/services/com.example.HelloWorld/interfaces/com.example.HelloWorld/properties/PID")
+	require.NotNil(t, readOnly)
+	assert.Nil(t, readOnly.Put)
+
+	schema := spec.Components.Schemas["com.example.HelloWorld"].Value
+	assert.True(t, schema.Properties["PID"].Value.ReadOnly)
+	assert.Equal(t, "u", schema.Properties["PID"].Value.Extensions["x-dbus-signature"])
+
+	_, err := json.Marshal(spec)
+	assert.NoError(t, err)
+}
+
+func TestTypeSchema(t *testing.T) {
+	tests := []struct {
+		signature string
+		out       bool
+		expected  string
+	}{
+		{"y", false, `{"maximum":255,"minimum":0,"type":"integer"}`},
+		{"u", false, `{"format":"int64","maximum":4294967295,"minimum":0,"type":"integer"}`},
+		{"d", false, `{"format":"double","type":"number"}`},
+		{"b", false, `{"type":"boolean"}`},
+		{"as", false, `{"items":{"type":"string"},"type":"array"}`},
+		{"ay", false, `{"items":{"maximum":255,"minimum":0,"type":"integer"},"type":"array"}`},
+		{"ay", true, `{"format":"byte","type":"string"}`},
+		{"a{sb}", false, `{"additionalProperties":{"type":"boolean"},"type":"object"}`},
+		{"(sx)", false, `{"maxItems":2,"minItems":2,"prefixItems":[{"type":"string"},{"format":"int64","type":"integer"}],"type":"array"}`},
+		{"v", false, `{"description":"D-Bus variant"}`},
+		{"a{", false, `{}`},
+		{"h", false, `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.signature, func(t *testing.T) {
+			data, err := json.Marshal(typeSchema(tt.signature, tt.out))
+
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(data))
+		})
+	}
+}
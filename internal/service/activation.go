@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// startServiceReplies names the replies of StartServiceByName
+var startServiceReplies = map[uint32]string{
+	1: "success",
+	2: "already_running",
+}
+
+// StartService starts an activatable service by name, waiting until it owns
+// the name or its activation fails
+func (s *DBusService) StartService(ctx context.Context, busType, serviceName string) (*model.ServiceStart, error) {
+	conn, err := s.getConnection(busType)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply uint32
+	err = s.call(ctx, busType, conn.BusObject(), "org.freedesktop.DBus.StartServiceByName", 0, serviceName, uint32(0)).Store(&reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start %s: %w", serviceName, err)
+	}
+
+	start := &model.ServiceStart{Name: serviceName, Reply: startServiceReplies[reply]}
+	if owner, err := s.GetNameOwner(ctx, busType, serviceName); err == nil {
+		start.Owner = owner
+	}
+	slog.InfoContext(ctx, "Service started", "bus", busType, "service", serviceName, "reply", start.Reply, "owner", start.Owner)
+	return start, nil
+}
+
+// UpdateActivationEnvironment adds or replaces variables of the environment
+// the bus starts activatable services with. Services already running keep
+// their environment.
+func (s *DBusService) UpdateActivationEnvironment(ctx context.Context, busType string, environment map[string]string) error {
+	conn, err := s.getConnection(busType)
+	if err != nil {
+		return err
+	}
+
+	err = s.call(ctx, busType, conn.BusObject(), "org.freedesktop.DBus.UpdateActivationEnvironment", 0, environment).Err
+	if err != nil {
+		return fmt.Errorf("failed to update the activation environment: %w", err)
+	}
+
+	keys := make([]string, 0, len(environment))
+	for key := range environment {
+		keys = append(keys, key)
+	}
+	slog.InfoContext(ctx, "Activation environment updated", "bus", busType, "variables", keys)
+	return nil
+}
@@ -0,0 +1,498 @@
+package service
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// ArgError reports a malformed textual argument and its position
+type ArgError struct {
+	Arg    int // Index of the textual argument, -1 for the signature
+	Offset int // Byte offset of the error in the argument or signature
+	Msg    string
+}
+
+func (e *ArgError) Error() string {
+	if e.Arg < 0 {
+		return fmt.Sprintf("signature at offset %d: %s", e.Offset, e.Msg)
+	}
+	return fmt.Sprintf("argument %d at offset %d: %s", e.Arg, e.Offset, e.Msg)
+}
+
+// ParseBusctlArgs converts arguments in busctl notation to typed D-Bus values.
+// The signature lists the types of the values; every basic value takes one
+// textual argument, arrays and dictionaries are preceded by their number of
+// elements, and variants by the signature of their value, for example
+// signature "sa{sv}" with arguments "foo 1 key s val".
+func ParseBusctlArgs(signature string, args []string) ([]interface{}, error) {
+	types, err := SplitSignature(signature)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &busctlParser{args: args}
+	values := make([]interface{}, 0, len(types))
+	for _, typ := range types {
+		value, err := p.value(typ)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+
+	if p.pos < len(args) {
+		return nil, &ArgError{Arg: p.pos, Msg: fmt.Sprintf("unexpected argument %q: signature %q is complete", args[p.pos], signature)}
+	}
+
+	return values, nil
+}
+
+// busctlParser consumes textual arguments in busctl notation
+type busctlParser struct {
+	args []string
+	pos  int
+}
+
+// next returns the next textual argument
+func (p *busctlParser) next(typ string) (string, int, error) {
+	if p.pos >= len(p.args) {
+		return "", 0, &ArgError{Arg: p.pos, Msg: fmt.Sprintf("missing argument for type %s", typ)}
+	}
+	p.pos++
+	return p.args[p.pos-1], p.pos - 1, nil
+}
+
+// value parses the arguments of one complete type
+func (p *busctlParser) value(typ string) (interface{}, error) {
+	goType, err := typeFor(typ)
+	if err != nil {
+		return nil, err
+	}
+
+	switch typ[0] {
+	case 'v':
+		text, index, err := p.next(typ)
+		if err != nil {
+			return nil, err
+		}
+		sig, err := dbus.ParseSignature(text)
+		if err != nil || len(mustSplit(text)) != 1 {
+			return nil, &ArgError{Arg: index, Msg: fmt.Sprintf("invalid variant signature %q", text)}
+		}
+		inner, err := p.value(text)
+		if err != nil {
+			return nil, err
+		}
+		return dbus.MakeVariantWithSignature(inner, sig), nil
+
+	case 'a':
+		text, index, err := p.next(typ)
+		if err != nil {
+			return nil, err
+		}
+		count, err := strconv.ParseUint(text, 10, 32)
+		if err != nil {
+			return nil, &ArgError{Arg: index, Msg: fmt.Sprintf("invalid element count %q for type %s", text, typ)}
+		}
+		// Every element takes at least one argument; the count also sizes the allocations below
+		if count > uint64(len(p.args)-p.pos) {
+			return nil, &ArgError{Arg: index, Msg: fmt.Sprintf("element count %d for type %s exceeds the %d remaining arguments", count, typ, len(p.args)-p.pos)}
+		}
+
+		if typ[1] == '{' {
+			entry := mustSplit(typ[2 : len(typ)-1])
+			dict := reflect.MakeMapWithSize(goType, int(count))
+			for i := uint64(0); i < count; i++ {
+				key, err := p.value(entry[0])
+				if err != nil {
+					return nil, err
+				}
+				value, err := p.value(entry[1])
+				if err != nil {
+					return nil, err
+				}
+				dict.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(value))
+			}
+			return dict.Interface(), nil
+		}
+
+		slice := reflect.MakeSlice(goType, 0, int(count))
+		for i := uint64(0); i < count; i++ {
+			elem, err := p.value(typ[1:])
+			if err != nil {
+				return nil, err
+			}
+			slice = reflect.Append(slice, reflect.ValueOf(elem))
+		}
+		return slice.Interface(), nil
+
+	case '(':
+		fields := mustSplit(typ[1 : len(typ)-1])
+		structValue := reflect.New(goType).Elem()
+		for i, field := range fields {
+			value, err := p.value(field)
+			if err != nil {
+				return nil, err
+			}
+			structValue.Field(i).Set(reflect.ValueOf(value))
+		}
+		return structValue.Interface(), nil
+
+	default:
+		text, index, err := p.next(typ)
+		if err != nil {
+			return nil, err
+		}
+		value, err := parseBasic(typ[0], text)
+		if err != nil {
+			return nil, &ArgError{Arg: index, Msg: err.Error()}
+		}
+		return value, nil
+	}
+}
+
+// dbusSendTypes maps the type names of dbus-send to signature codes
+var dbusSendTypes = map[string]byte{
+	"string":  's',
+	"objpath": 'o',
+	"byte":    'y',
+	"boolean": 'b',
+	"int16":   'n',
+	"uint16":  'q',
+	"int32":   'i',
+	"uint32":  'u',
+	"int64":   'x',
+	"uint64":  't',
+	"double":  'd',
+}
+
+// ParseDBusSendArgs converts arguments in dbus-send notation to typed D-Bus values:
+// TYPE:VALUE, variant:TYPE:VALUE, array:TYPE:VALUE[,VALUE...] and
+// dict:KEYTYPE:VALUETYPE:KEY,VALUE[,KEY,VALUE...].
+func ParseDBusSendArgs(args []string) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(args))
+	for i, arg := range args {
+		value, err := parseDBusSendArg(arg)
+		if err != nil {
+			err.Arg = i
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+// parseDBusSendArg parses one dbus-send argument; the returned error has no argument index
+func parseDBusSendArg(arg string) (interface{}, *ArgError) {
+	container, rest, offset := cutType(arg, 0)
+
+	switch container {
+	case "variant":
+		code, text, valueOffset, err := basicType(rest, offset)
+		if err != nil {
+			return nil, err
+		}
+		value, parseErr := parseBasic(code, text)
+		if parseErr != nil {
+			return nil, &ArgError{Offset: valueOffset, Msg: parseErr.Error()}
+		}
+		return dbus.MakeVariant(value), nil
+
+	case "array":
+		code, text, valueOffset, err := basicType(rest, offset)
+		if err != nil {
+			return nil, err
+		}
+		goType, _ := typeFor(string(code))
+		slice := reflect.MakeSlice(reflect.SliceOf(goType), 0, 0)
+		if text == "" {
+			return slice.Interface(), nil
+		}
+		for _, item := range strings.Split(text, ",") {
+			value, parseErr := parseBasic(code, item)
+			if parseErr != nil {
+				return nil, &ArgError{Offset: valueOffset, Msg: parseErr.Error()}
+			}
+			slice = reflect.Append(slice, reflect.ValueOf(value))
+			valueOffset += len(item) + 1
+		}
+		return slice.Interface(), nil
+
+	case "dict":
+		keyCode, rest, valueOffset, err := basicType(rest, offset)
+		if err != nil {
+			return nil, err
+		}
+		valueCode, text, valueOffset, err := basicType(rest, valueOffset)
+		if err != nil {
+			return nil, err
+		}
+		keyType, _ := typeFor(string(keyCode))
+		valueType, _ := typeFor(string(valueCode))
+		dict := reflect.MakeMap(reflect.MapOf(keyType, valueType))
+		if text == "" {
+			return dict.Interface(), nil
+		}
+		items := strings.Split(text, ",")
+		if len(items)%2 != 0 {
+			return nil, &ArgError{Offset: valueOffset, Msg: "dict entries must be KEY,VALUE pairs"}
+		}
+		for i := 0; i < len(items); i += 2 {
+			key, parseErr := parseBasic(keyCode, items[i])
+			if parseErr != nil {
+				return nil, &ArgError{Offset: valueOffset, Msg: parseErr.Error()}
+			}
+			valueOffset += len(items[i]) + 1
+			value, parseErr := parseBasic(valueCode, items[i+1])
+			if parseErr != nil {
+				return nil, &ArgError{Offset: valueOffset, Msg: parseErr.Error()}
+			}
+			valueOffset += len(items[i+1]) + 1
+			dict.SetMapIndex(reflect.ValueOf(key), reflect.ValueOf(value))
+		}
+		return dict.Interface(), nil
+
+	default:
+		code, text, valueOffset, err := basicType(arg, 0)
+		if err != nil {
+			return nil, err
+		}
+		value, parseErr := parseBasic(code, text)
+		if parseErr != nil {
+			return nil, &ArgError{Offset: valueOffset, Msg: parseErr.Error()}
+		}
+		return value, nil
+	}
+}
+
+// cutType splits "TYPE:REST" and returns the offset of REST in the argument
+func cutType(text string, offset int) (string, string, int) {
+	typeName, rest, found := strings.Cut(text, ":")
+	if !found {
+		return "", text, offset
+	}
+	return typeName, rest, offset + len(typeName) + 1
+}
+
+// basicType parses a leading dbus-send basic type name
+func basicType(text string, offset int) (byte, string, int, *ArgError) {
+	typeName, rest, restOffset := cutType(text, offset)
+	if typeName == "" {
+		return 0, "", 0, &ArgError{Offset: offset, Msg: fmt.Sprintf("expected TYPE:VALUE, got %q", text)}
+	}
+	code, ok := dbusSendTypes[typeName]
+	if !ok {
+		return 0, "", 0, &ArgError{Offset: offset, Msg: fmt.Sprintf("unknown type %q", typeName)}
+	}
+	return code, rest, restOffset, nil
+}
+
+// parseBasic converts the text of a basic D-Bus type to its Go value
+func parseBasic(code byte, text string) (interface{}, error) {
+	switch code {
+	case 's':
+		return text, nil
+	case 'o':
+		if !dbus.ObjectPath(text).IsValid() {
+			return nil, fmt.Errorf("invalid object path %q", text)
+		}
+		return dbus.ObjectPath(text), nil
+	case 'g':
+		sig, err := dbus.ParseSignature(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid signature %q", text)
+		}
+		return sig, nil
+	case 'b':
+		switch strings.ToLower(text) {
+		case "true", "yes", "on", "1":
+			return true, nil
+		case "false", "no", "off", "0":
+			return false, nil
+		}
+		return nil, fmt.Errorf("invalid boolean %q", text)
+	case 'y':
+		value, err := strconv.ParseUint(text, 0, 8)
+		if err != nil {
+			return nil, fmt.Errorf("invalid byte %q", text)
+		}
+		return byte(value), nil
+	case 'n':
+		value, err := strconv.ParseInt(text, 0, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid int16 %q", text)
+		}
+		return int16(value), nil
+	case 'q':
+		value, err := strconv.ParseUint(text, 0, 16)
+		if err != nil {
+			return nil, fmt.Errorf("invalid uint16 %q", text)
+		}
+		return uint16(value), nil
+	case 'i':
+		value, err := strconv.ParseInt(text, 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid int32 %q", text)
+		}
+		return int32(value), nil
+	case 'u':
+		value, err := strconv.ParseUint(text, 0, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid uint32 %q", text)
+		}
+		return uint32(value), nil
+	case 'x':
+		value, err := strconv.ParseInt(text, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid int64 %q", text)
+		}
+		return value, nil
+	case 't':
+		value, err := strconv.ParseUint(text, 0, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid uint64 %q", text)
+		}
+		return value, nil
+	case 'd':
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid double %q", text)
+		}
+		return value, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %q", code)
+	}
+}
+
+// basicTypes maps the basic signature codes to their Go types
+var basicTypes = map[byte]reflect.Type{
+	'y': reflect.TypeOf(byte(0)),
+	'b': reflect.TypeOf(false),
+	'n': reflect.TypeOf(int16(0)),
+	'q': reflect.TypeOf(uint16(0)),
+	'i': reflect.TypeOf(int32(0)),
+	'u': reflect.TypeOf(uint32(0)),
+	'x': reflect.TypeOf(int64(0)),
+	't': reflect.TypeOf(uint64(0)),
+	'd': reflect.TypeOf(float64(0)),
+	's': reflect.TypeOf(""),
+	'o': reflect.TypeOf(dbus.ObjectPath("")),
+	'g': reflect.TypeOf(dbus.Signature{}),
+	'v': reflect.TypeOf(dbus.Variant{}),
+}
+
+// typeFor returns the Go type encoding one complete D-Bus type.
+// Structs are encoded as Go structs with one exported field per member.
+func typeFor(typ string) (reflect.Type, error) {
+	if goType, ok := basicTypes[typ[0]]; ok {
+		return goType, nil
+	}
+
+	switch typ[0] {
+	case 'a':
+		if typ[1] == '{' {
+			entry := mustSplit(typ[2 : len(typ)-1])
+			keyType, err := typeFor(entry[0])
+			if err != nil {
+				return nil, err
+			}
+			valueType, err := typeFor(entry[1])
+			if err != nil {
+				return nil, err
+			}
+			return reflect.MapOf(keyType, valueType), nil
+		}
+		elemType, err := typeFor(typ[1:])
+		if err != nil {
+			return nil, err
+		}
+		return reflect.SliceOf(elemType), nil
+	case '(':
+		members := mustSplit(typ[1 : len(typ)-1])
+		fields := make([]reflect.StructField, len(members))
+		for i, member := range members {
+			fieldType, err := typeFor(member)
+			if err != nil {
+				return nil, err
+			}
+			fields[i] = reflect.StructField{Name: fmt.Sprintf("F%d", i), Type: fieldType}
+		}
+		return reflect.StructOf(fields), nil
+	default:
+		return nil, fmt.Errorf("unsupported type %q", typ)
+	}
+}
+
+// SplitSignature splits a signature into its complete types, reporting the offset of invalid ones
+func SplitSignature(signature string) ([]string, error) {
+	types := make([]string, 0)
+	for i := 0; i < len(signature); {
+		end, err := completeType(signature, i)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, signature[i:end])
+		i = end
+	}
+	return types, nil
+}
+
+// mustSplit splits a signature already validated by SplitSignature
+func mustSplit(signature string) []string {
+	types, err := SplitSignature(signature)
+	if err != nil {
+		return nil
+	}
+	return types
+}
+
+// completeType returns the end of the complete type starting at offset i
+func completeType(signature string, i int) (int, error) {
+	if i >= len(signature) {
+		return 0, &ArgError{Arg: -1, Offset: i, Msg: "incomplete type"}
+	}
+
+	switch c := signature[i]; {
+	case c == 'h':
+		return 0, &ArgError{Arg: -1, Offset: i, Msg: "file descriptors cannot be passed over HTTP"}
+	case strings.IndexByte("ybnqiuxtdsogv", c) >= 0:
+		return i + 1, nil
+	case c == 'a':
+		if i+1 < len(signature) && signature[i+1] == '{' {
+			if i+2 >= len(signature) || strings.IndexByte("ybnqiuxtdsog", signature[i+2]) < 0 {
+				return 0, &ArgError{Arg: -1, Offset: i + 2, Msg: "dictionary keys must be basic types"}
+			}
+			end, err := completeType(signature, i+3)
+			if err != nil {
+				return 0, err
+			}
+			if end >= len(signature) || signature[end] != '}' {
+				return 0, &ArgError{Arg: -1, Offset: end, Msg: "expected '}' after the dictionary value type"}
+			}
+			return end + 1, nil
+		}
+		return completeType(signature, i+1)
+	case c == '(':
+		j := i + 1
+		if j < len(signature) && signature[j] == ')' {
+			return 0, &ArgError{Arg: -1, Offset: j, Msg: "empty struct"}
+		}
+		for j < len(signature) && signature[j] != ')' {
+			end, err := completeType(signature, j)
+			if err != nil {
+				return 0, err
+			}
+			j = end
+		}
+		if j >= len(signature) {
+			return 0, &ArgError{Arg: -1, Offset: j, Msg: "expected ')' to close the struct"}
+		}
+		return j + 1, nil
+	default:
+		return 0, &ArgError{Arg: -1, Offset: i, Msg: fmt.Sprintf("invalid type code %q", c)}
+	}
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBusctlArgs_Basic(t *testing.T) {
+	values, err := ParseBusctlArgs("sbyniuxtdog", []string{"hello", "yes", "255", "-2", "0x10", "7", "-9", "9", "1.5", "/a/b", "a{sv}"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		"hello", true, byte(255), int16(-2), int32(16), uint32(7), int64(-9), uint64(9), 1.5,
+		dbus.ObjectPath("/a/b"), dbus.SignatureOf(map[string]dbus.Variant{}),
+	}, values)
+}
+
+func TestParseBusctlArgs_Containers(t *testing.T) {
+	values, err := ParseBusctlArgs("asa{sv}(si)", []string{
+		"2", "a", "b",
+		"2", "one", "i", "1", "two", "as", "1", "x",
+		"name", "42",
+	})
+
+	require.NoError(t, err)
+	require.Len(t, values, 3)
+	assert.Equal(t, []string{"a", "b"}, values[0])
+	assert.Equal(t, map[string]dbus.Variant{
+		"one": dbus.MakeVariant(int32(1)),
+		"two": dbus.MakeVariant([]string{"x"}),
+	}, values[1])
+
+	// Structs encode with the signature of their members
+	assert.Equal(t, "(si)", dbus.SignatureOf(values[2]).String())
+
+	assert.Equal(t, "asa{sv}(si)", dbus.SignatureOf(values...).String())
+}
+
+func TestParseBusctlArgs_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		signature string
+		args      []string
+		arg       int
+		offset    int
+	}{
+		{"invalid type code", "sz", []string{"a", "b"}, -1, 1},
+		{"unclosed struct", "(si", nil, -1, 3},
+		{"non-basic dict key", "a{vs}", nil, -1, 2},
+		{"file descriptor", "h", []string{"0"}, -1, 0},
+		{"missing argument", "ss", []string{"a"}, 1, 0},
+		{"invalid integer", "si", []string{"a", "x"}, 1, 0},
+		{"out of range", "y", []string{"256"}, 0, 0},
+		{"invalid count", "as", []string{"many"}, 0, 0},
+		{"count exceeding arguments", "as", []string{"3", "a", "b"}, 0, 0},
+		{"huge array count", "ax", []string{"4294967295"}, 0, 0},
+		{"huge dict count", "a{sx}", []string{"4294967295", "a", "1"}, 0, 0},
+		{"invalid variant signature", "v", []string{"ss", "a", "b"}, 0, 0},
+		{"extra argument", "s", []string{"a", "b"}, 1, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseBusctlArgs(tt.signature, tt.args)
+
+			var argErr *ArgError
+			require.True(t, errors.As(err, &argErr), "expected ArgError, got %v", err)
+			assert.Equal(t, tt.arg, argErr.Arg)
+			assert.Equal(t, tt.offset, argErr.Offset)
+		})
+	}
+}
+
+func TestParseDBusSendArgs(t *testing.T) {
+	values, err := ParseDBusSendArgs([]string{
+		"string:hello",
+		"uint32:5",
+		"boolean:true",
+		"objpath:/a",
+		"variant:int32:-1",
+		"array:string:a,b",
+		"array:int16:",
+		"dict:string:int32:one,1,two,2",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		"hello",
+		uint32(5),
+		true,
+		dbus.ObjectPath("/a"),
+		dbus.MakeVariant(int32(-1)),
+		[]string{"a", "b"},
+		[]int16{},
+		map[string]int32{"one": 1, "two": 2},
+	}, values)
+}
+
+func TestParseDBusSendArgs_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		args   []string
+		arg    int
+		offset int
+	}{
+		{"missing type", []string{"string:a", "hello"}, 1, 0},
+		{"unknown type", []string{"float:1"}, 0, 0},
+		{"invalid value", []string{"int32:x"}, 0, 6},
+		{"invalid array element", []string{"array:byte:1,2,300"}, 0, 15},
+		{"odd dict entries", []string{"dict:string:int32:a,1,b"}, 0, 18},
+		{"invalid dict value", []string{"dict:string:int32:a,1,b,c"}, 0, 24},
+		{"unknown variant type", []string{"variant:list:1"}, 0, 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseDBusSendArgs(tt.args)
+
+			var argErr *ArgError
+			require.True(t, errors.As(err, &argErr), "expected ArgError, got %v", err)
+			assert.Equal(t, tt.arg, argErr.Arg)
+			assert.Equal(t, tt.offset, argErr.Offset)
+		})
+	}
+}
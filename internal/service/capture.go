@@ -0,0 +1,141 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// pcap constants of the captures, as written by busctl capture
+const (
+	pcapMagic       = 0xa1b2c3d4 // Microsecond timestamps
+	pcapLinkTypeBus = 231        // DLT_DBUS
+	pcapSnapLen     = 1 << 27    // Maximum D-Bus message size
+)
+
+// errUnencodableMessage is returned for captured messages that cannot be
+// encoded again
+var errUnencodableMessage = errors.New("captured message cannot be encoded")
+
+// Capture records the messages of a bus selected by match rules, or every
+// message without rules, and writes them to w as a pcap file with the DLT_DBUS
+// link type. The capture runs for duration, until ctx is done or the service
+// shuts down. Messages are queued while w is slow and dropped while the buffer
+// of the monitor is full or when they cannot be encoded again; the returned
+// stats count them. Nothing is written
+// when the monitor cannot be opened, and it returns the error of ctx when ctx
+// is done before duration.
+func (s *DBusService) Capture(ctx context.Context, busType string, rules []string, duration time.Duration, w io.Writer) (*model.CaptureStats, error) {
+	session, limits, err := s.startMonitor(ctx, busType, rules)
+	if err != nil {
+		return nil, err
+	}
+	defer session.stop()
+
+	slog.InfoContext(ctx, "Capture started", "bus", busType, "rules", rules, "duration", duration)
+
+	captureCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	stats := &model.CaptureStats{}
+	if err := writePcapHeader(w); err != nil {
+		return stats, err
+	}
+
+	// The bus connection drops the messages it cannot hand over at once,
+	// so they are received apart from the writes to w
+	queue := make(chan capturedMessage, limits.Buffer)
+	var dropped uint64
+	go func() {
+		defer close(queue)
+		for {
+			msg, ok := session.next(captureCtx)
+			if !ok {
+				return
+			}
+			select {
+			case queue <- capturedMessage{msg: msg, receivedAt: time.Now()}:
+			default:
+				dropped++
+			}
+		}
+	}()
+
+	var unencodable uint64
+	for captured := range queue {
+		if err := writePcapRecord(w, captured.receivedAt, captured.msg); err != nil {
+			if errors.Is(err, errUnencodableMessage) {
+				slog.WarnContext(ctx, "Capture skipped a message", "bus", busType, "error", err)
+				unencodable++
+				continue
+			}
+			cancel()
+			for range queue {
+			}
+			stats.Dropped = dropped + unencodable
+			return stats, err
+		}
+		stats.Messages++
+	}
+	stats.Dropped = dropped + unencodable
+
+	if stats.Dropped > 0 {
+		slog.WarnContext(ctx, "Capture dropped messages", "bus", busType, "dropped", stats.Dropped)
+	}
+	slog.InfoContext(ctx, "Capture finished", "bus", busType, "messages", stats.Messages, "dropped", stats.Dropped)
+	return stats, ctx.Err()
+}
+
+// capturedMessage is a message queued for the capture file
+type capturedMessage struct {
+	msg        *dbus.Message
+	receivedAt time.Time
+}
+
+// writePcapHeader writes the global header of a pcap file
+func writePcapHeader(w io.Writer) error {
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint32(header[0:], pcapMagic)
+	binary.LittleEndian.PutUint16(header[4:], 2) // Version 2.4
+	binary.LittleEndian.PutUint16(header[6:], 4)
+	binary.LittleEndian.PutUint32(header[16:], pcapSnapLen)
+	binary.LittleEndian.PutUint32(header[20:], pcapLinkTypeBus)
+
+	if _, err := w.Write(header); err != nil {
+		return fmt.Errorf("failed to write capture: %w", err)
+	}
+	return nil
+}
+
+// writePcapRecord writes a message as a pcap record in a single write.
+// The message is encoded again from its decoded form, in little endian, so the
+// record is not the original bytes of the message. It returns
+// errUnencodableMessage, writing nothing, when the message does not encode.
+func writePcapRecord(w io.Writer, timestamp time.Time, msg *dbus.Message) error {
+	var record bytes.Buffer
+	record.Write(make([]byte, 16))
+	if err := msg.EncodeTo(&record, binary.LittleEndian); err != nil {
+		return fmt.Errorf("%w: %v", errUnencodableMessage, err)
+	}
+
+	data := record.Bytes()
+	length := uint32(len(data) - 16)
+	binary.LittleEndian.PutUint32(data[0:], uint32(timestamp.Unix()))
+	binary.LittleEndian.PutUint32(data[4:], uint32(timestamp.Nanosecond()/1000))
+	binary.LittleEndian.PutUint32(data[8:], length)
+	binary.LittleEndian.PutUint32(data[12:], length)
+
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("failed to write capture: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readPcap returns the link type and the records of a pcap file
+func readPcap(t *testing.T, data []byte) (uint32, [][]byte) {
+	require.GreaterOrEqual(t, len(data), 24)
+	assert.Equal(t, uint32(pcapMagic), binary.LittleEndian.Uint32(data[0:]))
+	assert.Equal(t, uint16(2), binary.LittleEndian.Uint16(data[4:]))
+	assert.Equal(t, uint16(4), binary.LittleEndian.Uint16(data[6:]))
+	linkType := binary.LittleEndian.Uint32(data[20:])
+
+	var records [][]byte
+	for rest := data[24:]; len(rest) > 0; {
+		require.GreaterOrEqual(t, len(rest), 16)
+		length := binary.LittleEndian.Uint32(rest[8:])
+		assert.Equal(t, length, binary.LittleEndian.Uint32(rest[12:]))
+		require.GreaterOrEqual(t, uint32(len(rest)-16), length)
+		records = append(records, rest[16:16+length])
+		rest = rest[16+length:]
+	}
+	return linkType, records
+}
+
+func TestWritePcap(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, writePcapHeader(&out))
+
+	sig := &dbus.Message{
+		Type: dbus.TypeSignal,
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldPath:      dbus.MakeVariant(dbus.ObjectPath("/com/example/Door")),
+			dbus.FieldInterface: dbus.MakeVariant("com.example.Door"),
+			dbus.FieldMember:    dbus.MakeVariant("Opened"),
+			dbus.FieldSignature: dbus.MakeVariant(dbus.SignatureOf("")),
+		},
+		Body: []interface{}{"front"},
+	}
+	timestamp := time.Unix(1700000000, 250000000)
+	require.NoError(t, writePcapRecord(&out, timestamp, sig))
+
+	linkType, records := readPcap(t, out.Bytes())
+	assert.Equal(t, uint32(231), linkType)
+	require.Len(t, records, 1)
+	record := out.Bytes()[24:]
+	assert.Equal(t, uint32(1700000000), binary.LittleEndian.Uint32(record[0:]))
+	assert.Equal(t, uint32(250000), binary.LittleEndian.Uint32(record[4:]))
+
+	decoded, err := dbus.DecodeMessage(bytes.NewReader(records[0]))
+	require.NoError(t, err)
+	assert.Equal(t, dbus.TypeSignal, decoded.Type)
+	assert.Equal(t, "Opened", decoded.Headers[dbus.FieldMember].Value())
+	assert.Equal(t, []interface{}{"front"}, decoded.Body)
+}
+
+func TestWritePcapRecord_Unencodable(t *testing.T) {
+	var out bytes.Buffer
+
+	// A message without a type does not encode
+	err := writePcapRecord(&out, time.Now(), &dbus.Message{})
+
+	assert.ErrorIs(t, err, errUnencodableMessage)
+	assert.Zero(t, out.Len())
+}
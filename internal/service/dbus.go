@@ -1,69 +1,147 @@
 package service
 
 import (
+	"bytes"
+	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/godbus/dbus/v5"
 	"github.com/godbus/dbus/v5/introspect"
-	"github.com/mesbrj/dbus-controller/internal/model"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
 )
 
+// tracer creates the spans of D-Bus operations
+var tracer = otel.Tracer("github.com/mesbrj/dbus-controller/internal/service")
+
+// healthCheckTimeout bounds the bus daemon round trips of CheckBus
+const healthCheckTimeout = 2 * time.Second
+
 // DBusService provides D-Bus operations
 type DBusService struct {
 	systemConn    *dbus.Conn
 	sessionConn   *dbus.Conn
 	mutex         sync.RWMutex
 	subscriptions map[string]*SignalHandler
+	routers       map[string]*signalRouter
+	streams       map[*SignalWatch]struct{} // Watches of the name owner and signal streams
+	monitors      map[*dbus.Conn]struct{}
+	openMonitors  int // Monitors in the map or being set up
+	monitorLimits MonitorLimits
+	jobs          *jobTable
+	noReply       noReplyCache
+	// Whether each bus shares the process namespace of the controller
+	systemNamespace, sessionNamespace processNamespace
+}
+
+// processNamespace records once whether the process IDs of a bus are those
+// of the controller
+type processNamespace struct {
+	once   sync.Once
+	shared bool
 }
 
-// SignalHandler manages signal subscriptions
+// ErrSubscriptionNotFound is returned for unknown signal subscription IDs
+var ErrSubscriptionNotFound = errors.New("subscription not found")
+
+// ErrInterfaceNotFound is returned when an object does not implement an interface
+var ErrInterfaceNotFound = errors.New("interface not found")
+
+// ErrInvalidSignal is returned for signals with invalid header fields or arguments
+var ErrInvalidSignal = errors.New("invalid signal")
+
+// SignalHandler manages a signal subscription: its bus match rule
+// and the watches streaming its signals to clients
 type SignalHandler struct {
-	channel chan *dbus.Signal
-	active  bool
-	mu      sync.RWMutex
+	subscription *model.SignalSubscription
+	match        SignalMatch
+	streams      map[*SignalWatch]struct{}
 }
 
 // NewDBusService creates a new D-Bus service instance
 func NewDBusService() *DBusService {
 	service := &DBusService{
 		subscriptions: make(map[string]*SignalHandler),
+		routers:       make(map[string]*signalRouter),
+		streams:       make(map[*SignalWatch]struct{}),
+		monitors:      make(map[*dbus.Conn]struct{}),
+		monitorLimits: DefaultMonitorLimits,
+		jobs:          newJobTable(DefaultJobLimits),
 	}
 
 	// Initialize system bus connection
 	if conn, err := dbus.SystemBus(); err == nil {
 		service.systemConn = conn
+	} else {
+		slog.Warn("System bus not available", "error", err)
 	}
 
 	// Initialize session bus connection
 	if conn, err := dbus.SessionBus(); err == nil {
 		service.sessionConn = conn
+	} else {
+		slog.Warn("Session bus not available", "error", err)
 	}
 
 	return service
 }
 
-// Close closes all D-Bus connections
+// Close abandons the unfinished jobs, removes all signal subscriptions and
+// closes the D-Bus connections. Match rules are removed and signal channels
+// unregistered before the buses go away.
 func (s *DBusService) Close() {
+	s.jobs.close()
+
+	s.mutex.Lock()
+	handlers := make(map[string]*SignalHandler, len(s.subscriptions))
+	var streams []*SignalWatch
+	for id, handler := range s.subscriptions {
+		streams = append(streams, handler.takeStreams()...)
+		handlers[id] = handler
+		delete(s.subscriptions, id)
+	}
+	s.mutex.Unlock()
+
+	// Match rules are removed without the lock, within healthCheckTimeout overall
+	ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+	defer cancel()
+
+	closeWatches(streams)
+	for id, handler := range handlers {
+		if err := s.removeMatch(ctx, handler); err != nil {
+			slog.Warn("Failed to remove signal subscription", "subscription", id, "error", err)
+		}
+	}
+
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
 
+	s.closeMonitors()
+
+	// Stop signal routers while their connections are still open
+	for busType, router := range s.routers {
+		router.stop()
+		delete(s.routers, busType)
+	}
+
 	if s.systemConn != nil {
 		s.systemConn.Close()
 	}
 	if s.sessionConn != nil {
 		s.sessionConn.Close()
 	}
-
-	// Close all signal subscriptions
-	for _, handler := range s.subscriptions {
-		handler.mu.Lock()
-		handler.active = false
-		close(handler.channel)
-		handler.mu.Unlock()
-	}
 }
 
 // getConnection returns the appropriate D-Bus connection
@@ -84,15 +162,168 @@ func (s *DBusService) getConnection(busType string) (*dbus.Conn, error) {
 	}
 }
 
+// CheckBus pings the bus daemon and returns the connectivity of the bus
+func (s *DBusService) CheckBus(ctx context.Context, busType string) *model.BusHealth {
+	health := &model.BusHealth{Type: busType}
+
+	conn, err := s.getConnection(busType)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	if !conn.Connected() {
+		health.Error = "connection closed"
+		return health
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err = s.call(ctx, busType, conn.BusObject(), "org.freedesktop.DBus.Peer.Ping", 0).Err
+	if err != nil {
+		health.Error = fmt.Sprintf("ping failed: %v", err)
+		return health
+	}
+	health.Latency = time.Since(start).String()
+
+	err = s.call(ctx, busType, conn.BusObject(), "org.freedesktop.DBus.GetId", 0).Store(&health.ID)
+	if err != nil {
+		health.Error = fmt.Sprintf("failed to get bus id: %v", err)
+		return health
+	}
+
+	health.Connected = true
+	return health
+}
+
+// call invokes a D-Bus method in a client span and logs the round trip with
+// the request attributes of ctx
+func (s *DBusService) call(ctx context.Context, busType string, obj dbus.BusObject, method string, flags dbus.Flags, args ...interface{}) *dbus.Call {
+	ctx, end := s.startCall(ctx, busType, obj, method)
+	call := obj.CallWithContext(ctx, method, flags, args...)
+	end(call.Err)
+	return call
+}
+
+// startCall starts the client span of a D-Bus method call. The returned
+// function ends the span and logs the round trip with its error, if any.
+func (s *DBusService) startCall(ctx context.Context, busType string, obj dbus.BusObject, method string) (context.Context, func(error)) {
+	iface, member := splitMember(method)
+	ctx, span := tracer.Start(ctx, method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "dbus"),
+			attribute.String("dbus.bus", busType),
+			attribute.String("dbus.destination", obj.Destination()),
+			attribute.String("dbus.path", string(obj.Path())),
+			attribute.String("dbus.interface", iface),
+			attribute.String("dbus.member", member),
+		),
+	)
+
+	start := time.Now()
+	return ctx, func(err error) {
+		defer span.End()
+
+		attrs := []any{
+			slog.String("bus", busType),
+			slog.String("destination", obj.Destination()),
+			slog.String("path", string(obj.Path())),
+			slog.String("method", method),
+			slog.Duration("duration", time.Since(start)),
+		}
+		if err != nil {
+			name := ErrorName(err)
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+			if name != "" {
+				span.SetAttributes(attribute.String("dbus.error_name", name))
+				attrs = append(attrs, slog.String("error_name", name))
+			}
+			slog.WarnContext(ctx, "D-Bus call failed", append(attrs, slog.Any("error", err))...)
+		} else {
+			slog.DebugContext(ctx, "D-Bus call", attrs...)
+		}
+	}
+}
+
+// splitMember splits a fully qualified method name into interface and member
+func splitMember(method string) (string, string) {
+	dot := strings.LastIndex(method, ".")
+	if dot < 0 {
+		return "", method
+	}
+	return method[:dot], method[dot+1:]
+}
+
+// ErrorName returns the D-Bus error name of a failed call, if any
+func ErrorName(err error) string {
+	var dbusErr dbus.Error
+	if errors.As(err, &dbusErr) {
+		return dbusErr.Name
+	}
+	var dbusErrPtr *dbus.Error
+	if errors.As(err, &dbusErrPtr) {
+		return dbusErrPtr.Name
+	}
+	return ""
+}
+
+// ErrorClass groups D-Bus error names by cause, mapped by each API to its
+// status codes
+type ErrorClass int
+
+const (
+	// ErrorClassFailed is a failure of the service, or an unknown error name
+	ErrorClassFailed ErrorClass = iota
+	// ErrorClassNotFound is a missing name, object, interface or member
+	ErrorClassNotFound
+	// ErrorClassInvalid is a request rejected for its arguments
+	ErrorClassInvalid
+	// ErrorClassDenied is a request the caller is not allowed to make
+	ErrorClassDenied
+	// ErrorClassTimeout is a call left without reply
+	ErrorClassTimeout
+)
+
+// ClassifyError returns the class of a D-Bus error name
+func ClassifyError(name string) ErrorClass {
+	switch name {
+	case "org.freedesktop.DBus.Error.ServiceUnknown",
+		"org.freedesktop.DBus.Error.NameHasNoOwner",
+		"org.freedesktop.DBus.Error.UnknownObject",
+		"org.freedesktop.DBus.Error.UnknownInterface",
+		"org.freedesktop.DBus.Error.UnknownMethod",
+		"org.freedesktop.DBus.Error.UnknownProperty":
+		return ErrorClassNotFound
+	case "org.freedesktop.DBus.Error.InvalidArgs",
+		"org.freedesktop.DBus.Error.InvalidSignature",
+		"org.freedesktop.DBus.Error.MatchRuleInvalid",
+		"org.freedesktop.DBus.Error.PropertyReadOnly",
+		"org.freedesktop.DBus.Properties.Error.ReadOnly":
+		return ErrorClassInvalid
+	case "org.freedesktop.DBus.Error.AccessDenied",
+		"org.freedesktop.DBus.Error.AuthFailed":
+		return ErrorClassDenied
+	case "org.freedesktop.DBus.Error.NoReply",
+		"org.freedesktop.DBus.Error.Timeout",
+		"org.freedesktop.DBus.Error.TimedOut":
+		return ErrorClassTimeout
+	default:
+		return ErrorClassFailed
+	}
+}
+
 // ListServices returns all services on the specified bus
-func (s *DBusService) ListServices(busType string) ([]string, error) {
+func (s *DBusService) ListServices(ctx context.Context, busType string) ([]string, error) {
 	conn, err := s.getConnection(busType)
 	if err != nil {
 		return nil, err
 	}
 
 	var services []string
-	err = conn.BusObject().Call("org.freedesktop.DBus.ListNames", 0).Store(&services)
+	err = s.call(ctx, busType, conn.BusObject(), "org.freedesktop.DBus.ListNames", 0).Store(&services)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list services: %w", err)
 	}
@@ -100,27 +331,87 @@ func (s *DBusService) ListServices(busType string) ([]string, error) {
 	return services, nil
 }
 
-// GetServiceInfo returns detailed information about a service
-func (s *DBusService) GetServiceInfo(busType, serviceName string) (*model.ServiceInfo, error) {
+// ListNames returns the names owned on the specified bus and the activatable
+// names, started on demand by the bus, sorted by name
+func (s *DBusService) ListNames(ctx context.Context, busType string) ([]model.NameInfo, error) {
 	conn, err := s.getConnection(busType)
 	if err != nil {
 		return nil, err
 	}
 
+	var running, activatable []string
+	if err := s.call(ctx, busType, conn.BusObject(), "org.freedesktop.DBus.ListNames", 0).Store(&running); err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	if err := s.call(ctx, busType, conn.BusObject(), "org.freedesktop.DBus.ListActivatableNames", 0).Store(&activatable); err != nil {
+		return nil, fmt.Errorf("failed to list activatable services: %w", err)
+	}
+
+	names := make(map[string]*model.NameInfo, len(running)+len(activatable))
+	for _, name := range running {
+		names[name] = &model.NameInfo{Name: name, Running: true}
+	}
+	for _, name := range activatable {
+		if info, ok := names[name]; ok {
+			info.Activatable = true
+		} else {
+			names[name] = &model.NameInfo{Name: name, Activatable: true}
+		}
+	}
+
+	infos := make([]model.NameInfo, 0, len(names))
+	for _, info := range names {
+		infos = append(infos, *info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos, nil
+}
+
+// GetNameOwner returns the unique connection name owning a bus name
+func (s *DBusService) GetNameOwner(ctx context.Context, busType, name string) (string, error) {
+	conn, err := s.getConnection(busType)
+	if err != nil {
+		return "", err
+	}
+
+	var owner string
+	err = s.call(ctx, busType, conn.BusObject(), "org.freedesktop.DBus.GetNameOwner", 0, name).Store(&owner)
+	if err != nil {
+		return "", fmt.Errorf("failed to get owner of %s: %w", name, err)
+	}
+
+	return owner, nil
+}
+
+// GetServiceInfo returns detailed information about a service: its owner
+// with the credentials and other names of the owner connection, the owner
+// process and the interfaces of the root object
+func (s *DBusService) GetServiceInfo(ctx context.Context, busType, serviceName string) (*model.ServiceInfo, error) {
+	conn, err := s.getConnection(busType)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &model.ServiceInfo{Name: serviceName}
+
 	// Get service owner
 	var owner string
-	err = conn.BusObject().Call("org.freedesktop.DBus.GetNameOwner", 0, serviceName).Store(&owner)
+	err = s.call(ctx, busType, conn.BusObject(), "org.freedesktop.DBus.GetNameOwner", 0, serviceName).Store(&owner)
 	if err != nil {
-		owner = "unknown"
+		info.Owner = "unknown"
+	} else {
+		info.Owner = owner
+		info.Aliases, info.AliasesTruncated = s.nameAliases(ctx, busType, conn, serviceName, owner)
+		info.Credentials = s.connectionCredentials(ctx, busType, conn, owner)
+		if info.Credentials != nil && info.Credentials.ProcessID != nil && s.sharesProcessNamespace(ctx, busType, conn) {
+			info.Process = readProcess(*info.Credentials.ProcessID)
+		}
 	}
 
 	// Get introspection data
-	introspectionResult, err := s.IntrospectService(busType, serviceName)
+	introspectionResult, err := s.IntrospectService(ctx, busType, serviceName)
 	if err != nil {
-		return &model.ServiceInfo{
-			Name:  serviceName,
-			Owner: owner,
-		}, nil
+		return info, nil
 	}
 
 	interfaces := make([]string, 0)
@@ -130,40 +421,180 @@ func (s *DBusService) GetServiceInfo(busType, serviceName string) (*model.Servic
 		}
 	}
 
-	return &model.ServiceInfo{
-		Name:          serviceName,
-		Owner:         owner,
-		Interfaces:    interfaces,
-		ObjectPaths:   []string{"/"}, // Default object path
-		Introspection: introspectionResult,
-	}, nil
+	info.Interfaces = interfaces
+	info.ObjectPaths = []string{"/"} // Default object path
+	info.Introspection = introspectionResult
+	return info, nil
+}
+
+// aliasLookupTimeout bounds the owner lookups of nameAliases on busy buses
+const aliasLookupTimeout = 2 * time.Second
+
+// aliasLookupWorkers is the number of concurrent owner lookups of nameAliases
+const aliasLookupWorkers = 16
+
+// nameAliases returns the other well-known names owned by the owner of a name.
+// Every candidate name costs a GetNameOwner call; the calls run concurrently
+// within aliasLookupTimeout, and truncated reports that some of them did not
+// complete, so the aliases may be incomplete.
+func (s *DBusService) nameAliases(ctx context.Context, busType string, conn *dbus.Conn, serviceName, owner string) (aliases []string, truncated bool) {
+	var names []string
+	if err := s.call(ctx, busType, conn.BusObject(), "org.freedesktop.DBus.ListNames", 0).Store(&names); err != nil {
+		return nil, false
+	}
+
+	candidates := make([]string, 0, len(names))
+	for _, name := range names {
+		if name != serviceName && !strings.HasPrefix(name, ":") && name != "org.freedesktop.DBus" {
+			candidates = append(candidates, name)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, aliasLookupTimeout)
+	defer cancel()
+
+	resolved := make([]bool, len(candidates))
+	owned := make([]bool, len(candidates))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(aliasLookupWorkers, len(candidates)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				var nameOwner string
+				err := s.call(ctx, busType, conn.BusObject(), "org.freedesktop.DBus.GetNameOwner", 0, candidates[i]).Store(&nameOwner)
+				// Names may be released in the meantime
+				resolved[i] = err == nil || ctx.Err() == nil
+				owned[i] = err == nil && nameOwner == owner
+			}
+		}()
+	}
+feed:
+	for i := range candidates {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	for i, name := range candidates {
+		if owned[i] {
+			aliases = append(aliases, name)
+		}
+		if !resolved[i] {
+			truncated = true
+		}
+	}
+	if truncated {
+		slog.DebugContext(ctx, "Alias lookup truncated", "bus", busType, "service", serviceName, "names", len(names))
+	}
+	sort.Strings(aliases)
+	return aliases, truncated
+}
+
+// connectionCredentials returns the credentials of a connection, or nil when
+// the bus does not provide them
+func (s *DBusService) connectionCredentials(ctx context.Context, busType string, conn *dbus.Conn, name string) *model.ConnectionCredentials {
+	var values map[string]dbus.Variant
+	if err := s.call(ctx, busType, conn.BusObject(), "org.freedesktop.DBus.GetConnectionCredentials", 0, name).Store(&values); err != nil {
+		return nil
+	}
+
+	credentials := &model.ConnectionCredentials{}
+	if uid, ok := values["UnixUserID"].Value().(uint32); ok {
+		credentials.UnixUserID = &uid
+	}
+	if gids, ok := values["UnixGroupIDs"].Value().([]uint32); ok {
+		credentials.UnixGroupIDs = gids
+	}
+	if pid, ok := values["ProcessID"].Value().(uint32); ok {
+		credentials.ProcessID = &pid
+	}
+	if label, ok := values["LinuxSecurityLabel"].Value().([]byte); ok {
+		credentials.LinuxSecurityLabel = string(bytes.TrimRight(label, "\x00"))
+	}
+	return credentials
+}
+
+// sharesProcessNamespace reports whether the process IDs of the bus are those
+// of the controller, i.e. whether /proc describes the processes of the bus.
+// The namespace does not change, so it is checked once per bus.
+func (s *DBusService) sharesProcessNamespace(ctx context.Context, busType string, conn *dbus.Conn) bool {
+	namespace := &s.sessionNamespace
+	if busType == "system" {
+		namespace = &s.systemNamespace
+	}
+
+	namespace.once.Do(func() {
+		names := conn.Names()
+		if len(names) == 0 {
+			return
+		}
+		// The result outlives the request, so its cancellation must not decide it
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), healthCheckTimeout)
+		defer cancel()
+		credentials := s.connectionCredentials(ctx, busType, conn, names[0])
+		namespace.shared = credentials != nil && credentials.ProcessID != nil && int(*credentials.ProcessID) == os.Getpid()
+	})
+	return namespace.shared
 }
 
 // IntrospectService returns introspection data for a service
-func (s *DBusService) IntrospectService(busType, serviceName string) (*model.IntrospectionResult, error) {
+func (s *DBusService) IntrospectService(ctx context.Context, busType, serviceName string) (*model.IntrospectionResult, error) {
+	return s.IntrospectObject(ctx, busType, serviceName, "/")
+}
+
+// IntrospectObject returns introspection data for an object of a service
+func (s *DBusService) IntrospectObject(ctx context.Context, busType, serviceName, objectPath string) (*model.IntrospectionResult, error) {
+	ctx, span := tracer.Start(ctx, "IntrospectObject", trace.WithAttributes(
+		attribute.String("dbus.bus", busType),
+		attribute.String("dbus.destination", serviceName),
+		attribute.String("dbus.path", objectPath),
+	))
+	defer span.End()
+
 	conn, err := s.getConnection(busType)
 	if err != nil {
 		return nil, err
 	}
 
-	obj := conn.Object(serviceName, "/")
+	if !dbus.ObjectPath(objectPath).IsValid() {
+		return nil, fmt.Errorf("invalid object path: %s", objectPath)
+	}
+
+	obj := conn.Object(serviceName, dbus.ObjectPath(objectPath))
 	var xmlData string
 
-	err = obj.Call("org.freedesktop.DBus.Introspectable.Introspect", 0).Store(&xmlData)
+	err = s.call(ctx, busType, obj, "org.freedesktop.DBus.Introspectable.Introspect", 0).Store(&xmlData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to introspect service %s: %w", serviceName, err)
 	}
 
 	result := &model.IntrospectionResult{
 		Service:    serviceName,
-		ObjectPath: "/",
+		ObjectPath: objectPath,
 		XML:        xmlData,
 		Timestamp:  time.Now(),
 	}
 
 	// Parse the introspection XML
+	_, parseSpan := tracer.Start(ctx, "parseIntrospectionXML")
 	parsed, err := s.parseIntrospectionXML(xmlData)
+	if err != nil {
+		parseSpan.RecordError(err)
+	}
+	parseSpan.End()
 	if err == nil {
+		// Child node paths are relative to the introspected object
+		if objectPath != "/" {
+			for i := range parsed.Nodes {
+				parsed.Nodes[i].Path = objectPath + parsed.Nodes[i].Path
+			}
+		}
 		result.ParsedData = parsed
 	}
 
@@ -198,7 +629,7 @@ func (s *DBusService) parseIntrospectionXML(xmlData string) (*model.ParsedIntros
 				Name:        method.Name,
 				InArgs:      make([]model.ArgumentInfo, 0),
 				OutArgs:     make([]model.ArgumentInfo, 0),
-				Annotations: make(map[string]string),
+				Annotations: annotations(method.Annotations),
 			}
 
 			for _, arg := range method.Args {
@@ -223,7 +654,7 @@ func (s *DBusService) parseIntrospectionXML(xmlData string) (*model.ParsedIntros
 				Name:        prop.Name,
 				Type:        prop.Type,
 				Access:      prop.Access,
-				Annotations: make(map[string]string),
+				Annotations: annotations(prop.Annotations),
 			}
 			interfaceInfo.Properties = append(interfaceInfo.Properties, propInfo)
 		}
@@ -233,7 +664,7 @@ func (s *DBusService) parseIntrospectionXML(xmlData string) (*model.ParsedIntros
 			signalInfo := model.SignalInfo{
 				Name:        signal.Name,
 				Args:        make([]model.ArgumentInfo, 0, len(signal.Args)),
-				Annotations: make(map[string]string),
+				Annotations: annotations(signal.Annotations),
 			}
 
 			for _, arg := range signal.Args {
@@ -263,37 +694,53 @@ func (s *DBusService) parseIntrospectionXML(xmlData string) (*model.ParsedIntros
 	return parsed, nil
 }
 
-// ListInterfaces returns all interfaces for a service
-func (s *DBusService) ListInterfaces(busType, serviceName string) ([]string, error) {
-	serviceInfo, err := s.GetServiceInfo(busType, serviceName)
+// annotations maps the names of introspected annotations to their values
+func annotations(list []introspect.Annotation) map[string]string {
+	values := make(map[string]string, len(list))
+	for _, annotation := range list {
+		values[annotation.Name] = annotation.Value
+	}
+	return values
+}
+
+// ListInterfaces returns all interfaces of an object of a service
+func (s *DBusService) ListInterfaces(ctx context.Context, busType, serviceName, objectPath string) ([]string, error) {
+	result, err := s.IntrospectObject(ctx, busType, serviceName, objectPath)
 	if err != nil {
 		return nil, err
 	}
 
-	return serviceInfo.Interfaces, nil
+	interfaces := make([]string, 0)
+	if result.ParsedData != nil {
+		for _, iface := range result.ParsedData.Interfaces {
+			interfaces = append(interfaces, iface.Name)
+		}
+	}
+
+	return interfaces, nil
 }
 
-// GetInterfaceInfo returns detailed information about an interface
-func (s *DBusService) GetInterfaceInfo(busType, serviceName, interfaceName string) (*model.InterfaceInfo, error) {
-	serviceInfo, err := s.GetServiceInfo(busType, serviceName)
+// GetInterfaceInfo returns detailed information about an interface of an object
+func (s *DBusService) GetInterfaceInfo(ctx context.Context, busType, serviceName, objectPath, interfaceName string) (*model.InterfaceInfo, error) {
+	result, err := s.IntrospectObject(ctx, busType, serviceName, objectPath)
 	if err != nil {
 		return nil, err
 	}
 
-	if serviceInfo.Introspection != nil && serviceInfo.Introspection.ParsedData != nil {
-		for _, iface := range serviceInfo.Introspection.ParsedData.Interfaces {
+	if result.ParsedData != nil {
+		for _, iface := range result.ParsedData.Interfaces {
 			if iface.Name == interfaceName {
 				return &iface, nil
 			}
 		}
 	}
 
-	return nil, fmt.Errorf("interface %s not found", interfaceName)
+	return nil, fmt.Errorf("%w: %s on %s", ErrInterfaceNotFound, interfaceName, objectPath)
 }
 
 // ListMethods returns all methods for an interface
-func (s *DBusService) ListMethods(busType, serviceName, interfaceName string) ([]model.MethodInfo, error) {
-	interfaceInfo, err := s.GetInterfaceInfo(busType, serviceName, interfaceName)
+func (s *DBusService) ListMethods(ctx context.Context, busType, serviceName, objectPath, interfaceName string) ([]model.MethodInfo, error) {
+	interfaceInfo, err := s.GetInterfaceInfo(ctx, busType, serviceName, objectPath, interfaceName)
 	if err != nil {
 		return nil, err
 	}
@@ -302,22 +749,49 @@ func (s *DBusService) ListMethods(busType, serviceName, interfaceName string) ([
 }
 
 // CallMethod executes a D-Bus method call
-func (s *DBusService) CallMethod(busType, serviceName, interfaceName, methodName string, args []interface{}) (*model.MethodCallResult, error) {
+func (s *DBusService) CallMethod(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName string, args []interface{}) (*model.MethodCallResult, error) {
+	return s.CallMethodWithFlags(ctx, busType, serviceName, objectPath, interfaceName, methodName, model.CallFlags{}, args)
+}
+
+// CallMethodWithFlags executes a D-Bus method call with the requested header flags.
+// Methods annotated org.freedesktop.DBus.Method.NoReply are sent flagged
+// NoReplyExpected as well; the result of a call without reply only reports
+// whether it was sent.
+func (s *DBusService) CallMethodWithFlags(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName string, flags model.CallFlags, args []interface{}) (*model.MethodCallResult, error) {
+	ctx, span := tracer.Start(ctx, "CallMethod", trace.WithAttributes(
+		attribute.String("dbus.bus", busType),
+		attribute.String("dbus.destination", serviceName),
+		attribute.String("dbus.path", objectPath),
+		attribute.String("dbus.interface", interfaceName),
+		attribute.String("dbus.member", methodName),
+	))
+	defer span.End()
+
 	conn, err := s.getConnection(busType)
 	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 		return nil, err
 	}
 
-	obj := conn.Object(serviceName, "/")
-	call := obj.Call(interfaceName+"."+methodName, 0, args...)
+	obj := conn.Object(serviceName, dbus.ObjectPath(objectPath))
+	callFlags := s.callFlags(ctx, busType, obj, interfaceName, methodName, flags)
+	call := s.callWithFlags(ctx, busType, conn, obj, interfaceName+"."+methodName, callFlags, args)
 
 	result := &model.MethodCallResult{
+		NoReply:   callFlags&dbus.FlagNoReplyExpected != 0,
 		Timestamp: time.Now(),
 	}
 
 	if call.Err != nil {
 		result.Success = false
 		result.Error = call.Err.Error()
+		result.ErrorName = ErrorName(call.Err)
+		span.RecordError(call.Err)
+		span.SetStatus(codes.Error, call.Err.Error())
+		if result.ErrorName != "" {
+			span.SetAttributes(attribute.String("dbus.error_name", result.ErrorName))
+		}
 	} else {
 		result.Success = true
 		result.ReturnValues = call.Body
@@ -326,16 +800,348 @@ func (s *DBusService) CallMethod(busType, serviceName, interfaceName, methodName
 	return result, nil
 }
 
+// noReplyAnnotation marks the methods that never reply
+const noReplyAnnotation = "org.freedesktop.DBus.Method.NoReply"
+
+// callFlags returns the header flags of a method call, adding NoReplyExpected
+// for methods annotated org.freedesktop.DBus.Method.NoReply. The annotation is
+// looked up by introspecting the object once per interface, and the object is
+// not activated for the lookup when NoAutoStart is requested. Objects that fail
+// to introspect are remembered as having no such methods. The lookups of a
+// destination are forgotten when its name changes owner.
+func (s *DBusService) callFlags(ctx context.Context, busType string, obj dbus.BusObject, interfaceName, methodName string, flags model.CallFlags) dbus.Flags {
+	var callFlags dbus.Flags
+	if flags.NoAutoStart {
+		callFlags |= dbus.FlagNoAutoStart
+	}
+	if flags.AllowInteractiveAuthorization {
+		callFlags |= dbus.FlagAllowInteractiveAuthorization
+	}
+	if flags.NoReply {
+		return callFlags | dbus.FlagNoReplyExpected
+	}
+	// The standard interfaces have no such methods
+	if strings.HasPrefix(interfaceName, "org.freedesktop.DBus") {
+		return callFlags
+	}
+
+	key := noReplyKey{busType, obj.Destination(), obj.Path(), interfaceName}
+	methods, ok := s.noReply.get(key)
+	if !ok {
+		// Watched before introspecting, so an owner change in between evicts the lookup
+		cached := s.watchNoReplyOwners(ctx, busType)
+
+		var err error
+		methods, err = s.introspectNoReply(ctx, busType, obj, interfaceName, callFlags&dbus.FlagNoAutoStart)
+		// Calls cut short before a reply say nothing about the object
+		if err != nil && ErrorName(err) == "" && !errors.Is(err, errInvalidIntrospection) {
+			return callFlags
+		}
+		if cached {
+			s.noReply.put(key, methods)
+		}
+	}
+	if methods[methodName] {
+		callFlags |= dbus.FlagNoReplyExpected
+	}
+
+	return callFlags
+}
+
+// noReplyOwnersBuffer is the number of owner changes queued for the NoReply
+// cache of a bus. Changes arriving while it is full are missed, leaving the
+// lookups of their names in place.
+const noReplyOwnersBuffer = 256
+
+// watchNoReplyOwners makes sure the NoReply lookups of a bus are evicted when
+// their destination changes owner, reporting whether they are. The owner
+// changes are watched from the first lookup until the bus goes away.
+func (s *DBusService) watchNoReplyOwners(ctx context.Context, busType string) bool {
+	if s.noReply.watched(busType) {
+		return true
+	}
+
+	match := SignalMatch{Sender: busName, Interface: busName, Member: "NameOwnerChanged"}
+	watch, err := s.WatchSignals(ctx, busType, match, noReplyOwnersBuffer)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to watch name owners for the NoReply cache", "bus", busType, "error", err)
+		return false
+	}
+	if !s.noReply.watch(busType, watch) {
+		// Another lookup started watching in the meantime
+		watch.Close()
+		return true
+	}
+
+	go func() {
+		for sig := range watch.C {
+			if len(sig.Body) != 3 {
+				continue
+			}
+			if name, ok := sig.Body[0].(string); ok {
+				s.noReply.evict(busType, name)
+			}
+		}
+		s.noReply.unwatch(busType, watch)
+	}()
+	return true
+}
+
+// errInvalidIntrospection is returned for introspection data that does not parse
+var errInvalidIntrospection = errors.New("invalid introspection data")
+
+// introspectNoReply introspects an object and returns the NoReply methods of the interface
+func (s *DBusService) introspectNoReply(ctx context.Context, busType string, obj dbus.BusObject, interfaceName string, flags dbus.Flags) (map[string]bool, error) {
+	var xmlData string
+	if err := s.call(ctx, busType, obj, "org.freedesktop.DBus.Introspectable.Introspect", flags).Store(&xmlData); err != nil {
+		return nil, err
+	}
+	var node introspect.Node
+	if err := xml.Unmarshal([]byte(xmlData), &node); err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidIntrospection, err)
+	}
+	return noReplyMethods(node, interfaceName), nil
+}
+
+// noReplyMethods returns the methods of the interface annotated NoReply
+func noReplyMethods(node introspect.Node, interfaceName string) map[string]bool {
+	methods := make(map[string]bool)
+	for _, iface := range node.Interfaces {
+		if iface.Name != interfaceName {
+			continue
+		}
+		for _, method := range iface.Methods {
+			if annotations(method.Annotations)[noReplyAnnotation] == "true" {
+				methods[method.Name] = true
+			}
+		}
+	}
+	return methods
+}
+
+// noReplyCacheSize bounds the interfaces whose NoReply methods are cached
+const noReplyCacheSize = 1024
+
+// noReplyKey identifies an interface of an object on a bus
+type noReplyKey struct {
+	busType       string
+	service       string
+	path          dbus.ObjectPath
+	interfaceName string
+}
+
+// noReplyCache keeps the NoReply methods of the interfaces already called.
+// It is emptied when full rather than tracking the least used interfaces.
+// Entries are only kept for the buses whose name owner changes are watched.
+type noReplyCache struct {
+	mu      sync.Mutex
+	entries map[noReplyKey]map[string]bool
+	watches map[string]*SignalWatch // Name owner changes evicting the entries of each bus
+}
+
+func (c *noReplyCache) get(key noReplyKey) (map[string]bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	methods, ok := c.entries[key]
+	return methods, ok
+}
+
+func (c *noReplyCache) put(key noReplyKey, methods map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.watches[key.busType] == nil {
+		return
+	}
+	if c.entries == nil || len(c.entries) >= noReplyCacheSize {
+		c.entries = make(map[noReplyKey]map[string]bool)
+	}
+	c.entries[key] = methods
+}
+
+// watched reports whether the name owner changes of the bus are watched
+func (c *noReplyCache) watched(busType string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.watches[busType] != nil
+}
+
+// watch records the watch of a bus, returning false when it already has one
+func (c *noReplyCache) watch(busType string, watch *SignalWatch) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.watches[busType] != nil {
+		return false
+	}
+	if c.watches == nil {
+		c.watches = make(map[string]*SignalWatch)
+	}
+	c.watches[busType] = watch
+	return true
+}
+
+// unwatch forgets the ended watch of a bus along with the entries it kept current
+func (c *noReplyCache) unwatch(busType string, watch *SignalWatch) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.watches[busType] != watch {
+		return
+	}
+	delete(c.watches, busType)
+	for key := range c.entries {
+		if key.busType == busType {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// evict removes the entries of a name whose owner changed
+func (c *noReplyCache) evict(busType, name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key := range c.entries {
+		if key.busType == busType && key.service == name {
+			delete(c.entries, key)
+		}
+	}
+}
+
+// callWithFlags invokes a D-Bus method like call, keeping every header flag
+func (s *DBusService) callWithFlags(ctx context.Context, busType string, conn *dbus.Conn, obj dbus.BusObject, method string, flags dbus.Flags, args []interface{}) *dbus.Call {
+	ctx, end := s.startCall(ctx, busType, obj, method)
+	call := <-goCall(ctx, conn, obj, method, flags, make(chan *dbus.Call, 1), args).Done
+	end(call.Err)
+	return call
+}
+
+// goCall starts a method call; ch receives it once done. BusObject masks the
+// AllowInteractiveAuthorization flag, so calls carrying it are sent as a
+// message built here.
+func goCall(ctx context.Context, conn *dbus.Conn, obj dbus.BusObject, method string, flags dbus.Flags, ch chan *dbus.Call, args []interface{}) *dbus.Call {
+	if flags&dbus.FlagAllowInteractiveAuthorization == 0 {
+		return obj.GoWithContext(ctx, method, flags, ch, args...)
+	}
+
+	iface, member := splitMember(method)
+	msg := &dbus.Message{
+		Type:  dbus.TypeMethodCall,
+		Flags: flags,
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldPath:        dbus.MakeVariant(obj.Path()),
+			dbus.FieldDestination: dbus.MakeVariant(obj.Destination()),
+			dbus.FieldMember:      dbus.MakeVariant(member),
+		},
+		Body: args,
+	}
+	if iface != "" {
+		msg.Headers[dbus.FieldInterface] = dbus.MakeVariant(iface)
+	}
+	if len(args) > 0 {
+		msg.Headers[dbus.FieldSignature] = dbus.MakeVariant(dbus.SignatureOf(args...))
+	}
+	return conn.SendWithContext(ctx, msg, ch)
+}
+
+// CallMethodNoReply sends a method call flagged NoReplyExpected, without waiting for a reply
+func (s *DBusService) CallMethodNoReply(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName string, args []interface{}) error {
+	result, err := s.CallMethodWithFlags(ctx, busType, serviceName, objectPath, interfaceName, methodName, model.CallFlags{NoReply: true}, args)
+	if err != nil {
+		return err
+	}
+	if !result.Success {
+		return errors.New(result.Error)
+	}
+	return nil
+}
+
+// EmitSignal emits a signal from the connection of the controller. The signal
+// is broadcast, or unicast to destination when it is not empty.
+func (s *DBusService) EmitSignal(ctx context.Context, busType, destination, objectPath, interfaceName, signalName string, args []interface{}) error {
+	ctx, span := tracer.Start(ctx, "EmitSignal", trace.WithAttributes(
+		attribute.String("dbus.bus", busType),
+		attribute.String("dbus.destination", destination),
+		attribute.String("dbus.path", objectPath),
+		attribute.String("dbus.interface", interfaceName),
+		attribute.String("dbus.member", signalName),
+	))
+	defer span.End()
+
+	conn, err := s.getConnection(busType)
+	if err != nil {
+		return err
+	}
+
+	// The bus drops connections sending malformed destinations
+	if destination != "" && !validBusName(destination) {
+		return fmt.Errorf("%w: invalid destination %q", ErrInvalidSignal, destination)
+	}
+
+	msg := &dbus.Message{
+		Type: dbus.TypeSignal,
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldPath:      dbus.MakeVariant(dbus.ObjectPath(objectPath)),
+			dbus.FieldInterface: dbus.MakeVariant(interfaceName),
+			dbus.FieldMember:    dbus.MakeVariant(signalName),
+		},
+		Body: args,
+	}
+	if destination != "" {
+		msg.Headers[dbus.FieldDestination] = dbus.MakeVariant(destination)
+	}
+	if len(args) > 0 {
+		msg.Headers[dbus.FieldSignature] = dbus.MakeVariant(dbus.SignatureOf(args...))
+	}
+	if err := msg.IsValid(); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidSignal, err)
+	}
+
+	if err := conn.SendWithContext(ctx, msg, nil).Err; err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return fmt.Errorf("failed to emit signal: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Signal emitted", "bus", busType, "destination", destination, "path", objectPath,
+		"interface", interfaceName, "signal", signalName, "args", len(args))
+
+	return nil
+}
+
+// validBusName reports whether name is a valid unique or well-known bus name
+func validBusName(name string) bool {
+	unique := strings.HasPrefix(name, ":")
+	elements := strings.Split(strings.TrimPrefix(name, ":"), ".")
+	if len(name) > 255 || len(elements) < 2 {
+		return false
+	}
+	for _, element := range elements {
+		if element == "" || (!unique && element[0] >= '0' && element[0] <= '9') {
+			return false
+		}
+		for _, r := range element {
+			if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // ListProperties returns all properties for an interface
-func (s *DBusService) ListProperties(busType, serviceName, interfaceName string) ([]model.PropertyInfo, error) {
-	interfaceInfo, err := s.GetInterfaceInfo(busType, serviceName, interfaceName)
+func (s *DBusService) ListProperties(ctx context.Context, busType, serviceName, objectPath, interfaceName string) ([]model.PropertyInfo, error) {
+	interfaceInfo, err := s.GetInterfaceInfo(ctx, busType, serviceName, objectPath, interfaceName)
 	if err != nil {
 		return nil, err
 	}
 
 	// Try to get actual property values
 	for i := range interfaceInfo.Properties {
-		if value, err := s.GetProperty(busType, serviceName, interfaceName, interfaceInfo.Properties[i].Name); err == nil {
+		if value, err := s.GetProperty(ctx, busType, serviceName, objectPath, interfaceName, interfaceInfo.Properties[i].Name); err == nil {
 			interfaceInfo.Properties[i].Value = value.Value
 		}
 	}
@@ -344,14 +1150,15 @@ func (s *DBusService) ListProperties(busType, serviceName, interfaceName string)
 }
 
 // GetProperty returns the value of a specific property
-func (s *DBusService) GetProperty(busType, serviceName, interfaceName, propertyName string) (*model.PropertyValue, error) {
+func (s *DBusService) GetProperty(ctx context.Context, busType, serviceName, objectPath, interfaceName, propertyName string) (*model.PropertyValue, error) {
 	conn, err := s.getConnection(busType)
 	if err != nil {
 		return nil, err
 	}
 
-	obj := conn.Object(serviceName, "/")
-	variant, err := obj.GetProperty(interfaceName + "." + propertyName)
+	var variant dbus.Variant
+	obj := conn.Object(serviceName, dbus.ObjectPath(objectPath))
+	err = s.call(ctx, busType, obj, "org.freedesktop.DBus.Properties.Get", 0, interfaceName, propertyName).Store(&variant)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get property %s: %w", propertyName, err)
 	}
@@ -364,76 +1171,57 @@ func (s *DBusService) GetProperty(busType, serviceName, interfaceName, propertyN
 	}, nil
 }
 
-// SetProperty sets the value of a specific property
-func (s *DBusService) SetProperty(busType, serviceName, interfaceName, propertyName string, value interface{}) (*model.PropertyValue, error) {
+// GetAllProperties returns the values of all properties of an object interface
+func (s *DBusService) GetAllProperties(ctx context.Context, busType, serviceName, objectPath, interfaceName string) (map[string]interface{}, error) {
 	conn, err := s.getConnection(busType)
 	if err != nil {
 		return nil, err
 	}
 
-	obj := conn.Object(serviceName, "/")
-	err = obj.SetProperty(interfaceName+"."+propertyName, dbus.MakeVariant(value))
+	var values map[string]dbus.Variant
+	obj := conn.Object(serviceName, dbus.ObjectPath(objectPath))
+	err = s.call(ctx, busType, obj, "org.freedesktop.DBus.Properties.GetAll", 0, interfaceName).Store(&values)
 	if err != nil {
-		return nil, fmt.Errorf("failed to set property %s: %w", propertyName, err)
+		return nil, fmt.Errorf("failed to get properties of %s: %w", interfaceName, err)
 	}
 
-	// Return the updated property value
-	return s.GetProperty(busType, serviceName, interfaceName, propertyName)
-}
-
-// ListSignals returns all signals for an interface
-func (s *DBusService) ListSignals(busType, serviceName, interfaceName string) ([]model.SignalInfo, error) {
-	interfaceInfo, err := s.GetInterfaceInfo(busType, serviceName, interfaceName)
-	if err != nil {
-		return nil, err
+	properties := make(map[string]interface{}, len(values))
+	for name, variant := range values {
+		properties[name] = variant.Value()
 	}
 
-	return interfaceInfo.Signals, nil
+	return properties, nil
 }
 
-// SubscribeToSignal subscribes to a D-Bus signal
-func (s *DBusService) SubscribeToSignal(busType, serviceName, interfaceName, signalName string) (*model.SignalSubscription, error) {
+// SetProperty sets the value of a specific property
+func (s *DBusService) SetProperty(ctx context.Context, busType, serviceName, objectPath, interfaceName, propertyName string, value interface{}) (*model.PropertyValue, error) {
 	conn, err := s.getConnection(busType)
 	if err != nil {
 		return nil, err
 	}
 
-	// Create a unique subscription ID
-	subscriptionID := fmt.Sprintf("%s:%s:%s:%s", busType, serviceName, interfaceName, signalName)
-
-	// Create signal handler
-	handler := &SignalHandler{
-		channel: make(chan *dbus.Signal, 100),
-		active:  true,
+	// Values parsed from variant notation already carry their signature
+	variant, ok := value.(dbus.Variant)
+	if !ok {
+		variant = dbus.MakeVariant(value)
 	}
 
-	// Add match rule
-	matchRule := fmt.Sprintf("type='signal',interface='%s',member='%s'", interfaceName, signalName)
-	if serviceName != "" {
-		matchRule += fmt.Sprintf(",sender='%s'", serviceName)
-	}
-
-	err = conn.BusObject().Call("org.freedesktop.DBus.AddMatch", 0, matchRule).Err
+	obj := conn.Object(serviceName, dbus.ObjectPath(objectPath))
+	err = s.call(ctx, busType, obj, "org.freedesktop.DBus.Properties.Set", 0, interfaceName, propertyName, variant).Err
 	if err != nil {
-		return nil, fmt.Errorf("failed to add match rule: %w", err)
+		return nil, fmt.Errorf("failed to set property %s: %w", propertyName, err)
 	}
 
-	// Register signal handler
-	conn.Signal(handler.channel)
-
-	s.mutex.Lock()
-	s.subscriptions[subscriptionID] = handler
-	s.mutex.Unlock()
+	// Return the updated property value
+	return s.GetProperty(ctx, busType, serviceName, objectPath, interfaceName, propertyName)
+}
 
-	subscription := &model.SignalSubscription{
-		ID:        subscriptionID,
-		BusType:   busType,
-		Service:   serviceName,
-		Interface: interfaceName,
-		Signal:    signalName,
-		Active:    true,
-		CreatedAt: time.Now(),
+// ListSignals returns all signals for an interface
+func (s *DBusService) ListSignals(ctx context.Context, busType, serviceName, objectPath, interfaceName string) ([]model.SignalInfo, error) {
+	interfaceInfo, err := s.GetInterfaceInfo(ctx, busType, serviceName, objectPath, interfaceName)
+	if err != nil {
+		return nil, err
 	}
 
-	return subscription, nil
+	return interfaceInfo.Signals, nil
 }
@@ -1,13 +1,26 @@
 package service
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
 	"testing"
 	"time"
 
+	"github.com/godbus/dbus/v5"
+	"github.com/godbus/dbus/v5/introspect"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
-	"github.com/mesbrj/dbus-controller/internal/model"
+	"github.com/mesbrj/dbus-controller/pkg/model"
 )
 
 // DBusServiceTestSuite defines a test suite for D-Bus service tests
@@ -126,6 +139,26 @@ func (suite *DBusServiceTestSuite) TestParseIntrospectionXML() {
 	assert.Equal(suite.T(), "/org", parsed.Nodes[0].Path)
 }
 
+func (suite *DBusServiceTestSuite) TestParseIntrospectionXML_Annotations() {
+	xmlData := `<node>
+  <interface name="com.example.Logger">
+    <method name="Log">
+      <arg direction="in" type="s" name="line"/>
+      <annotation name="org.freedesktop.DBus.Method.NoReply" value="true"/>
+    </method>
+    <property name="Level" type="u" access="read">
+      <annotation name="org.freedesktop.DBus.Property.EmitsChangedSignal" value="const"/>
+    </property>
+  </interface>
+</node>`
+
+	parsed, err := suite.service.parseIntrospectionXML(xmlData)
+
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), map[string]string{"org.freedesktop.DBus.Method.NoReply": "true"}, parsed.Interfaces[0].Methods[0].Annotations)
+	assert.Equal(suite.T(), "const", parsed.Interfaces[0].Properties[0].Annotations["org.freedesktop.DBus.Property.EmitsChangedSignal"])
+}
+
 func (suite *DBusServiceTestSuite) TestParseIntrospectionXML_InvalidXML() {
 	invalidXML := "not valid xml"
 
@@ -180,7 +213,7 @@ func TestDBusService_Integration_ListServices(t *testing.T) {
 	defer service.Close()
 
 	// This test would only pass if system D-Bus is available
-	services, err := service.ListServices("system")
+	services, err := service.ListServices(context.Background(), "system")
 	if err != nil {
 		t.Logf("System D-Bus not available: %v", err)
 		return
@@ -191,6 +224,513 @@ func TestDBusService_Integration_ListServices(t *testing.T) {
 	assert.Contains(t, services, "org.freedesktop.DBus")
 }
 
+func TestDBusService_Integration_CallMethodWithFlags(t *testing.T) {
+	service := NewDBusService()
+	defer service.Close()
+	if _, err := service.getConnection("session"); err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+	ctx := context.Background()
+
+	// Sent as a message built by hand, keeping the flag
+	result, err := service.CallMethodWithFlags(ctx, "session", "org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "GetId",
+		model.CallFlags{AllowInteractiveAuthorization: true, NoAutoStart: true}, nil)
+	require.NoError(t, err)
+	assert.True(t, result.Success, result.Error)
+	assert.Len(t, result.ReturnValues, 1)
+	assert.False(t, result.NoReply)
+
+	result, err = service.CallMethodWithFlags(ctx, "session", "org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "GetId",
+		model.CallFlags{NoReply: true}, nil)
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.True(t, result.NoReply)
+	assert.Empty(t, result.ReturnValues)
+}
+
+func TestDBusService_Integration_CallMethodSpanError(t *testing.T) {
+	service := NewDBusService()
+	defer service.Close()
+	if _, err := service.getConnection("session"); err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	result, err := service.CallMethodWithFlags(context.Background(), "session", "org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "NoSuchMethod",
+		model.CallFlags{}, nil)
+	require.NoError(t, err)
+	assert.False(t, result.Success)
+
+	var parent sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "CallMethod" {
+			parent = span
+		}
+	}
+	require.NotNil(t, parent)
+	assert.Equal(t, codes.Error, parent.Status().Code)
+	assert.Contains(t, parent.Attributes(), attribute.String("dbus.error_name", "org.freedesktop.DBus.Error.UnknownMethod"))
+}
+
+// logger exports a method annotated NoReply
+type logger struct{ lines chan string }
+
+func (l logger) Log(line string) *dbus.Error {
+	l.lines <- line
+	return nil
+}
+
+func TestDBusService_Integration_CallMethodNoReply(t *testing.T) {
+	service := NewDBusService()
+	defer service.Close()
+	if _, err := service.getConnection("session"); err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+
+	exporter, err := dbus.ConnectSessionBus()
+	require.NoError(t, err)
+	defer exporter.Close()
+	l := logger{lines: make(chan string, 1)}
+	require.NoError(t, exporter.Export(l, "/com/example/Logger", "com.example.Logger"))
+
+	err = service.CallMethodNoReply(context.Background(), "session", exporter.Names()[0], "/com/example/Logger", "com.example.Logger", "Log",
+		[]interface{}{"sent"})
+
+	require.NoError(t, err)
+	select {
+	case line := <-l.lines:
+		assert.Equal(t, "sent", line)
+	case <-time.After(5 * time.Second):
+		t.Fatal("call was not delivered")
+	}
+
+	err = service.CallMethodNoReply(context.Background(), "user", exporter.Names()[0], "/com/example/Logger", "com.example.Logger", "Log", nil)
+	assert.Error(t, err)
+}
+
+func TestDBusService_Integration_NoReplyAnnotation(t *testing.T) {
+	service := NewDBusService()
+	defer service.Close()
+	if _, err := service.getConnection("session"); err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+
+	exporter, err := dbus.ConnectSessionBus()
+	require.NoError(t, err)
+	defer exporter.Close()
+	l := logger{lines: make(chan string, 1)}
+	require.NoError(t, exporter.Export(l, "/com/example/Logger", "com.example.Logger"))
+	require.NoError(t, exporter.Export(introspect.Introspectable(`<node>
+  <interface name="com.example.Logger">
+    <method name="Log">
+      <arg direction="in" type="s" name="line"/>
+      <annotation name="org.freedesktop.DBus.Method.NoReply" value="true"/>
+    </method>
+  </interface>
+</node>`), "/com/example/Logger", "org.freedesktop.DBus.Introspectable"))
+
+	result, err := service.CallMethodWithFlags(context.Background(), "session", exporter.Names()[0], "/com/example/Logger", "com.example.Logger", "Log",
+		model.CallFlags{}, []interface{}{"started"})
+
+	require.NoError(t, err)
+	assert.True(t, result.Success)
+	assert.True(t, result.NoReply)
+	select {
+	case line := <-l.lines:
+		assert.Equal(t, "started", line)
+	case <-time.After(5 * time.Second):
+		t.Fatal("call was not delivered")
+	}
+
+	// The annotation is introspected once per interface
+	methods, ok := service.noReply.get(noReplyKey{"session", exporter.Names()[0], "/com/example/Logger", "com.example.Logger"})
+	require.True(t, ok)
+	assert.Equal(t, map[string]bool{"Log": true}, methods)
+
+	require.NoError(t, exporter.Export(nil, "/com/example/Logger", "org.freedesktop.DBus.Introspectable"))
+	result, err = service.CallMethodWithFlags(context.Background(), "session", exporter.Names()[0], "/com/example/Logger", "com.example.Logger", "Log",
+		model.CallFlags{}, []interface{}{"again"})
+	require.NoError(t, err)
+	assert.True(t, result.NoReply)
+	select {
+	case line := <-l.lines:
+		assert.Equal(t, "again", line)
+	case <-time.After(5 * time.Second):
+		t.Fatal("call was not delivered")
+	}
+}
+
+func TestDBusService_Integration_NoReplyAnnotation_Owner(t *testing.T) {
+	service := NewDBusService()
+	defer service.Close()
+	if _, err := service.getConnection("session"); err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+
+	exporter, err := dbus.ConnectSessionBus()
+	require.NoError(t, err)
+	defer exporter.Close()
+	reply, err := exporter.RequestName("com.example.NoReplyTest", dbus.NameFlagDoNotQueue)
+	require.NoError(t, err)
+	require.Equal(t, dbus.RequestNameReplyPrimaryOwner, reply)
+
+	// The object does not implement Introspectable
+	l := logger{lines: make(chan string, 2)}
+	require.NoError(t, exporter.Export(l, "/com/example/Logger", "com.example.Logger"))
+
+	for _, line := range []string{"first", "second"} {
+		result, err := service.CallMethodWithFlags(context.Background(), "session", "com.example.NoReplyTest", "/com/example/Logger", "com.example.Logger", "Log",
+			model.CallFlags{}, []interface{}{line})
+		require.NoError(t, err)
+		assert.True(t, result.Success)
+		assert.False(t, result.NoReply)
+		assert.Equal(t, line, <-l.lines)
+	}
+
+	// The failed lookup is remembered until the name changes owner
+	key := noReplyKey{"session", "com.example.NoReplyTest", "/com/example/Logger", "com.example.Logger"}
+	methods, ok := service.noReply.get(key)
+	require.True(t, ok)
+	assert.Empty(t, methods)
+
+	_, err = exporter.ReleaseName("com.example.NoReplyTest")
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool {
+		_, ok := service.noReply.get(key)
+		return !ok
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestDBusService_Integration_EmitSignal(t *testing.T) {
+	service := NewDBusService()
+	defer service.Close()
+	if _, err := service.getConnection("session"); err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+
+	listener, err := dbus.ConnectSessionBus()
+	require.NoError(t, err)
+	defer listener.Close()
+	signals := make(chan *dbus.Signal, 1)
+	listener.Signal(signals)
+
+	// Unicast signals reach the destination without a match rule
+	err = service.EmitSignal(context.Background(), "session", listener.Names()[0], "/com/example/Door", "com.example.Door", "Opened", []interface{}{"front", uint32(3)})
+	require.NoError(t, err)
+
+	// The bus may still be delivering NameAcquired to the listener
+	timeout := time.After(5 * time.Second)
+	for received := false; !received; {
+		select {
+		case sig := <-signals:
+			if sig.Sender == "org.freedesktop.DBus" {
+				continue
+			}
+			assert.Equal(t, dbus.ObjectPath("/com/example/Door"), sig.Path)
+			assert.Equal(t, "com.example.Door.Opened", sig.Name)
+			assert.Equal(t, []interface{}{"front", uint32(3)}, sig.Body)
+			received = true
+		case <-timeout:
+			t.Fatal("signal was not delivered")
+		}
+	}
+
+	err = service.EmitSignal(context.Background(), "session", "", "/com/example/Door", "com..Door", "Opened", nil)
+	assert.ErrorIs(t, err, ErrInvalidSignal)
+	err = service.EmitSignal(context.Background(), "session", "com.example.", "/", "com.example.Door", "Opened", nil)
+	assert.ErrorIs(t, err, ErrInvalidSignal)
+}
+
+func TestDBusService_Integration_Monitor(t *testing.T) {
+	service := NewDBusService()
+	defer service.Close()
+	if _, err := service.getConnection("session"); err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+	service.SetMonitorLimits(MonitorLimits{MaxSessions: 1, Buffer: 16})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	messages, err := service.Monitor(ctx, "session", []string{"type='signal',interface='com.example.MonitorTest'"})
+	require.NoError(t, err)
+	_, err = service.Monitor(ctx, "session", nil)
+	assert.ErrorIs(t, err, ErrTooManyMonitors)
+
+	err = service.EmitSignal(ctx, "session", "", "/com/example/MonitorTest", "com.example.MonitorTest", "Changed", []interface{}{dbus.MakeVariant(int32(-1))})
+	require.NoError(t, err)
+
+	select {
+	case message := <-messages:
+		assert.Equal(t, "signal", message.Type)
+		assert.Equal(t, "/com/example/MonitorTest", message.Path)
+		assert.Equal(t, "Changed", message.Member)
+		assert.Equal(t, []interface{}{map[string]any{"signature": "i", "value": json.Number("-1")}}, message.Body)
+	case <-ctx.Done():
+		t.Fatal("signal was not monitored")
+	}
+
+	// Closing the stream frees the session
+	service.CloseStreams()
+	for range messages {
+	}
+	require.Eventually(t, func() bool {
+		service.mutex.RLock()
+		defer service.mutex.RUnlock()
+		return service.openMonitors == 0
+	}, time.Second, 10*time.Millisecond)
+
+	_, err = service.Monitor(ctx, "session", []string{"type='nonsense'"})
+	assert.Equal(t, "org.freedesktop.DBus.Error.MatchRuleInvalid", ErrorName(err))
+}
+
+func TestDBusService_Integration_Capture(t *testing.T) {
+	service := NewDBusService()
+	defer service.Close()
+	if _, err := service.getConnection("session"); err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+	ctx := context.Background()
+
+	go func() {
+		// Emitted once the capture is running
+		time.Sleep(100 * time.Millisecond)
+		_ = service.EmitSignal(ctx, "session", "", "/com/example/CaptureTest", "com.example.CaptureTest", "Changed", []interface{}{uint32(7)})
+	}()
+
+	var out bytes.Buffer
+	stats, err := service.Capture(ctx, "session", []string{"type='signal',interface='com.example.CaptureTest'"}, 500*time.Millisecond, &out)
+	require.NoError(t, err)
+	assert.Equal(t, &model.CaptureStats{Messages: 1}, stats)
+
+	linkType, records := readPcap(t, out.Bytes())
+	assert.Equal(t, uint32(pcapLinkTypeBus), linkType)
+	require.Len(t, records, 1)
+	msg, err := dbus.DecodeMessage(bytes.NewReader(records[0]))
+	require.NoError(t, err)
+	assert.Equal(t, "Changed", msg.Headers[dbus.FieldMember].Value())
+	assert.Equal(t, []interface{}{uint32(7)}, msg.Body)
+
+	// The session is freed with the capture
+	service.mutex.RLock()
+	assert.Equal(t, 0, service.openMonitors)
+	service.mutex.RUnlock()
+}
+
+// slowWriter takes delay for every write
+type slowWriter struct {
+	bytes.Buffer
+	delay time.Duration
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	time.Sleep(w.delay)
+	return w.Buffer.Write(p)
+}
+
+func TestDBusService_Integration_CaptureDropped(t *testing.T) {
+	service := NewDBusService()
+	defer service.Close()
+	if _, err := service.getConnection("session"); err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+	service.SetMonitorLimits(MonitorLimits{MaxSessions: 1, Buffer: 1})
+	ctx := context.Background()
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		for i := 0; i < 20; i++ {
+			_ = service.EmitSignal(ctx, "session", "", "/com/example/CaptureTest", "com.example.CaptureTest", "Dropped", []interface{}{uint32(i)})
+		}
+	}()
+
+	out := &slowWriter{delay: 20 * time.Millisecond}
+	stats, err := service.Capture(ctx, "session", []string{"type='signal',interface='com.example.CaptureTest'"}, 500*time.Millisecond, out)
+	require.NoError(t, err)
+
+	// Every message is either written or counted as dropped
+	assert.Positive(t, stats.Dropped)
+	assert.Equal(t, 20, stats.Messages+int(stats.Dropped))
+	_, records := readPcap(t, out.Bytes())
+	assert.Len(t, records, stats.Messages)
+}
+
+func TestDBusService_Integration_Activation(t *testing.T) {
+	service := NewDBusService()
+	defer service.Close()
+	if _, err := service.getConnection("session"); err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+	ctx := context.Background()
+
+	names, err := service.ListNames(ctx, "session")
+	require.NoError(t, err)
+	assert.Contains(t, names, model.NameInfo{Name: "org.freedesktop.DBus", Running: true, Activatable: true})
+
+	// Only names provided by .service files can be started
+	_, err = service.StartService(ctx, "session", "com.example.NotActivatable")
+	assert.Equal(t, "org.freedesktop.DBus.Error.ServiceUnknown", ErrorName(err))
+
+	assert.NoError(t, service.UpdateActivationEnvironment(ctx, "session", map[string]string{"DBUS_CONTROLLER_TEST": "1"}))
+}
+
+func TestDBusService_Integration_Names(t *testing.T) {
+	service := NewDBusService()
+	defer service.Close()
+	conn, err := service.getConnection("session")
+	if err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+	ctx := context.Background()
+	self := conn.Names()[0]
+
+	other, err := dbus.ConnectSessionBus()
+	require.NoError(t, err)
+	defer other.Close()
+	_, err = other.RequestName("com.example.NameTest", dbus.NameFlagAllowReplacement)
+	require.NoError(t, err)
+
+	// Queued behind the owner, then taking the name over
+	ownership, err := service.RequestName(ctx, "session", "com.example.NameTest", model.NameFlags{})
+	require.NoError(t, err)
+	assert.Equal(t, "in_queue", ownership.Reply)
+	assert.Equal(t, other.Names()[0], ownership.Owner)
+	assert.Equal(t, self, ownership.Connection)
+
+	queue, err := service.ListQueuedOwners(ctx, "session", "com.example.NameTest")
+	require.NoError(t, err)
+	assert.Equal(t, []string{other.Names()[0], self}, queue.Owners)
+
+	ownership, err = service.RequestName(ctx, "session", "com.example.NameTest", model.NameFlags{ReplaceExisting: true})
+	require.NoError(t, err)
+	assert.Equal(t, "primary_owner", ownership.Reply)
+	assert.Equal(t, self, ownership.Owner)
+
+	ownership, err = service.ReleaseName(ctx, "session", "com.example.NameTest")
+	require.NoError(t, err)
+	assert.Equal(t, "released", ownership.Reply)
+
+	ownership, err = service.ReleaseName(ctx, "session", "com.example.NameTest")
+	require.NoError(t, err)
+	assert.Equal(t, "not_owner", ownership.Reply)
+
+	_, err = service.RequestName(ctx, "session", ":1.1", model.NameFlags{})
+	assert.Equal(t, "org.freedesktop.DBus.Error.InvalidArgs", ErrorName(err))
+}
+
+func TestDBusService_Integration_NameOwners(t *testing.T) {
+	service := NewDBusService()
+	defer service.Close()
+	if _, err := service.getConnection("session"); err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	events, err := service.WatchNameOwners(ctx, "session", "com.example.OwnerTest*", false)
+	require.NoError(t, err)
+	_, err = service.WatchNameOwners(ctx, "session", "com.[example", false)
+	assert.ErrorIs(t, err, ErrInvalidNamePattern)
+
+	waited := make(chan string, 1)
+	go func() {
+		owner, _ := service.WaitForName(ctx, "session", "com.example.OwnerTest")
+		waited <- owner
+	}()
+
+	other, err := dbus.ConnectSessionBus()
+	require.NoError(t, err)
+	defer other.Close()
+	// Give the wait time to register before the name appears
+	time.Sleep(100 * time.Millisecond)
+	_, err = other.RequestName("com.example.OwnerTest", dbus.NameFlagAllowReplacement)
+	require.NoError(t, err)
+	assert.Equal(t, other.Names()[0], <-waited)
+
+	_, err = service.RequestName(ctx, "session", "com.example.OwnerTest", model.NameFlags{ReplaceExisting: true})
+	require.NoError(t, err)
+	_, err = service.ReleaseName(ctx, "session", "com.example.OwnerTest")
+	require.NoError(t, err)
+
+	var changes []string
+	for len(changes) < 5 {
+		select {
+		case event := <-events:
+			assert.Equal(t, "com.example.OwnerTest", event.Name)
+			changes = append(changes, event.Change)
+		case <-ctx.Done():
+			t.Fatalf("missing name owner events, got %v", changes)
+		}
+	}
+	// NameAcquired may be delivered before or after the NameOwnerChanged it comes with
+	assert.Equal(t, "appeared", changes[0])
+	assert.ElementsMatch(t, []string{"replaced", "acquired"}, changes[1:3])
+	assert.ElementsMatch(t, []string{"replaced", "lost"}, changes[3:5])
+
+	// The name is already owned
+	owner, err := service.WaitForName(ctx, "session", "com.example.OwnerTest")
+	require.NoError(t, err)
+	assert.Equal(t, other.Names()[0], owner)
+
+	short, cancelShort := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancelShort()
+	_, err = service.WaitForName(short, "session", "com.example.NeverOwned")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestDBusService_Integration_ServiceInfoOwner(t *testing.T) {
+	service := NewDBusService()
+	defer service.Close()
+	conn, err := service.getConnection("session")
+	if err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+	ctx := context.Background()
+
+	for _, name := range []string{"com.example.InfoTest", "com.example.InfoTest.Alias"} {
+		_, err = service.RequestName(ctx, "session", name, model.NameFlags{})
+		require.NoError(t, err)
+		defer service.ReleaseName(ctx, "session", name)
+	}
+
+	info, err := service.GetServiceInfo(ctx, "session", "com.example.InfoTest")
+	require.NoError(t, err)
+	assert.Equal(t, conn.Names()[0], info.Owner)
+	assert.Equal(t, []string{"com.example.InfoTest.Alias"}, info.Aliases)
+	assert.False(t, info.AliasesTruncated)
+
+	require.NotNil(t, info.Credentials)
+	require.NotNil(t, info.Credentials.ProcessID)
+	assert.Equal(t, uint32(os.Getpid()), *info.Credentials.ProcessID)
+	require.NotNil(t, info.Credentials.UnixUserID)
+	assert.Equal(t, uint32(os.Getuid()), *info.Credentials.UnixUserID)
+
+	require.NotNil(t, info.Process)
+	assert.Equal(t, uint32(os.Getpid()), info.Process.PID)
+	assert.NotEmpty(t, info.Process.Cmdline)
+	require.NotNil(t, info.Process.StartTime)
+	assert.WithinDuration(t, time.Now(), *info.Process.StartTime, time.Hour)
+	// The namespace check is kept for the following requests
+	assert.True(t, service.sessionNamespace.shared)
+
+	info, err = service.GetServiceInfo(ctx, "session", "com.example.NotRunning")
+	require.NoError(t, err)
+	assert.Equal(t, "unknown", info.Owner)
+	assert.Nil(t, info.Credentials)
+	assert.Nil(t, info.Process)
+}
+
+func TestValidBusName(t *testing.T) {
+	for _, name := range []string{"org.freedesktop.DBus", ":1.42", "com.example-app._2"} {
+		assert.True(t, validBusName(name), name)
+	}
+	for _, name := range []string{"", "com", "com..example", "com.example.", "com.1example", "com.exa mple", ":1"} {
+		assert.False(t, validBusName(name), name)
+	}
+}
+
 // Benchmark tests
 func BenchmarkParseIntrospectionXML(b *testing.B) {
 	service := NewDBusService()
@@ -217,3 +757,30 @@ func BenchmarkParseIntrospectionXML(b *testing.B) {
 		}
 	}
 }
+
+func TestSplitMember(t *testing.T) {
+	iface, member := splitMember("org.freedesktop.DBus.Properties.Get")
+	assert.Equal(t, "org.freedesktop.DBus.Properties", iface)
+	assert.Equal(t, "Get", member)
+
+	iface, member = splitMember("Ping")
+	assert.Equal(t, "", iface)
+	assert.Equal(t, "Ping", member)
+}
+
+func TestErrorName(t *testing.T) {
+	err := dbus.Error{Name: "org.freedesktop.DBus.Error.ServiceUnknown"}
+
+	assert.Equal(t, "org.freedesktop.DBus.Error.ServiceUnknown", ErrorName(err))
+	assert.Equal(t, "org.freedesktop.DBus.Error.UnknownMethod", ErrorName(fmt.Errorf("wrapped: %w", &dbus.Error{Name: "org.freedesktop.DBus.Error.UnknownMethod"})))
+	assert.Equal(t, "", ErrorName(fmt.Errorf("plain error")))
+}
+
+func TestClassifyError(t *testing.T) {
+	assert.Equal(t, ErrorClassNotFound, ClassifyError("org.freedesktop.DBus.Error.ServiceUnknown"))
+	assert.Equal(t, ErrorClassInvalid, ClassifyError("org.freedesktop.DBus.Error.MatchRuleInvalid"))
+	assert.Equal(t, ErrorClassDenied, ClassifyError("org.freedesktop.DBus.Error.AuthFailed"))
+	assert.Equal(t, ErrorClassTimeout, ClassifyError("org.freedesktop.DBus.Error.TimedOut"))
+	assert.Equal(t, ErrorClassFailed, ClassifyError("com.example.Error.Failed"))
+	assert.Equal(t, ErrorClassFailed, ClassifyError(""))
+}
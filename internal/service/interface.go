@@ -1,22 +1,52 @@
 package service
 
-import "github.com/mesbrj/dbus-controller/internal/model"
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
 
 // DBusServiceInterface defines the interface for D-Bus operations
 // This interface allows for easy mocking in tests
 type DBusServiceInterface interface {
-	ListServices(busType string) ([]string, error)
-	GetServiceInfo(busType, serviceName string) (*model.ServiceInfo, error)
-	ListInterfaces(busType, serviceName string) ([]string, error)
-	GetInterfaceInfo(busType, serviceName, interfaceName string) (*model.InterfaceInfo, error)
-	ListMethods(busType, serviceName, interfaceName string) ([]model.MethodInfo, error)
-	CallMethod(busType, serviceName, interfaceName, methodName string, args []interface{}) (*model.MethodCallResult, error)
-	ListProperties(busType, serviceName, interfaceName string) ([]model.PropertyInfo, error)
-	GetProperty(busType, serviceName, interfaceName, propertyName string) (*model.PropertyValue, error)
-	SetProperty(busType, serviceName, interfaceName, propertyName string, value interface{}) (*model.PropertyValue, error)
-	ListSignals(busType, serviceName, interfaceName string) ([]model.SignalInfo, error)
-	SubscribeToSignal(busType, serviceName, interfaceName, signalName string) (*model.SignalSubscription, error)
-	IntrospectService(busType, serviceName string) (*model.IntrospectionResult, error)
+	CheckBus(ctx context.Context, busType string) *model.BusHealth
+	ListServices(ctx context.Context, busType string) ([]string, error)
+	ListNames(ctx context.Context, busType string) ([]model.NameInfo, error)
+	StartService(ctx context.Context, busType, serviceName string) (*model.ServiceStart, error)
+	UpdateActivationEnvironment(ctx context.Context, busType string, environment map[string]string) error
+	RequestName(ctx context.Context, busType, name string, flags model.NameFlags) (*model.NameOwnership, error)
+	ReleaseName(ctx context.Context, busType, name string) (*model.NameOwnership, error)
+	ListQueuedOwners(ctx context.Context, busType, name string) (*model.NameQueue, error)
+	WatchNameOwners(ctx context.Context, busType, pattern string, unique bool) (<-chan *model.NameOwnerEvent, error)
+	WaitForName(ctx context.Context, busType, name string) (string, error)
+	GetServiceInfo(ctx context.Context, busType, serviceName string) (*model.ServiceInfo, error)
+	ListInterfaces(ctx context.Context, busType, serviceName, objectPath string) ([]string, error)
+	GetInterfaceInfo(ctx context.Context, busType, serviceName, objectPath, interfaceName string) (*model.InterfaceInfo, error)
+	ListMethods(ctx context.Context, busType, serviceName, objectPath, interfaceName string) ([]model.MethodInfo, error)
+	CallMethod(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName string, args []interface{}) (*model.MethodCallResult, error)
+	CallMethodWithFlags(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName string, flags model.CallFlags, args []interface{}) (*model.MethodCallResult, error)
+	CallMethodNoReply(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName string, args []interface{}) error
+	StartJob(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName string, flags model.CallFlags, args []interface{}) (*model.Job, error)
+	ListJobs(ctx context.Context) ([]model.Job, error)
+	GetJob(ctx context.Context, jobID string) (*model.Job, error)
+	CancelJob(ctx context.Context, jobID string) error
+	WatchJob(ctx context.Context, jobID string) (<-chan *model.Job, error)
+	ListProperties(ctx context.Context, busType, serviceName, objectPath, interfaceName string) ([]model.PropertyInfo, error)
+	GetProperty(ctx context.Context, busType, serviceName, objectPath, interfaceName, propertyName string) (*model.PropertyValue, error)
+	SetProperty(ctx context.Context, busType, serviceName, objectPath, interfaceName, propertyName string, value interface{}) (*model.PropertyValue, error)
+	ListSignals(ctx context.Context, busType, serviceName, objectPath, interfaceName string) ([]model.SignalInfo, error)
+	EmitSignal(ctx context.Context, busType, destination, objectPath, interfaceName, signalName string, args []interface{}) error
+	SubscribeToSignal(ctx context.Context, busType, serviceName, interfaceName, signalName string) (*model.SignalSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]model.SignalSubscription, error)
+	Unsubscribe(ctx context.Context, subscriptionID string) error
+	StreamSubscription(ctx context.Context, subscriptionID string) (<-chan *model.SignalEvent, error)
+	Monitor(ctx context.Context, busType string, rules []string) (<-chan *model.MonitorMessage, error)
+	Capture(ctx context.Context, busType string, rules []string, duration time.Duration, w io.Writer) (*model.CaptureStats, error)
+	CloseStreams()
+	IntrospectService(ctx context.Context, busType, serviceName string) (*model.IntrospectionResult, error)
+	IntrospectObject(ctx context.Context, busType, serviceName, objectPath string) (*model.IntrospectionResult, error)
 	Close()
 }
 
@@ -0,0 +1,321 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// ErrJobNotFound is returned for unknown or expired job IDs
+var ErrJobNotFound = errors.New("job not found")
+
+// ErrTooManyJobs is returned when a job would exceed the queue of waiting jobs
+var ErrTooManyJobs = errors.New("too many jobs")
+
+// Job statuses
+const (
+	JobPending   = "pending"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobAbandoned = "abandoned"
+)
+
+// JobLimits bounds the asynchronous method calls
+type JobLimits struct {
+	MaxRunning int           // Calls in flight at once; further jobs wait
+	MaxQueued  int           // Jobs waiting for a slot; further jobs are rejected
+	Timeout    time.Duration // Time allowed to a call, from its start
+	Retention  time.Duration // Time a finished job is kept
+}
+
+// DefaultJobLimits are the job limits used until SetJobLimits is called
+var DefaultJobLimits = JobLimits{
+	MaxRunning: 4,
+	MaxQueued:  64,
+	Timeout:    time.Hour,
+	Retention:  15 * time.Minute,
+}
+
+// job is an asynchronous method call and its state
+type job struct {
+	job    model.Job
+	cancel context.CancelFunc
+	done   chan struct{} // Closed when the job finishes or is abandoned
+}
+
+// jobTable holds the jobs of the service and bounds how many run at once
+type jobTable struct {
+	mu       sync.Mutex
+	limits   JobLimits
+	slots    chan struct{} // Holds a token per running job
+	jobs     map[string]*job
+	waiting  int
+	closing  chan struct{} // Closed by CloseStreams to end the job watches
+	closeOne sync.Once
+}
+
+func newJobTable(limits JobLimits) *jobTable {
+	return &jobTable{
+		limits:  limits,
+		slots:   make(chan struct{}, limits.MaxRunning),
+		jobs:    make(map[string]*job),
+		closing: make(chan struct{}),
+	}
+}
+
+// SetJobLimits replaces the job limits. Jobs already started keep the previous limits.
+func (s *DBusService) SetJobLimits(limits JobLimits) {
+	s.jobs.mu.Lock()
+	defer s.jobs.mu.Unlock()
+
+	s.jobs.limits = limits
+	s.jobs.slots = make(chan struct{}, limits.MaxRunning)
+}
+
+// StartJob starts a method call in the background and returns its job.
+// The call waits for a free slot when the maximum of running jobs is reached.
+// A job sent without reply, as requested or annotated, succeeds once sent.
+func (s *DBusService) StartJob(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName string, flags model.CallFlags, args []interface{}) (*model.Job, error) {
+	conn, err := s.getConnection(busType)
+	if err != nil {
+		return nil, err
+	}
+
+	t := s.jobs
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.waiting >= t.limits.MaxQueued && len(t.slots) == cap(t.slots) {
+		return nil, fmt.Errorf("%w: %d jobs waiting", ErrTooManyJobs, t.waiting)
+	}
+
+	// The call outlives the request, but keeps its trace and log attributes
+	jobCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	j := &job{
+		job: model.Job{
+			ID:        newJobID(),
+			Status:    JobPending,
+			BusType:   busType,
+			Service:   serviceName,
+			Path:      objectPath,
+			Interface: interfaceName,
+			Method:    methodName,
+			CreatedAt: time.Now(),
+		},
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	t.jobs[j.job.ID] = j
+	t.waiting++
+
+	obj := conn.Object(serviceName, dbus.ObjectPath(objectPath))
+	go s.runJob(jobCtx, j, conn, obj, t.slots, t.limits, flags, args)
+
+	slog.InfoContext(ctx, "Job started", "job", j.job.ID, "bus", busType, "service", serviceName, "path", objectPath,
+		"interface", interfaceName, "method", methodName)
+
+	started := j.job
+	return &started, nil
+}
+
+// runJob waits for a slot, runs the call of the job and records its result
+func (s *DBusService) runJob(ctx context.Context, j *job, conn *dbus.Conn, obj dbus.BusObject, slots chan struct{}, limits JobLimits, flags model.CallFlags, args []interface{}) {
+	t := s.jobs
+
+	select {
+	case slots <- struct{}{}:
+	case <-ctx.Done():
+		t.mu.Lock()
+		t.waiting--
+		t.mu.Unlock()
+		return
+	}
+	defer func() { <-slots }()
+
+	t.mu.Lock()
+	t.waiting--
+	if j.job.Status == JobAbandoned {
+		t.mu.Unlock()
+		return
+	}
+	startedAt := time.Now()
+	j.job.Status = JobRunning
+	j.job.StartedAt = &startedAt
+	t.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, limits.Timeout)
+	defer cancel()
+
+	callFlags := s.callFlags(ctx, j.job.BusType, obj, j.job.Interface, j.job.Method, flags)
+	call := s.callWithFlags(ctx, j.job.BusType, conn, obj, j.job.Interface+"."+j.job.Method, callFlags, args)
+
+	result := &model.MethodCallResult{
+		Success:   call.Err == nil,
+		NoReply:   callFlags&dbus.FlagNoReplyExpected != 0,
+		Timestamp: time.Now(),
+	}
+	if call.Err != nil {
+		result.Error = call.Err.Error()
+		result.ErrorName = ErrorName(call.Err)
+	} else {
+		result.ReturnValues = call.Body
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if j.job.Status == JobAbandoned {
+		return
+	}
+	j.job.Status = JobSucceeded
+	if !result.Success {
+		j.job.Status = JobFailed
+	}
+	j.job.Result = result
+	j.job.FinishedAt = &result.Timestamp
+	close(j.done)
+
+	slog.InfoContext(ctx, "Job finished", "job", j.job.ID, "status", j.job.Status, "duration", result.Timestamp.Sub(startedAt))
+
+	// Finished jobs are kept for their result, then forgotten
+	time.AfterFunc(limits.Retention, func() {
+		t.mu.Lock()
+		if t.jobs[j.job.ID] == j {
+			delete(t.jobs, j.job.ID)
+		}
+		t.mu.Unlock()
+	})
+}
+
+// ListJobs returns the pending, running and retained finished jobs, oldest first
+func (s *DBusService) ListJobs(ctx context.Context) ([]model.Job, error) {
+	s.jobs.mu.Lock()
+	defer s.jobs.mu.Unlock()
+
+	jobs := make([]model.Job, 0, len(s.jobs.jobs))
+	for _, j := range s.jobs.jobs {
+		jobs = append(jobs, j.job)
+	}
+	sort.Slice(jobs, func(i, k int) bool {
+		if jobs[i].CreatedAt.Equal(jobs[k].CreatedAt) {
+			return jobs[i].ID < jobs[k].ID
+		}
+		return jobs[i].CreatedAt.Before(jobs[k].CreatedAt)
+	})
+
+	return jobs, nil
+}
+
+// GetJob returns the state of a job, with the result of its call once finished
+func (s *DBusService) GetJob(ctx context.Context, jobID string) (*model.Job, error) {
+	s.jobs.mu.Lock()
+	defer s.jobs.mu.Unlock()
+
+	j, ok := s.jobs.jobs[jobID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
+	}
+
+	state := j.job
+	return &state, nil
+}
+
+// CancelJob removes a job. A pending or running job is abandoned: its call is
+// no longer waited for, although the service may still carry it out.
+func (s *DBusService) CancelJob(ctx context.Context, jobID string) error {
+	s.jobs.mu.Lock()
+	j, ok := s.jobs.jobs[jobID]
+	if ok {
+		delete(s.jobs.jobs, jobID)
+		s.jobs.abandon(j)
+	}
+	s.jobs.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
+	}
+
+	slog.InfoContext(ctx, "Job removed", "job", jobID)
+
+	return nil
+}
+
+// WatchJob delivers the job once it finishes or is abandoned. The returned
+// channel is closed after the job is delivered, or without it when ctx is
+// done or the service shuts down.
+func (s *DBusService) WatchJob(ctx context.Context, jobID string) (<-chan *model.Job, error) {
+	s.jobs.mu.Lock()
+	j, ok := s.jobs.jobs[jobID]
+	closing := s.jobs.closing
+	s.jobs.mu.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
+	}
+
+	jobs := make(chan *model.Job, 1)
+
+	go func() {
+		defer close(jobs)
+
+		select {
+		case <-j.done:
+		case <-ctx.Done():
+			return
+		case <-closing:
+			return
+		}
+
+		s.jobs.mu.Lock()
+		state := j.job
+		s.jobs.mu.Unlock()
+		jobs <- &state
+	}()
+
+	return jobs, nil
+}
+
+// abandon cancels the call of an unfinished job and ends its watches.
+// The caller must hold the table mutex.
+func (t *jobTable) abandon(j *job) {
+	j.cancel()
+	if j.job.Status == JobPending || j.job.Status == JobRunning {
+		now := time.Now()
+		j.job.Status = JobAbandoned
+		j.job.FinishedAt = &now
+		close(j.done)
+	}
+}
+
+// closeWatches ends the job watches; used on shutdown like CloseStreams
+func (t *jobTable) closeWatches() {
+	t.closeOne.Do(func() { close(t.closing) })
+}
+
+// close abandons the unfinished jobs
+func (t *jobTable) close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for id, j := range t.jobs {
+		t.abandon(j)
+		delete(t.jobs, id)
+	}
+}
+
+func newJobID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
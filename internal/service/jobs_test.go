@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// newJobService returns a service connected to the session bus, skipping the test without one
+func newJobService(t *testing.T, limits JobLimits) *DBusService {
+	service := NewDBusService()
+	t.Cleanup(service.Close)
+	if _, err := service.getConnection("session"); err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+	service.SetJobLimits(limits)
+	return service
+}
+
+func TestStartJob(t *testing.T) {
+	service := newJobService(t, DefaultJobLimits)
+	ctx := context.Background()
+
+	job, err := service.StartJob(ctx, "session", "org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "GetId", model.CallFlags{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, JobPending, job.Status)
+
+	jobs, err := service.WatchJob(ctx, job.ID)
+	require.NoError(t, err)
+	select {
+	case finished := <-jobs:
+		require.NotNil(t, finished)
+		assert.Equal(t, JobSucceeded, finished.Status)
+		require.NotNil(t, finished.Result)
+		assert.Len(t, finished.Result.ReturnValues, 1)
+		assert.NotNil(t, finished.FinishedAt)
+	case <-time.After(5 * time.Second):
+		t.Fatal("job did not finish")
+	}
+
+	listed, err := service.ListJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, listed, 1)
+	assert.Equal(t, job.ID, listed[0].ID)
+
+	require.NoError(t, service.CancelJob(ctx, job.ID))
+	_, err = service.GetJob(ctx, job.ID)
+	assert.True(t, errors.Is(err, ErrJobNotFound))
+}
+
+func TestStartJob_Failure(t *testing.T) {
+	service := newJobService(t, DefaultJobLimits)
+	ctx := context.Background()
+
+	job, err := service.StartJob(ctx, "session", "org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "NoSuchMethod", model.CallFlags{}, nil)
+	require.NoError(t, err)
+
+	finished := <-mustWatchJob(t, service, job.ID)
+	require.NotNil(t, finished)
+	assert.Equal(t, JobFailed, finished.Status)
+	assert.Equal(t, "org.freedesktop.DBus.Error.UnknownMethod", finished.Result.ErrorName)
+}
+
+func TestStartJob_Queue(t *testing.T) {
+	service := newJobService(t, JobLimits{MaxRunning: 1, MaxQueued: 1, Timeout: time.Minute, Retention: time.Minute})
+	ctx := context.Background()
+
+	// Hold the only slot so that jobs wait
+	service.jobs.slots <- struct{}{}
+
+	waiting, err := service.StartJob(ctx, "session", "org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "GetId", model.CallFlags{}, nil)
+	require.NoError(t, err)
+
+	_, err = service.StartJob(ctx, "session", "org.freedesktop.DBus", "/org/freedesktop/DBus", "org.freedesktop.DBus", "GetId", model.CallFlags{}, nil)
+	assert.True(t, errors.Is(err, ErrTooManyJobs))
+
+	jobs := mustWatchJob(t, service, waiting.ID)
+	require.NoError(t, service.CancelJob(ctx, waiting.ID))
+	abandoned := <-jobs
+	require.NotNil(t, abandoned)
+	assert.Equal(t, JobAbandoned, abandoned.Status)
+	assert.Nil(t, abandoned.Result)
+
+	// The waiting job leaves the queue once abandoned
+	assert.Eventually(t, func() bool {
+		service.jobs.mu.Lock()
+		defer service.jobs.mu.Unlock()
+		return service.jobs.waiting == 0
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestJobs_NotFound(t *testing.T) {
+	service := NewDBusService()
+	defer service.Close()
+	ctx := context.Background()
+
+	_, err := service.GetJob(ctx, "missing")
+	assert.True(t, errors.Is(err, ErrJobNotFound))
+	assert.True(t, errors.Is(service.CancelJob(ctx, "missing"), ErrJobNotFound))
+	_, err = service.WatchJob(ctx, "missing")
+	assert.True(t, errors.Is(err, ErrJobNotFound))
+}
+
+func mustWatchJob(t *testing.T, service *DBusService, jobID string) <-chan *model.Job {
+	t.Helper()
+	jobs, err := service.WatchJob(context.Background(), jobID)
+	require.NoError(t, err)
+	return jobs
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// ValueError reports a JSON value that does not match its D-Bus type
+type ValueError struct {
+	Path string // Location of the value, e.g. options.key[1]
+	Msg  string
+}
+
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Msg)
+}
+
+// ArgName returns the name of an argument, argN for unnamed ones
+func ArgName(arg model.ArgumentInfo, i int) string {
+	if arg.Name != "" {
+		return arg.Name
+	}
+	return fmt.Sprintf("arg%d", i)
+}
+
+// ParseJSONArg converts a decoded JSON value to the typed D-Bus value of one
+// complete type. Integers and booleans may also be given as strings, as the
+// values of query and path parameters are, byte arrays as base64
+// strings, and variants as {"signature": ..., "value": ...} objects; other
+// variant values take the type of their JSON value.
+func ParseJSONArg(name, typ string, value interface{}) (interface{}, error) {
+	types, err := SplitSignature(typ)
+	if err != nil || len(types) != 1 {
+		return nil, &ValueError{Path: name, Msg: fmt.Sprintf("invalid type %q", typ)}
+	}
+	return jsonValue(name, typ, value)
+}
+
+func jsonValue(path, typ string, value interface{}) (interface{}, error) {
+	mismatch := func(expected string) error {
+		return &ValueError{Path: path, Msg: fmt.Sprintf("expected %s for type %s, got %s", expected, typ, jsonKind(value))}
+	}
+
+	switch typ[0] {
+	case 'y', 'n', 'q', 'i', 'u', 'x', 't', 'd':
+		var text string
+		switch v := value.(type) {
+		case float64:
+			if typ[0] != 'd' && v != math.Trunc(v) {
+				return nil, &ValueError{Path: path, Msg: fmt.Sprintf("%v is not an integer", v)}
+			}
+			text = strconv.FormatFloat(v, 'f', -1, 64)
+		case json.Number:
+			text = v.String()
+		case string:
+			text = v
+		default:
+			return nil, mismatch("a number")
+		}
+		parsed, err := parseBasic(typ[0], text)
+		if err != nil {
+			return nil, &ValueError{Path: path, Msg: err.Error()}
+		}
+		return parsed, nil
+
+	case 'b':
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			parsed, err := parseBasic('b', v)
+			if err != nil {
+				return nil, &ValueError{Path: path, Msg: err.Error()}
+			}
+			return parsed, nil
+		default:
+			return nil, mismatch("a boolean")
+		}
+
+	case 's', 'o', 'g':
+		v, ok := value.(string)
+		if !ok {
+			return nil, mismatch("a string")
+		}
+		parsed, err := parseBasic(typ[0], v)
+		if err != nil {
+			return nil, &ValueError{Path: path, Msg: err.Error()}
+		}
+		return parsed, nil
+
+	case 'v':
+		return jsonVariant(path, value)
+
+	case 'a':
+		goType, _ := typeFor(typ)
+
+		if typ[1] == '{' {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil, mismatch("an object")
+			}
+			entry := mustSplit(typ[2 : len(typ)-1])
+			dict := reflect.MakeMapWithSize(goType, len(object))
+			// Keys in order, for deterministic errors
+			for _, key := range slices.Sorted(maps.Keys(object)) {
+				parsedKey, err := parseBasic(entry[0][0], key)
+				if err != nil {
+					return nil, &ValueError{Path: path, Msg: fmt.Sprintf("key: %v", err)}
+				}
+				elem, err := jsonValue(path+"."+key, entry[1], object[key])
+				if err != nil {
+					return nil, err
+				}
+				dict.SetMapIndex(reflect.ValueOf(parsedKey), reflect.ValueOf(elem))
+			}
+			return dict.Interface(), nil
+		}
+
+		if text, ok := value.(string); ok && typ == "ay" {
+			data, err := base64.StdEncoding.DecodeString(text)
+			if err != nil {
+				return nil, &ValueError{Path: path, Msg: "invalid base64 byte array"}
+			}
+			return data, nil
+		}
+
+		array, ok := value.([]interface{})
+		if !ok {
+			return nil, mismatch("an array")
+		}
+		slice := reflect.MakeSlice(goType, 0, len(array))
+		for i, item := range array {
+			elem, err := jsonValue(fmt.Sprintf("%s[%d]", path, i), typ[1:], item)
+			if err != nil {
+				return nil, err
+			}
+			slice = reflect.Append(slice, reflect.ValueOf(elem))
+		}
+		return slice.Interface(), nil
+
+	case '(':
+		array, ok := value.([]interface{})
+		if !ok {
+			return nil, mismatch("an array")
+		}
+		members := mustSplit(typ[1 : len(typ)-1])
+		if len(array) != len(members) {
+			return nil, &ValueError{Path: path, Msg: fmt.Sprintf("expected %d struct members, got %d", len(members), len(array))}
+		}
+		goType, _ := typeFor(typ)
+		structValue := reflect.New(goType).Elem()
+		for i, member := range members {
+			field, err := jsonValue(fmt.Sprintf("%s[%d]", path, i), member, array[i])
+			if err != nil {
+				return nil, err
+			}
+			structValue.Field(i).Set(reflect.ValueOf(field))
+		}
+		return structValue.Interface(), nil
+
+	default:
+		return nil, &ValueError{Path: path, Msg: fmt.Sprintf("unsupported type %q", typ)}
+	}
+}
+
+// jsonVariant converts a variant value, typed by an explicit signature or by its JSON kind
+func jsonVariant(path string, value interface{}) (interface{}, error) {
+	if object, ok := value.(map[string]interface{}); ok && len(object) == 2 {
+		if signature, ok := object["signature"].(string); ok {
+			if _, hasValue := object["value"]; hasValue {
+				if types, err := SplitSignature(signature); err != nil || len(types) != 1 {
+					return nil, &ValueError{Path: path, Msg: fmt.Sprintf("invalid variant signature %q", signature)}
+				}
+				inner, err := jsonValue(path+".value", signature, object["value"])
+				if err != nil {
+					return nil, err
+				}
+				return dbus.MakeVariantWithSignature(inner, dbus.ParseSignatureMust(signature)), nil
+			}
+		}
+	}
+
+	switch v := value.(type) {
+	case string, bool:
+		return dbus.MakeVariant(v), nil
+	case float64:
+		if v == math.Trunc(v) && v >= math.MinInt32 && v <= math.MaxInt32 {
+			return dbus.MakeVariant(int32(v)), nil
+		}
+		if v == math.Trunc(v) && v >= math.MinInt64 && v < math.MaxInt64 {
+			return dbus.MakeVariant(int64(v)), nil
+		}
+		return dbus.MakeVariant(v), nil
+	case []interface{}:
+		inner, err := jsonValue(path, "av", v)
+		if err != nil {
+			return nil, err
+		}
+		return dbus.MakeVariant(inner), nil
+	case map[string]interface{}:
+		inner, err := jsonValue(path, "a{sv}", v)
+		if err != nil {
+			return nil, err
+		}
+		return dbus.MakeVariant(inner), nil
+	default:
+		return nil, &ValueError{Path: path, Msg: fmt.Sprintf("cannot infer the variant type of %s", jsonKind(value))}
+	}
+}
+
+// jsonKind names the JSON kind of a decoded value
+func jsonKind(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case float64, json.Number:
+		return "a number"
+	case string:
+		return "a string"
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// ToJSONArg converts a D-Bus value to the decoded JSON accepted by
+// ParseJSONArg. Integers become json.Number and variants become
+// {"signature", "value"} objects; JSON values are returned unchanged.
+func ToJSONArg(value any) any {
+	switch v := value.(type) {
+	case nil, bool, string, float64, json.Number:
+		return v
+	case dbus.Variant:
+		return map[string]any{"signature": v.Signature().String(), "value": ToJSONArg(v.Value())}
+	case dbus.Signature:
+		return v.String()
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return json.Number(strconv.FormatInt(rv.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return json.Number(strconv.FormatUint(rv.Uint(), 10))
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	case reflect.String:
+		return rv.String()
+	case reflect.Slice, reflect.Array:
+		list := make([]any, rv.Len())
+		for i := range list {
+			list[i] = ToJSONArg(rv.Index(i).Interface())
+		}
+		return list
+	case reflect.Map:
+		object := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			object[fmt.Sprint(iter.Key().Interface())] = ToJSONArg(iter.Value().Interface())
+		}
+		return object
+	default:
+		return value
+	}
+}
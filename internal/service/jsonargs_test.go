@@ -0,0 +1,96 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// decode returns a JSON document as decoded by encoding/json
+func decode(t *testing.T, document string) interface{} {
+	var value interface{}
+	require.NoError(t, json.Unmarshal([]byte(document), &value))
+	return value
+}
+
+func TestParseJSONArg(t *testing.T) {
+	tests := []struct {
+		typ      string
+		document string
+		expected interface{}
+	}{
+		{"s", `"hello"`, "hello"},
+		{"u", `4`, uint32(4)},
+		{"t", `"18446744073709551615"`, uint64(18446744073709551615)},
+		{"n", `-3`, int16(-3)},
+		{"d", `1.5`, 1.5},
+		{"b", `true`, true},
+		{"b", `"false"`, false},
+		{"o", `"/a/b"`, dbus.ObjectPath("/a/b")},
+		{"as", `["a", "b"]`, []string{"a", "b"}},
+		{"ay", `"AAEC"`, []byte{0, 1, 2}},
+		{"ay", `[0, 1, 2]`, []byte{0, 1, 2}},
+		{"a{ui}", `{"1": 2}`, map[uint32]int32{1: 2}},
+		{"v", `"text"`, dbus.MakeVariant("text")},
+		{"v", `7`, dbus.MakeVariant(int32(7))},
+		{"v", `{"signature": "u", "value": 7}`, dbus.MakeVariant(uint32(7))},
+		{"a{sv}", `{"k": {"signature": "as", "value": ["x"]}}`, map[string]dbus.Variant{"k": dbus.MakeVariant([]string{"x"})}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typ+" "+tt.document, func(t *testing.T) {
+			value, err := ParseJSONArg("arg", tt.typ, decode(t, tt.document))
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestParseJSONArg_Struct(t *testing.T) {
+	value, err := ParseJSONArg("arg", "a(si)", decode(t, `[["a", 1], ["b", 2]]`))
+
+	require.NoError(t, err)
+	assert.Equal(t, "a(si)", dbus.SignatureOf(value).String())
+}
+
+func TestParseJSONArg_Errors(t *testing.T) {
+	tests := []struct {
+		typ      string
+		document string
+		path     string
+	}{
+		{"s", `1`, "arg"},
+		{"b", `"maybe"`, "arg"},
+		{"b", `1`, "arg"},
+		{"i", `1.5`, "arg"},
+		{"y", `256`, "arg"},
+		{"o", `"relative"`, "arg"},
+		{"as", `["a", 1]`, "arg[1]"},
+		{"a{sv}", `{"k": null}`, "arg.k"},
+		{"(si)", `["a"]`, "arg"},
+		{"a{is}", `{"x": "a"}`, "arg"},
+		{"ss", `"a"`, "arg"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.typ+" "+tt.document, func(t *testing.T) {
+			_, err := ParseJSONArg("arg", tt.typ, decode(t, tt.document))
+
+			var valueErr *ValueError
+			require.True(t, errors.As(err, &valueErr), "expected ValueError, got %v", err)
+			assert.Equal(t, tt.path, valueErr.Path)
+		})
+	}
+}
+
+func TestArgName(t *testing.T) {
+	assert.Equal(t, "name", ArgName(model.ArgumentInfo{Name: "name"}, 0))
+	assert.Equal(t, "arg2", ArgName(model.ArgumentInfo{}, 2))
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// ErrTooManyMonitors is returned when a monitor would exceed the monitor sessions
+var ErrTooManyMonitors = errors.New("too many monitors")
+
+// MonitorLimits bounds the bus monitors
+type MonitorLimits struct {
+	MaxSessions int // Monitors open at once; further monitors are rejected
+	Buffer      int // Messages queued for a slow client; further messages are dropped
+}
+
+// DefaultMonitorLimits are the monitor limits used until SetMonitorLimits is called
+var DefaultMonitorLimits = MonitorLimits{
+	MaxSessions: 4,
+	Buffer:      256,
+}
+
+// monitorMessageTypes names the message types
+var monitorMessageTypes = map[dbus.Type]string{
+	dbus.TypeMethodCall:  "method_call",
+	dbus.TypeMethodReply: "method_return",
+	dbus.TypeError:       "error",
+	dbus.TypeSignal:      "signal",
+}
+
+// SetMonitorLimits replaces the monitor limits. Open monitors keep their buffer.
+func (s *DBusService) SetMonitorLimits(limits MonitorLimits) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.monitorLimits = limits
+}
+
+// Monitor delivers the messages of a bus, selected by match rules, or every
+// message without rules. The monitor is a dedicated connection turned into a
+// monitor with BecomeMonitor, which the bus usually restricts to root on the
+// system bus. Messages are dropped while the buffer is full; the next message
+// delivered counts them. The returned channel is closed when ctx is done, the
+// bus closes the connection or the service shuts down.
+func (s *DBusService) Monitor(ctx context.Context, busType string, rules []string) (<-chan *model.MonitorMessage, error) {
+	session, limits, err := s.startMonitor(ctx, busType, rules)
+	if err != nil {
+		return nil, err
+	}
+	messages := make(chan *model.MonitorMessage, limits.Buffer)
+
+	slog.InfoContext(ctx, "Monitor opened", "bus", busType, "rules", rules)
+
+	go func() {
+		defer close(messages)
+		defer func() {
+			session.stop()
+			slog.Info("Monitor closed", "bus", busType)
+		}()
+
+		var dropped uint64
+		for {
+			msg, ok := session.next(ctx)
+			if !ok {
+				return
+			}
+			message := newMonitorMessage(msg)
+			message.Dropped = dropped
+			select {
+			case messages <- message:
+				dropped = 0
+			default:
+				dropped++
+			}
+		}
+	}()
+
+	return messages, nil
+}
+
+// monitorSession is a connection turned into a monitor
+type monitorSession struct {
+	conn     *dbus.Conn
+	self     string             // Unique name of the monitor connection
+	received chan *dbus.Message // Messages of the bus, as eavesdropped
+	stop     func()             // Closes the connection and frees the session
+}
+
+// next returns the next message of the bus. It returns false once ctx is
+// done or the connection is closed.
+func (m *monitorSession) next(ctx context.Context) (*dbus.Message, bool) {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-m.conn.Context().Done():
+			return nil, false
+		case msg, ok := <-m.received:
+			if !ok {
+				return nil, false
+			}
+			// The bus notifies the monitor that it lost its unique name
+			if destination, _ := msg.Headers[dbus.FieldDestination].Value().(string); destination == m.self {
+				continue
+			}
+			return msg, true
+		}
+	}
+}
+
+// startMonitor opens a monitor session counted against the monitor limits
+func (s *DBusService) startMonitor(ctx context.Context, busType string, rules []string) (*monitorSession, MonitorLimits, error) {
+	if _, err := s.getConnection(busType); err != nil {
+		return nil, MonitorLimits{}, err
+	}
+
+	s.mutex.Lock()
+	limits := s.monitorLimits
+	if s.openMonitors >= limits.MaxSessions {
+		s.mutex.Unlock()
+		return nil, limits, fmt.Errorf("%w: %d monitors open", ErrTooManyMonitors, s.openMonitors)
+	}
+	// Count the monitor while its connection is set up
+	s.openMonitors++
+	s.mutex.Unlock()
+
+	release := func(conn *dbus.Conn) {
+		s.mutex.Lock()
+		s.openMonitors--
+		delete(s.monitors, conn)
+		s.mutex.Unlock()
+	}
+
+	conn, err := s.becomeMonitor(ctx, busType, rules)
+	if err != nil {
+		release(nil)
+		return nil, limits, err
+	}
+
+	s.mutex.Lock()
+	s.monitors[conn] = struct{}{}
+	s.mutex.Unlock()
+
+	// The relays keep up with the bus; clients have their own buffers
+	session := &monitorSession{
+		conn:     conn,
+		self:     connectionName(conn),
+		received: make(chan *dbus.Message, 64),
+	}
+	session.stop = func() {
+		release(conn)
+		conn.Close()
+	}
+	conn.Eavesdrop(session.received)
+
+	return session, limits, nil
+}
+
+// becomeMonitor opens a connection to the bus and turns it into a monitor
+func (s *DBusService) becomeMonitor(ctx context.Context, busType string, rules []string) (*dbus.Conn, error) {
+	var conn *dbus.Conn
+	var err error
+	if busType == "system" {
+		conn, err = dbus.SystemBusPrivate()
+	} else {
+		conn, err = dbus.SessionBusPrivate()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect monitor: %w", err)
+	}
+	if err = conn.Auth(nil); err == nil {
+		err = conn.Hello()
+	}
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to connect monitor: %w", err)
+	}
+
+	if rules == nil {
+		rules = []string{}
+	}
+	err = s.call(ctx, busType, conn.BusObject(), "org.freedesktop.DBus.Monitoring.BecomeMonitor", 0, rules, uint32(0)).Err
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("failed to become monitor: %w", err)
+	}
+
+	return conn, nil
+}
+
+// closeMonitors closes the monitor connections, ending their streams.
+// The caller must hold the service mutex.
+func (s *DBusService) closeMonitors() {
+	for conn := range s.monitors {
+		conn.Close()
+	}
+}
+
+// newMonitorMessage converts a monitored message to its API representation
+func newMonitorMessage(msg *dbus.Message) *model.MonitorMessage {
+	message := &model.MonitorMessage{
+		Type:       monitorMessageTypes[msg.Type],
+		Serial:     msg.Serial(),
+		ReceivedAt: time.Now(),
+	}
+
+	header := func(field dbus.HeaderField) interface{} {
+		value, ok := msg.Headers[field]
+		if !ok {
+			return nil
+		}
+		return value.Value()
+	}
+	message.ReplySerial, _ = header(dbus.FieldReplySerial).(uint32)
+	message.Sender, _ = header(dbus.FieldSender).(string)
+	message.Destination, _ = header(dbus.FieldDestination).(string)
+	if path, ok := header(dbus.FieldPath).(dbus.ObjectPath); ok {
+		message.Path = string(path)
+	}
+	message.Interface, _ = header(dbus.FieldInterface).(string)
+	message.Member, _ = header(dbus.FieldMember).(string)
+	message.ErrorName, _ = header(dbus.FieldErrorName).(string)
+	if signature, ok := header(dbus.FieldSignature).(dbus.Signature); ok {
+		message.Signature = signature.String()
+	}
+
+	if len(msg.Body) > 0 {
+		message.Body = make([]interface{}, len(msg.Body))
+		for i, value := range msg.Body {
+			message.Body[i] = ToJSONArg(value)
+		}
+	}
+	return message
+}
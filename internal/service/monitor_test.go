@@ -0,0 +1,48 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMonitorMessage(t *testing.T) {
+	msg := &dbus.Message{
+		Type:  dbus.TypeMethodCall,
+		Flags: dbus.FlagNoAutoStart,
+		Body:  []interface{}{"com.example.Door", dbus.MakeVariant(uint32(3))},
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldSender:      dbus.MakeVariant(":1.9"),
+			dbus.FieldDestination: dbus.MakeVariant("com.example.Door"),
+			dbus.FieldPath:        dbus.MakeVariant(dbus.ObjectPath("/com/example/Door")),
+			dbus.FieldInterface:   dbus.MakeVariant("org.freedesktop.DBus.Properties"),
+			dbus.FieldMember:      dbus.MakeVariant("Set"),
+			dbus.FieldSignature:   dbus.MakeVariant(dbus.SignatureOf("", dbus.Variant{})),
+		},
+	}
+
+	message := newMonitorMessage(msg)
+
+	assert.Equal(t, "method_call", message.Type)
+	assert.Equal(t, ":1.9", message.Sender)
+	assert.Equal(t, "com.example.Door", message.Destination)
+	assert.Equal(t, "/com/example/Door", message.Path)
+	assert.Equal(t, "org.freedesktop.DBus.Properties", message.Interface)
+	assert.Equal(t, "Set", message.Member)
+	assert.Equal(t, "sv", message.Signature)
+	assert.Equal(t, []interface{}{"com.example.Door", map[string]any{"signature": "u", "value": json.Number("3")}}, message.Body)
+
+	reply := newMonitorMessage(&dbus.Message{
+		Type: dbus.TypeError,
+		Headers: map[dbus.HeaderField]dbus.Variant{
+			dbus.FieldReplySerial: dbus.MakeVariant(uint32(7)),
+			dbus.FieldErrorName:   dbus.MakeVariant("org.freedesktop.DBus.Error.AccessDenied"),
+		},
+	})
+	assert.Equal(t, "error", reply.Type)
+	assert.Equal(t, uint32(7), reply.ReplySerial)
+	assert.Equal(t, "org.freedesktop.DBus.Error.AccessDenied", reply.ErrorName)
+	assert.Nil(t, reply.Body)
+}
@@ -0,0 +1,261 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// requestNameReplies names the replies of RequestName
+var requestNameReplies = map[dbus.RequestNameReply]string{
+	dbus.RequestNameReplyPrimaryOwner: "primary_owner",
+	dbus.RequestNameReplyInQueue:      "in_queue",
+	dbus.RequestNameReplyExists:       "exists",
+	dbus.RequestNameReplyAlreadyOwner: "already_owner",
+}
+
+// releaseNameReplies names the replies of ReleaseName
+var releaseNameReplies = map[dbus.ReleaseNameReply]string{
+	dbus.ReleaseNameReplyReleased:    "released",
+	dbus.ReleaseNameReplyNonExistent: "non_existent",
+	dbus.ReleaseNameReplyNotOwner:    "not_owner",
+}
+
+// RequestName requests a well-known name for the connection of the controller.
+// The name is held until it is released, taken over or the controller stops.
+func (s *DBusService) RequestName(ctx context.Context, busType, name string, flags model.NameFlags) (*model.NameOwnership, error) {
+	conn, err := s.getConnection(busType)
+	if err != nil {
+		return nil, err
+	}
+
+	var requestFlags dbus.RequestNameFlags
+	if flags.AllowReplacement {
+		requestFlags |= dbus.NameFlagAllowReplacement
+	}
+	if flags.ReplaceExisting {
+		requestFlags |= dbus.NameFlagReplaceExisting
+	}
+	if flags.DoNotQueue {
+		requestFlags |= dbus.NameFlagDoNotQueue
+	}
+
+	var reply uint32
+	err = s.call(ctx, busType, conn.BusObject(), "org.freedesktop.DBus.RequestName", 0, name, uint32(requestFlags)).Store(&reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request %s: %w", name, err)
+	}
+
+	ownership := s.nameOwnership(ctx, busType, conn, name, requestNameReplies[dbus.RequestNameReply(reply)])
+	slog.InfoContext(ctx, "Name requested", "bus", busType, "name", name, "reply", ownership.Reply, "owner", ownership.Owner)
+	return ownership, nil
+}
+
+// ReleaseName releases a well-known name owned or queued for by the connection
+// of the controller
+func (s *DBusService) ReleaseName(ctx context.Context, busType, name string) (*model.NameOwnership, error) {
+	conn, err := s.getConnection(busType)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply uint32
+	err = s.call(ctx, busType, conn.BusObject(), "org.freedesktop.DBus.ReleaseName", 0, name).Store(&reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to release %s: %w", name, err)
+	}
+
+	ownership := s.nameOwnership(ctx, busType, conn, name, releaseNameReplies[dbus.ReleaseNameReply(reply)])
+	slog.InfoContext(ctx, "Name released", "bus", busType, "name", name, "reply", ownership.Reply, "owner", ownership.Owner)
+	return ownership, nil
+}
+
+// ListQueuedOwners returns the connections queued for a well-known name
+func (s *DBusService) ListQueuedOwners(ctx context.Context, busType, name string) (*model.NameQueue, error) {
+	conn, err := s.getConnection(busType)
+	if err != nil {
+		return nil, err
+	}
+
+	var owners []string
+	err = s.call(ctx, busType, conn.BusObject(), "org.freedesktop.DBus.ListQueuedOwners", 0, name).Store(&owners)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the owners of %s: %w", name, err)
+	}
+
+	return &model.NameQueue{Name: name, Owners: owners, Connection: connectionName(conn)}, nil
+}
+
+// nameOwnership describes the ownership of a name after a request or release
+func (s *DBusService) nameOwnership(ctx context.Context, busType string, conn *dbus.Conn, name, reply string) *model.NameOwnership {
+	ownership := &model.NameOwnership{Name: name, Reply: reply, Connection: connectionName(conn)}
+	// A name without owner answers NameHasNoOwner
+	if owner, err := s.GetNameOwner(ctx, busType, name); err == nil {
+		ownership.Owner = owner
+	}
+	return ownership
+}
+
+// connectionName returns the unique name of a connection
+func connectionName(conn *dbus.Conn) string {
+	if names := conn.Names(); len(names) > 0 {
+		return names[0]
+	}
+	return ""
+}
+
+// ErrInvalidNamePattern is returned for malformed name patterns
+var ErrInvalidNamePattern = errors.New("invalid name pattern")
+
+// busName is the name of the message bus, sending the name owner signals
+const busName = "org.freedesktop.DBus"
+
+// WatchNameOwners delivers the name owner changes of a bus: NameOwnerChanged
+// signals decoded as appeared, disappeared or replaced, and the NameAcquired
+// and NameLost signals of the controller connection. Pattern selects the names
+// with shell wildcards, e.g. com.example.*; unique names are only delivered
+// when unique is set. The returned channel is closed when ctx is done or the
+// service shuts down.
+func (s *DBusService) WatchNameOwners(ctx context.Context, busType, pattern string, unique bool) (<-chan *model.NameOwnerEvent, error) {
+	if pattern == "" {
+		pattern = "*"
+	}
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidNamePattern, pattern)
+	}
+
+	conn, err := s.getConnection(busType)
+	if err != nil {
+		return nil, err
+	}
+	self := connectionName(conn)
+
+	watch, err := s.WatchSignals(ctx, busType, SignalMatch{Sender: busName, Interface: busName}, signalStreamBuffer)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	s.streams[watch] = struct{}{}
+	s.mutex.Unlock()
+
+	events := make(chan *model.NameOwnerEvent)
+
+	go func() {
+		defer close(events)
+		defer func() {
+			s.mutex.Lock()
+			delete(s.streams, watch)
+			s.mutex.Unlock()
+			watch.Close()
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig, ok := <-watch.C:
+				if !ok {
+					return
+				}
+				event := newNameOwnerEvent(busType, self, sig)
+				if event == nil || (!unique && strings.HasPrefix(event.Name, ":")) {
+					continue
+				}
+				if matched, _ := path.Match(pattern, event.Name); !matched {
+					continue
+				}
+				select {
+				case events <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// WaitForName waits until a name has an owner and returns the owner.
+// It returns the error of ctx when ctx is done first.
+func (s *DBusService) WaitForName(ctx context.Context, busType, name string) (string, error) {
+	// Watch before looking the owner up, so that no change is missed in between
+	watch, err := s.WatchSignals(ctx, busType, SignalMatch{Sender: busName, Interface: busName, Member: "NameOwnerChanged"}, signalStreamBuffer)
+	if err != nil {
+		return "", err
+	}
+	defer watch.Close()
+
+	owner, err := s.GetNameOwner(ctx, busType, name)
+	if err == nil {
+		return owner, nil
+	}
+	if ErrorName(err) != "org.freedesktop.DBus.Error.NameHasNoOwner" {
+		return "", err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case sig, ok := <-watch.C:
+			if !ok {
+				return "", fmt.Errorf("stopped waiting for %s: bus connection closed", name)
+			}
+			event := newNameOwnerEvent(busType, "", sig)
+			if event != nil && event.Name == name && event.NewOwner != "" {
+				return event.NewOwner, nil
+			}
+		}
+	}
+}
+
+// newNameOwnerEvent decodes a name owner signal of the bus, or returns nil for
+// other signals. Self is the unique name of the connection receiving the signal.
+func newNameOwnerEvent(busType, self string, sig *dbus.Signal) *model.NameOwnerEvent {
+	if sig.Sender != busName || len(sig.Body) == 0 {
+		return nil
+	}
+	name, ok := sig.Body[0].(string)
+	if !ok {
+		return nil
+	}
+
+	event := &model.NameOwnerEvent{Bus: busType, Name: name, ReceivedAt: time.Now()}
+	switch sig.Name {
+	case busName + ".NameOwnerChanged":
+		if len(sig.Body) != 3 {
+			return nil
+		}
+		event.OldOwner, _ = sig.Body[1].(string)
+		event.NewOwner, _ = sig.Body[2].(string)
+		switch {
+		case event.OldOwner == "" && event.NewOwner == "":
+			return nil
+		case event.OldOwner == "":
+			event.Change = "appeared"
+		case event.NewOwner == "":
+			event.Change = "disappeared"
+		default:
+			event.Change = "replaced"
+		}
+	case busName + ".NameAcquired":
+		event.Change = "acquired"
+		event.NewOwner = self
+	case busName + ".NameLost":
+		event.Change = "lost"
+		event.OldOwner = self
+	default:
+		return nil
+	}
+	return event
+}
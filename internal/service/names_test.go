@@ -0,0 +1,41 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewNameOwnerEvent(t *testing.T) {
+	changed := func(name, oldOwner, newOwner string) *dbus.Signal {
+		return &dbus.Signal{Sender: busName, Name: busName + ".NameOwnerChanged", Body: []interface{}{name, oldOwner, newOwner}}
+	}
+
+	tests := []struct {
+		signal   *dbus.Signal
+		change   string
+		oldOwner string
+		newOwner string
+	}{
+		{changed("com.example.Test", "", ":1.9"), "appeared", "", ":1.9"},
+		{changed("com.example.Test", ":1.9", ""), "disappeared", ":1.9", ""},
+		{changed("com.example.Test", ":1.9", ":1.12"), "replaced", ":1.9", ":1.12"},
+		{&dbus.Signal{Sender: busName, Name: busName + ".NameAcquired", Body: []interface{}{"com.example.Test"}}, "acquired", "", ":1.7"},
+		{&dbus.Signal{Sender: busName, Name: busName + ".NameLost", Body: []interface{}{"com.example.Test"}}, "lost", ":1.7", ""},
+	}
+	for _, tt := range tests {
+		event := newNameOwnerEvent("session", ":1.7", tt.signal)
+		require.NotNil(t, event, tt.change)
+		assert.Equal(t, "session", event.Bus)
+		assert.Equal(t, "com.example.Test", event.Name)
+		assert.Equal(t, tt.change, event.Change)
+		assert.Equal(t, tt.oldOwner, event.OldOwner)
+		assert.Equal(t, tt.newOwner, event.NewOwner)
+	}
+
+	// Signals of other senders and members are not name owner changes
+	assert.Nil(t, newNameOwnerEvent("session", ":1.7", &dbus.Signal{Sender: ":1.9", Name: busName + ".NameOwnerChanged", Body: []interface{}{"com.example.Test", "", ":1.9"}}))
+	assert.Nil(t, newNameOwnerEvent("session", ":1.7", &dbus.Signal{Sender: busName, Name: busName + ".PropertiesChanged", Body: []interface{}{"x"}}))
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// procRoot is the mount point of the proc filesystem
+var procRoot = "/proc"
+
+// clockTicks is the unit of the process times in /proc (USER_HZ), 100 on Linux
+const clockTicks = 100
+
+// readProcess reads the details of a process from /proc. It returns nil when
+// the process does not exist; details that cannot be read are left empty.
+func readProcess(pid uint32) *model.ProcessInfo {
+	dir := filepath.Join(procRoot, strconv.FormatUint(uint64(pid), 10))
+	if _, err := os.Stat(dir); err != nil {
+		return nil
+	}
+
+	process := &model.ProcessInfo{PID: pid}
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil && len(cmdline) > 0 {
+		process.Cmdline = strings.Split(string(bytes.TrimSuffix(cmdline, []byte{0})), "\x00")
+	}
+	if exe, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+		process.Exe = exe
+	}
+	if cgroup, err := os.ReadFile(filepath.Join(dir, "cgroup")); err == nil {
+		process.Cgroup = cgroupPath(string(cgroup))
+	}
+	if stat, err := os.ReadFile(filepath.Join(dir, "stat")); err == nil {
+		process.StartTime = processStartTime(string(stat))
+	}
+	return process
+}
+
+// cgroupPath returns the path of a process in the unified cgroup hierarchy,
+// or in the systemd hierarchy on hosts without one
+func cgroupPath(cgroup string) string {
+	var systemd string
+	for _, line := range strings.Split(cgroup, "\n") {
+		fields := strings.SplitN(line, ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		if fields[0] == "0" && fields[1] == "" {
+			return fields[2]
+		}
+		if fields[1] == "name=systemd" {
+			systemd = fields[2]
+		}
+	}
+	return systemd
+}
+
+// processStartTime returns the start time of a process from its stat file
+// and the boot time of the system
+func processStartTime(stat string) *time.Time {
+	// The command name may contain spaces and parentheses; fields follow the last one
+	end := strings.LastIndexByte(stat, ')')
+	if end < 0 {
+		return nil
+	}
+	fields := strings.Fields(stat[end+1:])
+	// starttime is the 22nd field; fields start at the 3rd
+	if len(fields) < 20 {
+		return nil
+	}
+	ticks, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	bootTime, ok := systemBootTime()
+	if !ok {
+		return nil
+	}
+	start := bootTime.Add(time.Duration(ticks) * time.Second / clockTicks).UTC()
+	return &start
+}
+
+// systemBootTime reads the boot time of the system from /proc/stat
+func systemBootTime() (time.Time, bool) {
+	stat, err := os.ReadFile(filepath.Join(procRoot, "stat"))
+	if err != nil {
+		return time.Time{}, false
+	}
+	for _, line := range strings.Split(string(stat), "\n") {
+		if value, ok := strings.CutPrefix(line, "btime "); ok {
+			seconds, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+			if err != nil {
+				return time.Time{}, false
+			}
+			return time.Unix(seconds, 0), true
+		}
+	}
+	return time.Time{}, false
+}
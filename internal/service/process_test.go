@@ -0,0 +1,47 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCgroupPath(t *testing.T) {
+	assert.Equal(t, "/user.slice/app.service", cgroupPath("0::/user.slice/app.service\n"))
+	assert.Equal(t, "/system.slice/app.service",
+		cgroupPath("12:cpu,cpuacct:/system.slice\n1:name=systemd:/system.slice/app.service\n"))
+	assert.Equal(t, "", cgroupPath(""))
+}
+
+func TestReadProcess(t *testing.T) {
+	root := t.TempDir()
+	procRoot = root
+	defer func() { procRoot = "/proc" }()
+
+	dir := filepath.Join(root, "42")
+	require.NoError(t, os.Mkdir(dir, 0o755))
+	write := func(name, content string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	write("cmdline", "/usr/bin/app\x00--flag\x00value\x00")
+	write("cgroup", "0::/user.slice/app.service\n")
+	// The command name holds a space and a parenthesis; starttime is 250 ticks
+	write("stat", "42 (my app) ) S 1 42 42 0 -1 4194560 0 0 0 0 0 0 0 0 20 0 1 0 250 0 0")
+	require.NoError(t, os.Symlink("/usr/bin/app", filepath.Join(dir, "exe")))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "stat"), []byte("cpu 1 2 3\nbtime 1700000000\n"), 0o644))
+
+	process := readProcess(42)
+	require.NotNil(t, process)
+	assert.Equal(t, uint32(42), process.PID)
+	assert.Equal(t, []string{"/usr/bin/app", "--flag", "value"}, process.Cmdline)
+	assert.Equal(t, "/usr/bin/app", process.Exe)
+	assert.Equal(t, "/user.slice/app.service", process.Cgroup)
+	require.NotNil(t, process.StartTime)
+	assert.Equal(t, time.Unix(1700000002, 500000000).UTC(), *process.StartTime)
+
+	assert.Nil(t, readProcess(43))
+}
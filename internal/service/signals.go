@@ -0,0 +1,574 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// SignalMatch selects the signals delivered to a watch.
+// Empty fields match any value.
+type SignalMatch struct {
+	Sender    string
+	Path      string
+	Interface string
+	Member    string
+}
+
+// rule returns the bus match rule for the signal match
+func (m SignalMatch) rule() string {
+	parts := []string{"type='signal'"}
+	if m.Sender != "" {
+		parts = append(parts, fmt.Sprintf("sender='%s'", m.Sender))
+	}
+	if m.Path != "" {
+		parts = append(parts, fmt.Sprintf("path='%s'", m.Path))
+	}
+	if m.Interface != "" {
+		parts = append(parts, fmt.Sprintf("interface='%s'", m.Interface))
+	}
+	if m.Member != "" {
+		parts = append(parts, fmt.Sprintf("member='%s'", m.Member))
+	}
+	return strings.Join(parts, ",")
+}
+
+// ownerRule returns the bus match rule for the owner changes of the sender,
+// or "" when the sender is a unique name or the bus itself
+func (m SignalMatch) ownerRule() string {
+	if m.Sender == "" || m.Sender == busName || strings.HasPrefix(m.Sender, ":") {
+		return ""
+	}
+	return fmt.Sprintf("type='signal',sender='%s',interface='%s',member='NameOwnerChanged',arg0='%s'", busName, busName, m.Sender)
+}
+
+// SignalWatch receives the signals selected by a SignalMatch.
+// C is closed when the watch is closed or the bus connection goes away.
+type SignalWatch struct {
+	C <-chan *dbus.Signal
+
+	ch     chan *dbus.Signal
+	match  SignalMatch
+	owner  string // Unique name owning match.Sender, followed through NameOwnerChanged
+	router *signalRouter
+	closed bool
+}
+
+// matches reports whether the signal is selected by the watch
+func (w *SignalWatch) matches(sig *dbus.Signal) bool {
+	if w.match.Sender != "" && sig.Sender != w.match.Sender && sig.Sender != w.owner {
+		return false
+	}
+	if w.match.Path != "" && string(sig.Path) != w.match.Path {
+		return false
+	}
+
+	dot := strings.LastIndex(sig.Name, ".")
+	if dot < 0 {
+		return false
+	}
+	if w.match.Interface != "" && sig.Name[:dot] != w.match.Interface {
+		return false
+	}
+	if w.match.Member != "" && sig.Name[dot+1:] != w.match.Member {
+		return false
+	}
+
+	return true
+}
+
+// Close removes the match rule and stops delivery to the watch
+func (w *SignalWatch) Close() {
+	if w.router.remove(w) {
+		removeMatchRule(w.router.conn, w.match.rule())
+		if rule := w.match.ownerRule(); rule != "" {
+			removeMatchRule(w.router.conn, rule)
+		}
+	}
+}
+
+// matchRuleTimeout bounds the AddMatch and RemoveMatch calls, so a stalled bus
+// does not hold up subscriptions and shutdown
+const matchRuleTimeout = 5 * time.Second
+
+// removeMatchRule removes a match rule added by a watch or subscription.
+// The rule is gone with the connection when the call fails.
+func removeMatchRule(conn *dbus.Conn, rule string) {
+	ctx, cancel := context.WithTimeout(context.Background(), matchRuleTimeout)
+	defer cancel()
+
+	if err := conn.BusObject().CallWithContext(ctx, "org.freedesktop.DBus.RemoveMatch", 0, rule).Err; err != nil {
+		slog.Debug("Failed to remove match rule", "rule", rule, "error", err)
+	}
+}
+
+// signalRouter fans the signals received on one connection out to watches
+type signalRouter struct {
+	conn    *dbus.Conn
+	channel chan *dbus.Signal // Closed by the connection when it goes away
+	mu      sync.Mutex
+	watches map[*SignalWatch]struct{}
+	quit    chan struct{}
+	done    chan struct{}
+}
+
+// newSignalRouter starts routing the signals of conn. closed is called once
+// the connection goes away, unless the router was stopped before.
+func newSignalRouter(conn *dbus.Conn, closed func(*signalRouter)) *signalRouter {
+	router := &signalRouter{
+		conn:    conn,
+		channel: make(chan *dbus.Signal, 100),
+		watches: make(map[*SignalWatch]struct{}),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	conn.Signal(router.channel)
+	go func() {
+		if router.run() {
+			closed(router)
+		}
+	}()
+
+	return router
+}
+
+// run delivers signals until the router is stopped or the connection closes
+// the router channel, reporting the latter. A watch whose buffer is full
+// misses the signal rather than blocking the others.
+func (r *signalRouter) run() (disconnected bool) {
+	defer close(r.done)
+	defer r.closeWatches()
+
+	for {
+		select {
+		case sig, ok := <-r.channel:
+			if !ok {
+				return true
+			}
+			r.mu.Lock()
+			r.updateOwners(sig)
+			for watch := range r.watches {
+				if !watch.matches(sig) {
+					continue
+				}
+				select {
+				case watch.ch <- sig:
+				default:
+				}
+			}
+			r.mu.Unlock()
+		case <-r.quit:
+			return false
+		}
+	}
+}
+
+// closeWatches closes the channels of the remaining watches
+func (r *signalRouter) closeWatches() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for watch := range r.watches {
+		watch.closed = true
+		close(watch.ch)
+	}
+	r.watches = make(map[*SignalWatch]struct{})
+}
+
+// updateOwners follows the watched senders to their new unique name when
+// the service restarts, so its signals keep matching
+func (r *signalRouter) updateOwners(sig *dbus.Signal) {
+	if sig.Sender != busName || sig.Name != busName+".NameOwnerChanged" || len(sig.Body) != 3 {
+		return
+	}
+	name, _ := sig.Body[0].(string)
+	owner, _ := sig.Body[2].(string)
+
+	for watch := range r.watches {
+		if watch.match.Sender == name {
+			watch.owner = owner
+		}
+	}
+}
+
+func (r *signalRouter) add(watch *SignalWatch) {
+	r.mu.Lock()
+	r.watches[watch] = struct{}{}
+	r.mu.Unlock()
+}
+
+// remove unregisters the watch and closes its channel.
+// It returns false if the watch was already closed.
+func (r *signalRouter) remove(watch *SignalWatch) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if watch.closed {
+		return false
+	}
+	watch.closed = true
+	delete(r.watches, watch)
+	close(watch.ch)
+
+	return true
+}
+
+// stop unregisters the router from the connection and waits for it to end.
+// The router channel is left to the connection, which closes it when it goes
+// away before the router is unregistered.
+func (r *signalRouter) stop() {
+	r.conn.RemoveSignal(r.channel)
+	close(r.quit)
+	<-r.done
+}
+
+// WatchSignals delivers the signals selected by match on the specified bus
+func (s *DBusService) WatchSignals(ctx context.Context, busType string, match SignalMatch, buffer int) (*SignalWatch, error) {
+	conn, err := s.getConnection(busType)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.addMatch(ctx, busType, conn, match.rule()); err != nil {
+		return nil, err
+	}
+
+	watch := &SignalWatch{
+		ch:    make(chan *dbus.Signal, buffer),
+		match: match,
+	}
+	watch.C = watch.ch
+
+	// Signals carry the unique name of the sender, not the well-known name.
+	// The owner is resolved now and followed when the service restarts.
+	if rule := match.ownerRule(); rule != "" {
+		if err := s.addMatch(ctx, busType, conn, rule); err != nil {
+			removeMatchRule(conn, match.rule())
+			return nil, err
+		}
+		if owner, err := s.GetNameOwner(ctx, busType, match.Sender); err == nil {
+			watch.owner = owner
+		}
+	}
+
+	s.mutex.Lock()
+	router, ok := s.routers[busType]
+	if !ok {
+		router = newSignalRouter(conn, func(r *signalRouter) { s.removeRouter(busType, r) })
+		s.routers[busType] = router
+	}
+	s.mutex.Unlock()
+
+	watch.router = router
+	router.add(watch)
+
+	return watch, nil
+}
+
+// removeRouter forgets the router of a bus whose connection went away
+func (s *DBusService) removeRouter(busType string, router *signalRouter) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.routers[busType] == router {
+		delete(s.routers, busType)
+	}
+}
+
+// signalStreamBuffer is the number of signals queued for a slow stream client
+const signalStreamBuffer = 64
+
+// SubscribeToSignal subscribes to a D-Bus signal.
+// Subscribing again to the same signal returns the existing subscription.
+func (s *DBusService) SubscribeToSignal(ctx context.Context, busType, serviceName, interfaceName, signalName string) (*model.SignalSubscription, error) {
+	conn, err := s.getConnection(busType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a unique subscription ID
+	subscriptionID := fmt.Sprintf("%s:%s:%s:%s", busType, serviceName, interfaceName, signalName)
+
+	s.mutex.RLock()
+	handler, ok := s.subscriptions[subscriptionID]
+	s.mutex.RUnlock()
+	if ok {
+		return handler.subscription, nil
+	}
+
+	match := SignalMatch{
+		Sender:    serviceName,
+		Interface: interfaceName,
+		Member:    signalName,
+	}
+
+	// The rule is added without the lock, a stalled bus holding up only this call
+	if err := s.addMatch(ctx, busType, conn, match.rule()); err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Subscribed concurrently: the bus counts each AddMatch, so the extra rule goes
+	if handler, ok := s.subscriptions[subscriptionID]; ok {
+		go removeMatchRule(conn, match.rule())
+		return handler.subscription, nil
+	}
+
+	handler = &SignalHandler{
+		subscription: &model.SignalSubscription{
+			ID:        subscriptionID,
+			BusType:   busType,
+			Service:   serviceName,
+			Interface: interfaceName,
+			Signal:    signalName,
+			Active:    true,
+			CreatedAt: time.Now(),
+		},
+		match:   match,
+		streams: make(map[*SignalWatch]struct{}),
+	}
+	s.subscriptions[subscriptionID] = handler
+
+	slog.InfoContext(ctx, "Subscribed to signal", "subscription", subscriptionID, "match_rule", match.rule())
+
+	return handler.subscription, nil
+}
+
+// ListSubscriptions returns the active signal subscriptions
+func (s *DBusService) ListSubscriptions(ctx context.Context) ([]model.SignalSubscription, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	subscriptions := make([]model.SignalSubscription, 0, len(s.subscriptions))
+	for _, handler := range s.subscriptions {
+		subscriptions = append(subscriptions, *handler.subscription)
+	}
+	sort.Slice(subscriptions, func(i, j int) bool {
+		return subscriptions[i].ID < subscriptions[j].ID
+	})
+
+	return subscriptions, nil
+}
+
+// Unsubscribe removes a signal subscription and ends its streams
+func (s *DBusService) Unsubscribe(ctx context.Context, subscriptionID string) error {
+	s.mutex.Lock()
+	handler, ok := s.subscriptions[subscriptionID]
+	if ok {
+		delete(s.subscriptions, subscriptionID)
+	}
+	s.mutex.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrSubscriptionNotFound, subscriptionID)
+	}
+
+	s.mutex.Lock()
+	streams := handler.takeStreams()
+	s.mutex.Unlock()
+	closeWatches(streams)
+
+	if err := s.removeMatch(ctx, handler); err != nil {
+		return err
+	}
+
+	slog.InfoContext(ctx, "Unsubscribed from signal", "subscription", subscriptionID)
+
+	return nil
+}
+
+// StreamSubscription delivers the signals of a subscription until ctx is done,
+// the subscription is removed or the service shuts down.
+// The returned channel is closed when the stream ends.
+func (s *DBusService) StreamSubscription(ctx context.Context, subscriptionID string) (<-chan *model.SignalEvent, error) {
+	s.mutex.RLock()
+	handler, ok := s.subscriptions[subscriptionID]
+	s.mutex.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrSubscriptionNotFound, subscriptionID)
+	}
+
+	watch, err := s.WatchSignals(ctx, handler.subscription.BusType, handler.match, signalStreamBuffer)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	if _, ok := s.subscriptions[subscriptionID]; !ok {
+		s.mutex.Unlock()
+		watch.Close()
+		return nil, fmt.Errorf("%w: %s", ErrSubscriptionNotFound, subscriptionID)
+	}
+	handler.streams[watch] = struct{}{}
+	s.mutex.Unlock()
+
+	events := make(chan *model.SignalEvent)
+
+	go func() {
+		defer close(events)
+		defer func() {
+			s.mutex.Lock()
+			delete(handler.streams, watch)
+			s.mutex.Unlock()
+			watch.Close()
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig, ok := <-watch.C:
+				if !ok {
+					return
+				}
+				select {
+				case events <- newSignalEvent(subscriptionID, sig):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// StreamSignals delivers the signals selected by match until ctx is done or
+// the service shuts down, without a subscription: the match rule is removed
+// when the stream ends. The returned channel is closed when the stream ends.
+func (s *DBusService) StreamSignals(ctx context.Context, busType string, match SignalMatch) (<-chan *model.SignalEvent, error) {
+	watch, err := s.WatchSignals(ctx, busType, match, signalStreamBuffer)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mutex.Lock()
+	s.streams[watch] = struct{}{}
+	s.mutex.Unlock()
+
+	events := make(chan *model.SignalEvent)
+
+	go func() {
+		defer close(events)
+		defer func() {
+			s.mutex.Lock()
+			delete(s.streams, watch)
+			s.mutex.Unlock()
+			watch.Close()
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case sig, ok := <-watch.C:
+				if !ok {
+					return
+				}
+				select {
+				case events <- newSignalEvent("", sig):
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// CloseStreams ends every subscription stream, name owner stream, monitor and
+// job watch, leaving the subscriptions and jobs in place. It is used on
+// shutdown so that long-lived streams do not hold the server open.
+func (s *DBusService) CloseStreams() {
+	s.jobs.closeWatches()
+
+	s.mutex.Lock()
+	var streams []*SignalWatch
+	for _, handler := range s.subscriptions {
+		streams = append(streams, handler.takeStreams()...)
+	}
+	for watch := range s.streams {
+		streams = append(streams, watch)
+		delete(s.streams, watch)
+	}
+	s.closeMonitors()
+	s.mutex.Unlock()
+
+	// Match rules are removed without the lock
+	closeWatches(streams)
+}
+
+// takeStreams removes the stream watches of the subscription and returns them
+// to be closed once the service mutex, which the caller must hold, is released
+func (h *SignalHandler) takeStreams() []*SignalWatch {
+	streams := make([]*SignalWatch, 0, len(h.streams))
+	for watch := range h.streams {
+		streams = append(streams, watch)
+		delete(h.streams, watch)
+	}
+	return streams
+}
+
+// closeWatches closes the watches, removing their match rules
+func closeWatches(watches []*SignalWatch) {
+	for _, watch := range watches {
+		watch.Close()
+	}
+}
+
+// addMatch adds a bus match rule within matchRuleTimeout
+func (s *DBusService) addMatch(ctx context.Context, busType string, conn *dbus.Conn, rule string) error {
+	ctx, cancel := context.WithTimeout(ctx, matchRuleTimeout)
+	defer cancel()
+
+	if err := s.call(ctx, busType, conn.BusObject(), "org.freedesktop.DBus.AddMatch", 0, rule).Err; err != nil {
+		return fmt.Errorf("failed to add match rule: %w", err)
+	}
+	return nil
+}
+
+// removeMatch removes the bus match rule of the subscription within matchRuleTimeout
+func (s *DBusService) removeMatch(ctx context.Context, handler *SignalHandler) error {
+	conn, err := s.getConnection(handler.subscription.BusType)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, matchRuleTimeout)
+	defer cancel()
+
+	err = s.call(ctx, handler.subscription.BusType, conn.BusObject(), "org.freedesktop.DBus.RemoveMatch", 0, handler.match.rule()).Err
+	if err != nil {
+		return fmt.Errorf("failed to remove match rule: %w", err)
+	}
+
+	return nil
+}
+
+// newSignalEvent converts a received signal to its API representation
+func newSignalEvent(subscriptionID string, sig *dbus.Signal) *model.SignalEvent {
+	iface, member := splitMember(sig.Name)
+
+	return &model.SignalEvent{
+		SubscriptionID: subscriptionID,
+		Sender:         sig.Sender,
+		Path:           string(sig.Path),
+		Interface:      iface,
+		Signal:         member,
+		Body:           sig.Body,
+		ReceivedAt:     time.Now(),
+	}
+}
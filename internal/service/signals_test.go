@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignalMatch_Rule(t *testing.T) {
+	match := SignalMatch{
+		Sender:    "com.example.Service",
+		Path:      "/com/example",
+		Interface: "org.freedesktop.DBus.Properties",
+		Member:    "PropertiesChanged",
+	}
+
+	assert.Equal(t,
+		"type='signal',sender='com.example.Service',path='/com/example',interface='org.freedesktop.DBus.Properties',member='PropertiesChanged'",
+		match.rule())
+	assert.Equal(t, "type='signal'", SignalMatch{}.rule())
+}
+
+func TestSignalMatch_OwnerRule(t *testing.T) {
+	assert.Equal(t,
+		"type='signal',sender='org.freedesktop.DBus',interface='org.freedesktop.DBus',member='NameOwnerChanged',arg0='com.example.Service'",
+		SignalMatch{Sender: "com.example.Service"}.ownerRule())
+	assert.Empty(t, SignalMatch{}.ownerRule())
+	assert.Empty(t, SignalMatch{Sender: ":1.42"}.ownerRule())
+	assert.Empty(t, SignalMatch{Sender: "org.freedesktop.DBus"}.ownerRule())
+}
+
+func TestSignalWatch_Matches(t *testing.T) {
+	watch := &SignalWatch{
+		match: SignalMatch{
+			Sender:    "com.example.Service",
+			Interface: "com.example.Service",
+			Member:    "Changed",
+		},
+		owner: ":1.42",
+	}
+
+	assert.True(t, watch.matches(&dbus.Signal{Sender: ":1.42", Path: "/", Name: "com.example.Service.Changed"}))
+	assert.True(t, watch.matches(&dbus.Signal{Sender: "com.example.Service", Path: "/", Name: "com.example.Service.Changed"}))
+	assert.False(t, watch.matches(&dbus.Signal{Sender: ":1.7", Path: "/", Name: "com.example.Service.Changed"}))
+	assert.False(t, watch.matches(&dbus.Signal{Sender: ":1.42", Path: "/", Name: "com.example.Service.Removed"}))
+	assert.False(t, watch.matches(&dbus.Signal{Sender: ":1.42", Path: "/", Name: "Changed"}))
+}
+
+func TestSignalRouter_UpdateOwners(t *testing.T) {
+	router := &signalRouter{watches: make(map[*SignalWatch]struct{})}
+	watch := &SignalWatch{
+		match: SignalMatch{Sender: "com.example.Service"},
+		owner: ":1.42",
+	}
+	other := &SignalWatch{
+		match: SignalMatch{Sender: "com.example.Other"},
+		owner: ":1.7",
+	}
+	router.add(watch)
+	router.add(other)
+
+	// The service restarts under a new unique name
+	router.updateOwners(&dbus.Signal{
+		Sender: "org.freedesktop.DBus",
+		Name:   "org.freedesktop.DBus.NameOwnerChanged",
+		Body:   []interface{}{"com.example.Service", ":1.42", ""},
+	})
+	router.updateOwners(&dbus.Signal{
+		Sender: "org.freedesktop.DBus",
+		Name:   "org.freedesktop.DBus.NameOwnerChanged",
+		Body:   []interface{}{"com.example.Service", "", ":1.50"},
+	})
+
+	assert.Equal(t, ":1.50", watch.owner)
+	assert.Equal(t, ":1.7", other.owner)
+	assert.True(t, watch.matches(&dbus.Signal{Sender: ":1.50", Path: "/", Name: "com.example.Service.Changed"}))
+	assert.False(t, watch.matches(&dbus.Signal{Sender: ":1.42", Path: "/", Name: "com.example.Service.Changed"}))
+
+	// Only the bus can report owner changes
+	router.updateOwners(&dbus.Signal{
+		Sender: ":1.99",
+		Name:   "org.freedesktop.DBus.NameOwnerChanged",
+		Body:   []interface{}{"com.example.Service", ":1.50", ":1.99"},
+	})
+	assert.Equal(t, ":1.50", watch.owner)
+}
+
+func TestSignalRouter_RemoveClosesWatch(t *testing.T) {
+	router := &signalRouter{watches: make(map[*SignalWatch]struct{})}
+	watch := &SignalWatch{ch: make(chan *dbus.Signal, 1)}
+	watch.C = watch.ch
+
+	router.add(watch)
+	assert.True(t, router.remove(watch))
+	assert.False(t, router.remove(watch))
+
+	_, open := <-watch.C
+	assert.False(t, open)
+}
+
+func TestDBusService_UnknownSubscription(t *testing.T) {
+	service := &DBusService{
+		subscriptions: make(map[string]*SignalHandler),
+		routers:       make(map[string]*signalRouter),
+	}
+
+	err := service.Unsubscribe(context.Background(), "session:a:b:c")
+	assert.ErrorIs(t, err, ErrSubscriptionNotFound)
+
+	_, err = service.StreamSubscription(context.Background(), "session:a:b:c")
+	assert.ErrorIs(t, err, ErrSubscriptionNotFound)
+
+	subscriptions, err := service.ListSubscriptions(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, subscriptions)
+}
+
+func TestDBusService_Integration_CloseAfterDisconnect(t *testing.T) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+	service := &DBusService{
+		sessionConn:   conn,
+		subscriptions: make(map[string]*SignalHandler),
+		routers:       make(map[string]*signalRouter),
+		streams:       make(map[*SignalWatch]struct{}),
+		monitors:      make(map[*dbus.Conn]struct{}),
+		jobs:          newJobTable(DefaultJobLimits),
+	}
+
+	watch, err := service.WatchSignals(context.Background(), "session", SignalMatch{Interface: "com.example.Disconnect"}, 1)
+	require.NoError(t, err)
+
+	// The connection closes the router channel as it goes away
+	conn.Close()
+	_, open := <-watch.C
+	assert.False(t, open)
+	assert.Eventually(t, func() bool {
+		service.mutex.RLock()
+		defer service.mutex.RUnlock()
+		return len(service.routers) == 0
+	}, time.Second, 10*time.Millisecond)
+
+	assert.NotPanics(t, service.Close)
+}
+
+func TestSignalRouter_StopAfterDisconnect(t *testing.T) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+
+	disconnected := make(chan struct{})
+	router := newSignalRouter(conn, func(*signalRouter) { close(disconnected) })
+	conn.Close()
+	<-disconnected
+
+	assert.NotPanics(t, router.stop)
+}
+
+func TestDBusService_Integration_ConcurrentSubscribe(t *testing.T) {
+	service := NewDBusService()
+	defer service.Close()
+	if _, err := service.getConnection("session"); err != nil {
+		t.Skipf("Session D-Bus not available: %v", err)
+	}
+	ctx := context.Background()
+
+	// The match rules are added without the service lock; one subscription wins
+	ids := make(chan string, 8)
+	for i := 0; i < cap(ids); i++ {
+		go func() {
+			subscription, err := service.SubscribeToSignal(ctx, "session", "com.example.Concurrent", "com.example.Concurrent", "Changed")
+			if assert.NoError(t, err) {
+				ids <- subscription.ID
+				return
+			}
+			ids <- ""
+		}()
+	}
+	for i := 0; i < cap(ids); i++ {
+		assert.Equal(t, "session:com.example.Concurrent:com.example.Concurrent:Changed", <-ids)
+	}
+
+	subscriptions, err := service.ListSubscriptions(ctx)
+	require.NoError(t, err)
+	assert.Len(t, subscriptions, 1)
+
+	events, err := service.StreamSubscription(ctx, subscriptions[0].ID)
+	require.NoError(t, err)
+	require.NoError(t, service.Unsubscribe(ctx, subscriptions[0].ID))
+	_, open := <-events
+	assert.False(t, open)
+}
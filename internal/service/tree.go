@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// IntrospectTree introspects the objects below root, breadth first and up to
+// limit objects, skipping objects that fail to introspect except root itself
+func IntrospectTree(ctx context.Context, dbusService DBusServiceInterface, busType, serviceName, root string, limit int) ([]*model.IntrospectionResult, error) {
+	objects := make([]*model.IntrospectionResult, 0)
+	queue := []string{root}
+
+	for len(queue) > 0 {
+		if len(objects) == limit {
+			slog.WarnContext(ctx, "Object tree truncated", "service", serviceName, "limit", limit)
+			break
+		}
+
+		objectPath := queue[0]
+		queue = queue[1:]
+
+		result, err := dbusService.IntrospectObject(ctx, busType, serviceName, objectPath)
+		if err != nil {
+			if objectPath == root {
+				return nil, err
+			}
+			slog.DebugContext(ctx, "Skipping object", "service", serviceName, "path", objectPath, "error", err)
+			continue
+		}
+
+		objects = append(objects, result)
+		if result.ParsedData != nil {
+			for _, node := range result.ParsedData.Nodes {
+				queue = append(queue, node.Path)
+			}
+		}
+	}
+
+	return objects, nil
+}
@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/mesbrj/dbus-controller/internal/config"
+)
+
+func TestSetup_Disabled(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TracingConfig{})
+
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
+
+func TestSetup_UnknownExporter(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.TracingConfig{Enabled: true, Exporter: "zipkin"})
+
+	assert.Nil(t, shutdown)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown trace exporter")
+}
+
+func TestMiddleware_ContinuesIncomingTrace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "traces.json")
+	shutdown, err := Setup(context.Background(), config.TracingConfig{
+		Enabled:     true,
+		Exporter:    "file",
+		File:        path,
+		ServiceName: "dbus-controller-test",
+		SampleRatio: 1,
+	})
+	require.NoError(t, err)
+
+	var spanContext trace.SpanContext
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		spanContext = trace.SpanContextFromContext(r.Context())
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/buses", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.NoError(t, shutdown(context.Background()))
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spanContext.TraceID().String())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "4bf92f3577b34da6a3ce929d0e0e4736")
+	assert.Contains(t, string(data), "dbus-controller-test")
+}
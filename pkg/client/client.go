@@ -0,0 +1,503 @@
+// Package client provides a Go client for the D-Bus Controller REST API.
+//
+// The client mirrors the D-Bus operations of the controller: every method
+// takes a context, returns the API types of the model package, and reports
+// failures as *APIError or *DBusError values.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// Client calls the D-Bus Controller API
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how many times idempotent requests are retried after a
+// transport error or a 502, 503 or 504 response, and the initial backoff
+// between attempts. The backoff doubles after every attempt.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// New creates a client for the controller listening at baseURL
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid base URL: scheme must be http or https")
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		retries:    2,
+		backoff:    100 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// ListBuses returns the available D-Bus types
+func (c *Client) ListBuses(ctx context.Context) ([]model.BusInfo, error) {
+	var buses []model.BusInfo
+	err := c.do(ctx, http.MethodGet, c.endpoint("buses"), nil, &buses)
+	return buses, err
+}
+
+// CheckBus reports the connectivity of a bus as seen by the readiness probe
+func (c *Client) CheckBus(ctx context.Context, busType string) *model.BusHealth {
+	var status model.ReadinessStatus
+	err := c.do(ctx, http.MethodGet, c.endpoint("readyz"), nil, &status)
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusServiceUnavailable && apiErr.readiness != nil {
+		status, err = *apiErr.readiness, nil
+	}
+	if err != nil {
+		return &model.BusHealth{Type: busType, Error: err.Error()}
+	}
+
+	for _, bus := range status.Buses {
+		if bus.Type == busType {
+			return &bus
+		}
+	}
+
+	return &model.BusHealth{Type: busType, Error: fmt.Sprintf("invalid bus type: %s", busType)}
+}
+
+// ListServices returns all services on the specified bus
+func (c *Client) ListServices(ctx context.Context, busType string) ([]string, error) {
+	var services []string
+	err := c.do(ctx, http.MethodGet, c.endpoint("buses", busType, "services"), nil, &services)
+	return services, err
+}
+
+// GetServiceInfo returns detailed information about a service
+func (c *Client) GetServiceInfo(ctx context.Context, busType, serviceName string) (*model.ServiceInfo, error) {
+	var info model.ServiceInfo
+	if err := c.do(ctx, http.MethodGet, c.endpoint("buses", busType, "services", serviceName), nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// ListNames returns the running and activatable names on a bus
+func (c *Client) ListNames(ctx context.Context, busType string) ([]model.NameInfo, error) {
+	var names []model.NameInfo
+	err := c.do(ctx, http.MethodGet, c.endpoint("buses", busType, "names"), nil, &names)
+	return names, err
+}
+
+// StartService starts an activatable service by name
+func (c *Client) StartService(ctx context.Context, busType, serviceName string) (*model.ServiceStart, error) {
+	var start model.ServiceStart
+	if err := c.do(ctx, http.MethodPost, c.endpoint("buses", busType, "services", serviceName, "start"), nil, &start); err != nil {
+		return nil, err
+	}
+	return &start, nil
+}
+
+// UpdateActivationEnvironment adds or replaces variables of the environment of activated services
+func (c *Client) UpdateActivationEnvironment(ctx context.Context, busType string, environment map[string]string) error {
+	return c.do(ctx, http.MethodPatch, c.endpoint("buses", busType, "activation", "environment"), model.ActivationEnvironment{Environment: environment}, nil)
+}
+
+// RequestName requests a well-known name for the connection of the controller
+func (c *Client) RequestName(ctx context.Context, busType, name string, flags model.NameFlags) (*model.NameOwnership, error) {
+	var ownership model.NameOwnership
+	if err := c.do(ctx, http.MethodPost, c.endpoint("buses", busType, "names", name, "request"), flags, &ownership); err != nil {
+		return nil, err
+	}
+	return &ownership, nil
+}
+
+// ReleaseName releases a well-known name owned or queued for by the controller
+func (c *Client) ReleaseName(ctx context.Context, busType, name string) (*model.NameOwnership, error) {
+	var ownership model.NameOwnership
+	if err := c.do(ctx, http.MethodPost, c.endpoint("buses", busType, "names", name, "release"), nil, &ownership); err != nil {
+		return nil, err
+	}
+	return &ownership, nil
+}
+
+// ListQueuedOwners returns the connections queued for a well-known name
+func (c *Client) ListQueuedOwners(ctx context.Context, busType, name string) (*model.NameQueue, error) {
+	var queue model.NameQueue
+	if err := c.do(ctx, http.MethodGet, c.endpoint("buses", busType, "names", name, "owners"), nil, &queue); err != nil {
+		return nil, err
+	}
+	return &queue, nil
+}
+
+// ListInterfaces returns all interfaces of an object of a service
+func (c *Client) ListInterfaces(ctx context.Context, busType, serviceName, objectPath string) ([]string, error) {
+	var interfaces []string
+	err := c.do(ctx, http.MethodGet, c.objectEndpoint(objectPath, "buses", busType, "services", serviceName, "interfaces"), nil, &interfaces)
+	return interfaces, err
+}
+
+// GetInterfaceInfo returns detailed information about an interface
+func (c *Client) GetInterfaceInfo(ctx context.Context, busType, serviceName, objectPath, interfaceName string) (*model.InterfaceInfo, error) {
+	var info model.InterfaceInfo
+	if err := c.do(ctx, http.MethodGet, c.objectEndpoint(objectPath, "buses", busType, "services", serviceName, "interfaces", interfaceName), nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// ListMethods returns all methods for an interface
+func (c *Client) ListMethods(ctx context.Context, busType, serviceName, objectPath, interfaceName string) ([]model.MethodInfo, error) {
+	var methods []model.MethodInfo
+	err := c.do(ctx, http.MethodGet, c.objectEndpoint(objectPath, "buses", busType, "services", serviceName, "interfaces", interfaceName, "methods"), nil, &methods)
+	return methods, err
+}
+
+// CallMethod executes a D-Bus method call.
+// When the call fails on the bus, the result is returned along with a *DBusError.
+func (c *Client) CallMethod(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName string, args []interface{}) (*model.MethodCallResult, error) {
+	return c.CallMethodWithFlags(ctx, busType, serviceName, objectPath, interfaceName, methodName, model.CallFlags{}, args)
+}
+
+// CallMethodWithFlags executes a D-Bus method call with the given header flags.
+// A call sent without reply succeeds once sent, without return values.
+func (c *Client) CallMethodWithFlags(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName string, flags model.CallFlags, args []interface{}) (*model.MethodCallResult, error) {
+	body := callBody(flags)
+	body["args"] = args
+
+	var result model.MethodCallResult
+	if err := c.do(ctx, http.MethodPost, c.objectEndpoint(objectPath, "buses", busType, "services", serviceName, "interfaces", interfaceName, "methods", methodName, "call"), body, &result); err != nil {
+		return nil, err
+	}
+	if !result.Success {
+		return &result, &DBusError{Name: result.ErrorName, Message: result.Error}
+	}
+
+	return &result, nil
+}
+
+// callBody returns a method call request body holding the set call flags
+func callBody(flags model.CallFlags) map[string]interface{} {
+	body := map[string]interface{}{}
+	if flags.NoReply {
+		body["no_reply"] = true
+	}
+	if flags.NoAutoStart {
+		body["no_auto_start"] = true
+	}
+	if flags.AllowInteractiveAuthorization {
+		body["allow_interactive_authorization"] = true
+	}
+	return body
+}
+
+// CallMethodNoReply sends a method call without waiting for a reply, as a
+// JSON-RPC notification. Failures of the call itself are not reported.
+func (c *Client) CallMethodNoReply(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName string, args []interface{}) error {
+	params, err := json.Marshal(model.RPCCallParams{
+		Bus:       busType,
+		Service:   serviceName,
+		Path:      objectPath,
+		Interface: interfaceName,
+		Method:    methodName,
+		Args:      args,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode request body: %w", err)
+	}
+
+	return c.do(ctx, http.MethodPost, c.endpoint("jsonrpc"), model.RPCRequest{JSONRPC: "2.0", Method: "call", Params: params}, nil)
+}
+
+// Batch runs a batch of operations. Failed operations are reported in their
+// results rather than as an error.
+func (c *Client) Batch(ctx context.Context, batch model.BatchRequest) (*model.BatchResponse, error) {
+	var response model.BatchResponse
+	if err := c.do(ctx, http.MethodPost, c.endpoint("batch"), batch, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// CallMethodText executes a D-Bus method call with arguments in busctl notation,
+// typed by the signature and converted by the controller
+func (c *Client) CallMethodText(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName, signature string, args []string) (*model.MethodCallResult, error) {
+	return c.CallMethodTextWithFlags(ctx, busType, serviceName, objectPath, interfaceName, methodName, signature, model.CallFlags{}, args)
+}
+
+// CallMethodTextWithFlags executes a D-Bus method call with arguments in busctl
+// notation and the given header flags
+func (c *Client) CallMethodTextWithFlags(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName, signature string, flags model.CallFlags, args []string) (*model.MethodCallResult, error) {
+	body := callBody(flags)
+	body["signature"] = signature
+	body["text_args"] = args
+
+	var result model.MethodCallResult
+	if err := c.do(ctx, http.MethodPost, c.objectEndpoint(objectPath, "buses", busType, "services", serviceName, "interfaces", interfaceName, "methods", methodName, "call"), body, &result); err != nil {
+		return nil, err
+	}
+	if !result.Success {
+		return &result, &DBusError{Name: result.ErrorName, Message: result.Error}
+	}
+
+	return &result, nil
+}
+
+// CallObjectMethod executes a D-Bus method call with arguments keyed by their
+// introspected names (argN when unnamed) and returns the named return values.
+// Failed calls are reported as *DBusError.
+func (c *Client) CallObjectMethod(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName string, args map[string]interface{}) (map[string]interface{}, error) {
+	segments := []string{"buses", busType, "services", serviceName, "objects"}
+	if trimmed := strings.Trim(objectPath, "/"); trimmed != "" {
+		segments = append(segments, strings.Split(trimmed, "/")...)
+	}
+	segments = append(segments, interfaceName, methodName)
+
+	if args == nil {
+		args = map[string]interface{}{}
+	}
+
+	var values map[string]interface{}
+	if err := c.do(ctx, http.MethodPost, c.endpoint(segments...), args, &values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// ListProperties returns all properties for an interface
+func (c *Client) ListProperties(ctx context.Context, busType, serviceName, objectPath, interfaceName string) ([]model.PropertyInfo, error) {
+	var properties []model.PropertyInfo
+	err := c.do(ctx, http.MethodGet, c.objectEndpoint(objectPath, "buses", busType, "services", serviceName, "interfaces", interfaceName, "properties"), nil, &properties)
+	return properties, err
+}
+
+// GetProperty returns the value of a specific property
+func (c *Client) GetProperty(ctx context.Context, busType, serviceName, objectPath, interfaceName, propertyName string) (*model.PropertyValue, error) {
+	var value model.PropertyValue
+	if err := c.do(ctx, http.MethodGet, c.objectEndpoint(objectPath, "buses", busType, "services", serviceName, "interfaces", interfaceName, "properties", propertyName), nil, &value); err != nil {
+		return nil, err
+	}
+	return &value, nil
+}
+
+// SetProperty sets the value of a specific property and returns the updated value
+func (c *Client) SetProperty(ctx context.Context, busType, serviceName, objectPath, interfaceName, propertyName string, value interface{}) (*model.PropertyValue, error) {
+	body := map[string]interface{}{"value": value}
+
+	var updated model.PropertyValue
+	if err := c.do(ctx, http.MethodPut, c.objectEndpoint(objectPath, "buses", busType, "services", serviceName, "interfaces", interfaceName, "properties", propertyName), body, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// SetPropertyText sets the value of a property given in busctl notation and returns the updated value
+func (c *Client) SetPropertyText(ctx context.Context, busType, serviceName, objectPath, interfaceName, propertyName, signature string, args []string) (*model.PropertyValue, error) {
+	body := map[string]interface{}{"signature": signature, "text_args": args}
+
+	var updated model.PropertyValue
+	if err := c.do(ctx, http.MethodPut, c.objectEndpoint(objectPath, "buses", busType, "services", serviceName, "interfaces", interfaceName, "properties", propertyName), body, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// ListSignals returns all signals for an interface
+func (c *Client) ListSignals(ctx context.Context, busType, serviceName, objectPath, interfaceName string) ([]model.SignalInfo, error) {
+	var signals []model.SignalInfo
+	err := c.do(ctx, http.MethodGet, c.objectEndpoint(objectPath, "buses", busType, "services", serviceName, "interfaces", interfaceName, "signals"), nil, &signals)
+	return signals, err
+}
+
+// EmitSignal emits a D-Bus signal from the connection of the controller,
+// broadcast or unicast to destination when it is not empty
+func (c *Client) EmitSignal(ctx context.Context, busType, destination, objectPath, interfaceName, signalName string, args []interface{}) error {
+	return c.Emit(ctx, busType, model.EmitSignalRequest{
+		Path:        objectPath,
+		Interface:   interfaceName,
+		Member:      signalName,
+		Destination: destination,
+		Args:        args,
+	})
+}
+
+// Emit emits a D-Bus signal, with arguments typed by the signature or given as text
+func (c *Client) Emit(ctx context.Context, busType string, signal model.EmitSignalRequest) error {
+	return c.do(ctx, http.MethodPost, c.endpoint("buses", busType, "signals"), signal, nil)
+}
+
+// SubscribeToSignal subscribes to a D-Bus signal
+func (c *Client) SubscribeToSignal(ctx context.Context, busType, serviceName, interfaceName, signalName string) (*model.SignalSubscription, error) {
+	var subscription model.SignalSubscription
+	if err := c.do(ctx, http.MethodPost, c.endpoint("buses", busType, "services", serviceName, "interfaces", interfaceName, "signals", signalName, "subscribe"), nil, &subscription); err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// ListSubscriptions returns the active signal subscriptions
+func (c *Client) ListSubscriptions(ctx context.Context) ([]model.SignalSubscription, error) {
+	var subscriptions []model.SignalSubscription
+	err := c.do(ctx, http.MethodGet, c.endpoint("subscriptions"), nil, &subscriptions)
+	return subscriptions, err
+}
+
+// Unsubscribe removes a signal subscription
+func (c *Client) Unsubscribe(ctx context.Context, subscriptionID string) error {
+	return c.do(ctx, http.MethodDelete, c.endpoint("subscriptions", subscriptionID), nil, nil)
+}
+
+// IntrospectService returns the introspection data of the root object of a service
+func (c *Client) IntrospectService(ctx context.Context, busType, serviceName string) (*model.IntrospectionResult, error) {
+	return c.IntrospectObject(ctx, busType, serviceName, "/")
+}
+
+// IntrospectObject returns the introspection data of an object of a service
+func (c *Client) IntrospectObject(ctx context.Context, busType, serviceName, objectPath string) (*model.IntrospectionResult, error) {
+	var result model.IntrospectionResult
+	if err := c.do(ctx, http.MethodGet, c.objectEndpoint(objectPath, "buses", busType, "services", serviceName, "introspect"), nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// CloseStreams is a no-op; streams end when their context is done
+func (c *Client) CloseStreams() {}
+
+// Close releases the idle connections of the HTTP client
+func (c *Client) Close() {
+	c.httpClient.CloseIdleConnections()
+}
+
+// endpoint returns the URL of the API path made of the escaped segments
+func (c *Client) endpoint(segments ...string) string {
+	u := *c.baseURL
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		escaped[i] = url.PathEscape(segment)
+	}
+	u.Path = c.baseURL.Path + "/" + strings.Join(segments, "/")
+	u.RawPath = c.baseURL.EscapedPath() + "/" + strings.Join(escaped, "/")
+	return u.String()
+}
+
+// objectEndpoint returns the URL of an object-scoped API path
+func (c *Client) objectEndpoint(objectPath string, segments ...string) string {
+	endpoint := c.endpoint(segments...)
+	if objectPath == "" || objectPath == "/" {
+		return endpoint
+	}
+	return endpoint + "?" + url.Values{"path": {objectPath}}.Encode()
+}
+
+// do sends a request with an optional JSON body and decodes the JSON response into out.
+// Idempotent requests are retried on transport errors and gateway errors.
+func (c *Client) do(ctx context.Context, method, endpoint string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("failed to encode request body: %w", err)
+		}
+	}
+
+	attempts := 1
+	if method != http.MethodPost {
+		attempts += c.retries
+	}
+	backoff := c.backoff
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		var retry bool
+		retry, err = c.send(ctx, method, endpoint, payload, out)
+		if err == nil || !retry || ctx.Err() != nil {
+			return err
+		}
+	}
+
+	return err
+}
+
+// send performs a single request and reports whether a failure may be retried
+func (c *Client) send(ctx context.Context, method, endpoint string, payload []byte, out interface{}) (bool, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("%s %s: %w", method, endpoint, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := newAPIError(resp)
+		retry := resp.StatusCode == http.StatusBadGateway && apiErr.DBusErrorName == "" ||
+			resp.StatusCode == http.StatusServiceUnavailable ||
+			resp.StatusCode == http.StatusGatewayTimeout && apiErr.DBusErrorName == ""
+		return retry, apiErr
+	}
+
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return false, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return false, nil
+}
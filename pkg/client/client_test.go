@@ -0,0 +1,445 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-fuego/fuego"
+	"github.com/godbus/dbus/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mesbrj/dbus-controller/internal/api"
+	"github.com/mesbrj/dbus-controller/internal/config"
+	"github.com/mesbrj/dbus-controller/internal/handler"
+	"github.com/mesbrj/dbus-controller/internal/handler/handlertest"
+	"github.com/mesbrj/dbus-controller/internal/service"
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// Ensure the client mirrors the D-Bus operations of the controller
+var _ service.DBusServiceInterface = (*Client)(nil)
+
+// newTestClient serves the controller routes backed by a mock service
+func newTestClient(t *testing.T) (*Client, *handlertest.MockDBusService) {
+	mockService := new(handlertest.MockDBusService)
+	s := fuego.NewServer(fuego.WithErrorHandler(handler.ErrorHandler))
+	api.SetupRoutes(s, mockService)
+	api.SetupHealthRoutes(s, mockService, []string{"session"})
+	api.SetupSignalRoutes(s, mockService, []config.SignalRule{{Interface: "com.example.*"}})
+
+	server := httptest.NewServer(s.Mux)
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL, WithRetries(0, 0))
+	require.NoError(t, err)
+
+	return c, mockService
+}
+
+func TestNew_InvalidURL(t *testing.T) {
+	_, err := New("localhost:8080")
+	assert.Error(t, err)
+}
+
+func TestClient_ListServices(t *testing.T) {
+	c, mockService := newTestClient(t)
+	mockService.On("ListServices", mock.Anything, "session").Return([]string{"org.freedesktop.DBus", "com.example.HelloWorld"}, nil)
+
+	services, err := c.ListServices(context.Background(), "session")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"org.freedesktop.DBus", "com.example.HelloWorld"}, services)
+	mockService.AssertExpectations(t)
+}
+
+func TestClient_CallMethod(t *testing.T) {
+	c, mockService := newTestClient(t)
+	mockService.On("CallMethodWithFlags", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Hello", model.CallFlags{}, []interface{}{"world"}).
+		Return(&model.MethodCallResult{Success: true, ReturnValues: []interface{}{"Hello, world!"}}, nil)
+
+	result, err := c.CallMethod(context.Background(), "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Hello", []interface{}{"world"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"Hello, world!"}, result.ReturnValues)
+}
+
+func TestClient_CallMethodWithFlags(t *testing.T) {
+	c, mockService := newTestClient(t)
+	mockService.On("CallMethodWithFlags", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Hello",
+		model.CallFlags{NoReply: true, NoAutoStart: true}, []interface{}{"world"}).
+		Return(&model.MethodCallResult{Success: true, NoReply: true}, nil)
+
+	result, err := c.CallMethodWithFlags(context.Background(), "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Hello",
+		model.CallFlags{NoReply: true, NoAutoStart: true}, []interface{}{"world"})
+
+	require.NoError(t, err)
+	assert.True(t, result.NoReply)
+	mockService.AssertExpectations(t)
+}
+
+func TestClient_CallMethodText(t *testing.T) {
+	c, mockService := newTestClient(t)
+	mockService.On("CallMethodWithFlags", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Hello", model.CallFlags{}, []interface{}{"world", []int32{1, 2}}).
+		Return(&model.MethodCallResult{Success: true, ReturnValues: []interface{}{"Hello, world!"}}, nil)
+
+	result, err := c.CallMethodText(context.Background(), "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Hello", "sai", []string{"world", "2", "1", "2"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"Hello, world!"}, result.ReturnValues)
+}
+
+func TestClient_CallMethodNoReply(t *testing.T) {
+	c, mockService := newTestClient(t)
+	mockService.On("CallMethodWithFlags", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Hello", model.CallFlags{NoReply: true}, []interface{}{"world"}).
+		Return(&model.MethodCallResult{Success: true, NoReply: true}, nil)
+
+	err := c.CallMethodNoReply(context.Background(), "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Hello", []interface{}{"world"})
+
+	assert.NoError(t, err)
+	mockService.AssertExpectations(t)
+}
+
+func TestClient_Batch(t *testing.T) {
+	c, mockService := newTestClient(t)
+	mockService.On("GetProperty", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Data").
+		Return(&model.PropertyValue{Name: "Data", Value: "world"}, nil)
+	mockService.On("CallMethod", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Hello", []interface{}{"world"}).
+		Return(&model.MethodCallResult{Success: true, ReturnValues: []interface{}{"Hello, world!"}}, nil)
+
+	response, err := c.Batch(context.Background(), model.BatchRequest{Operations: []model.BatchOperation{
+		{ID: "data", Op: "get", Bus: "session", Service: "com.example.HelloWorld", Path: "/com/example/HelloWorld", Interface: "com.example.HelloWorld", Property: "Data"},
+		{Op: "call", Bus: "session", Service: "com.example.HelloWorld", Path: "/com/example/HelloWorld", Interface: "com.example.HelloWorld", Method: "Hello",
+			Args: []interface{}{"{results.data}"}},
+	}})
+
+	require.NoError(t, err)
+	assert.True(t, response.Success)
+	assert.Equal(t, []interface{}{"Hello, world!"}, response.Results[1].Result)
+}
+
+func TestClient_CallMethodText_InvalidArgs(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	_, err := c.CallMethodText(context.Background(), "session", "com.example.HelloWorld", "/", "com.example.HelloWorld", "Hello", "i", []string{"x"})
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	assert.Contains(t, apiErr.Detail, "argument 0")
+}
+
+func TestClient_CallObjectMethod(t *testing.T) {
+	c, mockService := newTestClient(t)
+	mockService.On("GetInterfaceInfo", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld").
+		Return(&model.InterfaceInfo{Name: "com.example.HelloWorld", Methods: []model.MethodInfo{{
+			Name:    "Hello",
+			InArgs:  []model.ArgumentInfo{{Name: "name", Type: "s"}},
+			OutArgs: []model.ArgumentInfo{{Name: "greeting", Type: "s"}},
+		}}}, nil)
+	mockService.On("CallMethod", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Hello", []interface{}{"world"}).
+		Return(&model.MethodCallResult{Success: true, ReturnValues: []interface{}{"Hello, world!"}}, nil)
+
+	values, err := c.CallObjectMethod(context.Background(), "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Hello", map[string]interface{}{"name": "world"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"greeting": "Hello, world!"}, values)
+}
+
+func TestClient_CallMethod_DBusError(t *testing.T) {
+	c, mockService := newTestClient(t)
+	mockService.On("CallMethodWithFlags", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Missing", model.CallFlags{}, []interface{}(nil)).
+		Return(&model.MethodCallResult{Success: false, Error: "no such method", ErrorName: "org.freedesktop.DBus.Error.UnknownMethod"}, nil)
+
+	result, err := c.CallMethod(context.Background(), "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Missing", nil)
+
+	assert.NotNil(t, result)
+	var dbusErr *DBusError
+	require.ErrorAs(t, err, &dbusErr)
+	assert.Equal(t, "org.freedesktop.DBus.Error.UnknownMethod", dbusErr.Name)
+	assert.Equal(t, "no such method", dbusErr.Message)
+}
+
+func TestClient_GetProperty_DBusError(t *testing.T) {
+	c, mockService := newTestClient(t)
+	replyErr := dbus.Error{Name: "org.freedesktop.DBus.Error.UnknownProperty", Body: []interface{}{"no such property"}}
+	mockService.On("GetProperty", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Missing").
+		Return((*model.PropertyValue)(nil), &replyErr)
+
+	_, err := c.GetProperty(context.Background(), "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Missing")
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.True(t, IsNotFound(err))
+	assert.Equal(t, "org.freedesktop.DBus.Error.UnknownProperty", DBusErrorName(err))
+}
+
+func TestClient_SetProperty(t *testing.T) {
+	c, mockService := newTestClient(t)
+	mockService.On("SetProperty", mock.Anything, "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Data", "updated").
+		Return(&model.PropertyValue{Name: "Data", Type: "s", Value: "updated"}, nil)
+
+	value, err := c.SetProperty(context.Background(), "session", "com.example.HelloWorld", "/com/example/HelloWorld", "com.example.HelloWorld", "Data", "updated")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "updated", value.Value)
+}
+
+func TestClient_CheckBus(t *testing.T) {
+	c, mockService := newTestClient(t)
+	mockService.On("CheckBus", mock.Anything, "system").Return(&model.BusHealth{Type: "system", Error: "system bus not available"})
+	mockService.On("CheckBus", mock.Anything, "session").Return(&model.BusHealth{Type: "session", Error: "session bus not available"})
+
+	health := c.CheckBus(context.Background(), "session")
+
+	assert.False(t, health.Connected)
+	assert.True(t, health.Required)
+	assert.Equal(t, "session bus not available", health.Error)
+}
+
+func TestClient_Unsubscribe_NotFound(t *testing.T) {
+	c, mockService := newTestClient(t)
+	mockService.On("Unsubscribe", mock.Anything, "missing").Return(service.ErrSubscriptionNotFound)
+
+	err := c.Unsubscribe(context.Background(), "missing")
+
+	assert.True(t, IsNotFound(err))
+	assert.Empty(t, DBusErrorName(err))
+}
+
+func TestClient_StreamSubscription(t *testing.T) {
+	c, mockService := newTestClient(t)
+	id := "session:com.example.HelloWorld:com.example.HelloWorld:Changed"
+
+	source := make(chan *model.SignalEvent, 2)
+	source <- &model.SignalEvent{SubscriptionID: id, Signal: "Changed", Body: []interface{}{"a"}}
+	source <- &model.SignalEvent{SubscriptionID: id, Signal: "Changed", Body: []interface{}{"b"}}
+	close(source)
+	mockService.On("StreamSubscription", mock.Anything, id).Return(source, nil)
+
+	events, err := c.StreamSubscription(context.Background(), id)
+	require.NoError(t, err)
+
+	received := make([]*model.SignalEvent, 0)
+	for event := range events {
+		received = append(received, event)
+	}
+
+	require.Len(t, received, 2)
+	assert.Equal(t, []interface{}{"a"}, received[0].Body)
+	assert.Equal(t, []interface{}{"b"}, received[1].Body)
+}
+
+func TestClient_Jobs(t *testing.T) {
+	c, mockService := newTestClient(t)
+	mockService.On("StartJob", mock.Anything, "session", "com.example.Flash", "/", "com.example.Flash", "Write", model.CallFlags{NoAutoStart: true}, []interface{}{"image.bin"}).
+		Return(&model.Job{ID: "0af1", Status: "pending"}, nil)
+
+	finished := make(chan *model.Job, 1)
+	finished <- &model.Job{ID: "0af1", Status: "succeeded", Result: &model.MethodCallResult{Success: true, ReturnValues: []interface{}{true}}}
+	close(finished)
+	mockService.On("WatchJob", mock.Anything, "0af1").Return(finished, nil)
+
+	job, err := c.StartJob(context.Background(), "session", "com.example.Flash", "/", "com.example.Flash", "Write", model.CallFlags{NoAutoStart: true}, []interface{}{"image.bin"})
+	require.NoError(t, err)
+	assert.Equal(t, "0af1", job.ID)
+
+	jobs, err := c.WatchJob(context.Background(), job.ID)
+	require.NoError(t, err)
+	done := <-jobs
+	require.NotNil(t, done)
+	assert.Equal(t, "succeeded", done.Status)
+	assert.Equal(t, []interface{}{true}, done.Result.ReturnValues)
+	_, open := <-jobs
+	assert.False(t, open)
+}
+
+func TestClient_GetJob_NotFound(t *testing.T) {
+	c, mockService := newTestClient(t)
+	mockService.On("GetJob", mock.Anything, "missing").Return(nil, service.ErrJobNotFound)
+
+	_, err := c.GetJob(context.Background(), "missing")
+
+	assert.True(t, IsNotFound(err))
+}
+
+func TestClient_EmitSignal(t *testing.T) {
+	c, mockService := newTestClient(t)
+	mockService.On("EmitSignal", mock.Anything, "session", "", "/com/example/Door", "com.example.Door", "Opened", []interface{}{"front", uint32(3)}).
+		Return(nil)
+
+	err := c.Emit(context.Background(), "session", model.EmitSignalRequest{
+		Path: "/com/example/Door", Interface: "com.example.Door", Member: "Opened", Signature: "su", Args: []interface{}{"front", 3},
+	})
+
+	assert.NoError(t, err)
+	mockService.AssertExpectations(t)
+}
+
+func TestClient_Activation(t *testing.T) {
+	c, mockService := newTestClient(t)
+	mockService.On("ListNames", mock.Anything, "session").Return([]model.NameInfo{{Name: "com.example.Flash", Activatable: true}}, nil)
+	mockService.On("StartService", mock.Anything, "session", "com.example.Flash").
+		Return(&model.ServiceStart{Name: "com.example.Flash", Reply: "success", Owner: ":1.12"}, nil)
+	mockService.On("UpdateActivationEnvironment", mock.Anything, "session", map[string]string{"LANG": "C.UTF-8"}).Return(nil)
+	ctx := context.Background()
+
+	names, err := c.ListNames(ctx, "session")
+	require.NoError(t, err)
+	assert.Equal(t, []model.NameInfo{{Name: "com.example.Flash", Activatable: true}}, names)
+
+	start, err := c.StartService(ctx, "session", "com.example.Flash")
+	require.NoError(t, err)
+	assert.Equal(t, ":1.12", start.Owner)
+
+	assert.NoError(t, c.UpdateActivationEnvironment(ctx, "session", map[string]string{"LANG": "C.UTF-8"}))
+	mockService.AssertExpectations(t)
+}
+
+func TestClient_Names(t *testing.T) {
+	c, mockService := newTestClient(t)
+	ownership := &model.NameOwnership{Name: "com.example.Test", Reply: "in_queue", Owner: ":1.9", Connection: ":1.7"}
+	mockService.On("RequestName", mock.Anything, "session", "com.example.Test", model.NameFlags{AllowReplacement: true}).Return(ownership, nil)
+	mockService.On("ListQueuedOwners", mock.Anything, "session", "com.example.Test").
+		Return(&model.NameQueue{Name: "com.example.Test", Owners: []string{":1.9", ":1.7"}, Connection: ":1.7"}, nil)
+	mockService.On("ReleaseName", mock.Anything, "session", "com.example.Test").
+		Return(&model.NameOwnership{Name: "com.example.Test", Reply: "released", Owner: ":1.9", Connection: ":1.7"}, nil)
+	ctx := context.Background()
+
+	requested, err := c.RequestName(ctx, "session", "com.example.Test", model.NameFlags{AllowReplacement: true})
+	require.NoError(t, err)
+	assert.Equal(t, ownership, requested)
+
+	queue, err := c.ListQueuedOwners(ctx, "session", "com.example.Test")
+	require.NoError(t, err)
+	assert.Equal(t, []string{":1.9", ":1.7"}, queue.Owners)
+
+	released, err := c.ReleaseName(ctx, "session", "com.example.Test")
+	require.NoError(t, err)
+	assert.Equal(t, "released", released.Reply)
+	mockService.AssertExpectations(t)
+}
+
+func TestClient_NameOwners(t *testing.T) {
+	c, mockService := newTestClient(t)
+	source := make(chan *model.NameOwnerEvent, 1)
+	source <- &model.NameOwnerEvent{Bus: "session", Name: "com.example.Test", Change: "appeared", NewOwner: ":1.9"}
+	close(source)
+	mockService.On("WatchNameOwners", mock.Anything, "session", "com.example.*", true).Return(source, nil)
+	mockService.On("WaitForName", mock.Anything, "session", "com.example.Test").Return(":1.9", nil)
+	mockService.On("ListServices", mock.Anything, "session").Return([]string{"com.example.Test"}, nil)
+	mockService.On("ListQueuedOwners", mock.Anything, "session", "com.example.Test").
+		Return(&model.NameQueue{Name: "com.example.Test", Owners: []string{":1.9"}, Connection: ":1.7"}, nil)
+	ctx := context.Background()
+
+	events, err := c.WatchNameOwners(ctx, "session", "com.example.*", true)
+	require.NoError(t, err)
+	var received []*model.NameOwnerEvent
+	for event := range events {
+		received = append(received, event)
+	}
+	require.Len(t, received, 1)
+	assert.Equal(t, "appeared", received[0].Change)
+	assert.Equal(t, ":1.9", received[0].NewOwner)
+
+	owner, err := c.WaitForName(ctx, "session", "com.example.Test")
+	require.NoError(t, err)
+	assert.Equal(t, ":1.9", owner)
+	mockService.AssertExpectations(t)
+}
+
+func TestClient_Monitor(t *testing.T) {
+	c, mockService := newTestClient(t)
+	source := make(chan *model.MonitorMessage, 1)
+	source <- &model.MonitorMessage{Type: "signal", Serial: 12, Sender: ":1.9", Path: "/", Interface: "com.example.Door", Member: "Opened"}
+	close(source)
+	rules := []string{"type='signal'"}
+	mockService.On("Monitor", mock.Anything, "session", rules).Return(source, nil)
+
+	messages, err := c.Monitor(context.Background(), "session", rules)
+	require.NoError(t, err)
+	var received []*model.MonitorMessage
+	for message := range messages {
+		received = append(received, message)
+	}
+	require.Len(t, received, 1)
+	assert.Equal(t, "Opened", received[0].Member)
+	assert.Equal(t, uint32(12), received[0].Serial)
+}
+
+func TestClient_Capture(t *testing.T) {
+	c, mockService := newTestClient(t)
+	mockService.On("Capture", mock.Anything, "session", []string{"type='error'"}, 5*time.Second, mock.Anything).
+		Run(func(args mock.Arguments) {
+			_, _ = args.Get(4).(io.Writer).Write([]byte("pcap"))
+		}).
+		Return(&model.CaptureStats{Messages: 3, Dropped: 2}, nil)
+
+	var out bytes.Buffer
+	stats, err := c.Capture(context.Background(), "session", []string{"type='error'"}, 5*time.Second, &out)
+	require.NoError(t, err)
+	assert.Equal(t, "pcap", out.String())
+	assert.Equal(t, &model.CaptureStats{Messages: 3, Dropped: 2}, stats)
+
+	_, err = c.Capture(context.Background(), "session", nil, time.Hour, &out)
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+}
+
+func TestClient_RetriesIdempotentRequests(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if attempts.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`["org.freedesktop.DBus"]`))
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, WithRetries(2, time.Millisecond))
+	require.NoError(t, err)
+
+	services, err := c.ListServices(context.Background(), "session")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"org.freedesktop.DBus"}, services)
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestClient_DoesNotRetryCalls(t *testing.T) {
+	var attempts atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	c, err := New(server.URL, WithRetries(2, time.Millisecond))
+	require.NoError(t, err)
+
+	_, err = c.CallMethod(context.Background(), "session", "a.b", "/", "a.b", "C", nil)
+
+	var apiErr *APIError
+	assert.True(t, errors.As(err, &apiErr))
+	assert.Equal(t, int32(1), attempts.Load())
+}
+
+func TestClient_EndpointEscapesSegments(t *testing.T) {
+	c, err := New("http://localhost:8080/api/")
+	require.NoError(t, err)
+
+	assert.Equal(t, "http://localhost:8080/api/subscriptions/session:a%2Fb:c/events",
+		c.endpoint("subscriptions", "session:a/b:c", "events"))
+}
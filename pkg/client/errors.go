@@ -0,0 +1,123 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// maxErrorBody bounds the error response read from the controller
+const maxErrorBody = 64 << 10
+
+// APIError is returned when the controller answers with an error status
+type APIError struct {
+	StatusCode    int
+	Title         string
+	Detail        string
+	DBusErrorName string // D-Bus error name when the request failed on the bus
+
+	dbusMessage string                 // Message of the D-Bus error reply
+	readiness   *model.ReadinessStatus // Body of a failed readiness probe
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("controller returned %d", e.StatusCode)
+	if e.Title != "" {
+		msg += " " + e.Title
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+// Unwrap returns the D-Bus error of requests that failed on the bus
+func (e *APIError) Unwrap() error {
+	if e.DBusErrorName == "" {
+		return nil
+	}
+	return &DBusError{Name: e.DBusErrorName, Message: e.dbusMessage}
+}
+
+// DBusError is a D-Bus error reply returned by the bus or the called service
+type DBusError struct {
+	Name    string // For example org.freedesktop.DBus.Error.UnknownMethod
+	Message string
+}
+
+func (e *DBusError) Error() string {
+	if e.Name == "" {
+		return e.Message
+	}
+	return e.Name + ": " + e.Message
+}
+
+// DBusErrorName returns the D-Bus error name carried by err, if any
+func DBusErrorName(err error) string {
+	var dbusErr *DBusError
+	if errors.As(err, &dbusErr) {
+		return dbusErr.Name
+	}
+	return ""
+}
+
+// IsNotFound reports whether err is a 404 response of the controller
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// dbusErrorTitle is the title of the controller errors caused by D-Bus error replies
+const dbusErrorTitle = "D-Bus error"
+
+// problem is the JSON error body written by the controller
+type problem struct {
+	Title  string `json:"title"`
+	Detail string `json:"detail"`
+	Errors []struct {
+		Name   string `json:"name"`
+		Reason string `json:"reason"`
+	} `json:"errors"`
+}
+
+// newAPIError decodes the error response of the controller
+func newAPIError(resp *http.Response) *APIError {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err != nil || len(data) == 0 {
+		apiErr.Title = http.StatusText(resp.StatusCode)
+		return apiErr
+	}
+
+	if resp.StatusCode == http.StatusServiceUnavailable {
+		var readiness model.ReadinessStatus
+		if json.Unmarshal(data, &readiness) == nil && readiness.Buses != nil {
+			apiErr.Title = "Not ready"
+			apiErr.readiness = &readiness
+			return apiErr
+		}
+	}
+
+	var body problem
+	if err := json.Unmarshal(data, &body); err != nil {
+		apiErr.Title = http.StatusText(resp.StatusCode)
+		return apiErr
+	}
+
+	apiErr.Title = body.Title
+	apiErr.Detail = body.Detail
+	if body.Title == dbusErrorTitle && len(body.Errors) > 0 {
+		apiErr.DBusErrorName = body.Errors[0].Name
+		apiErr.dbusMessage = body.Errors[0].Reason
+	}
+	if apiErr.Title == "" {
+		apiErr.Title = http.StatusText(resp.StatusCode)
+	}
+
+	return apiErr
+}
@@ -0,0 +1,85 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// StartJob starts a method call in the background and returns its job
+// without waiting for the reply
+func (c *Client) StartJob(ctx context.Context, busType, serviceName, objectPath, interfaceName, methodName string, flags model.CallFlags, args []interface{}) (*model.Job, error) {
+	body := callBody(flags)
+	body["args"] = args
+	body["async"] = true
+
+	var job model.Job
+	if err := c.do(ctx, http.MethodPost, c.objectEndpoint(objectPath, "buses", busType, "services", serviceName, "interfaces", interfaceName, "methods", methodName, "call"), body, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// ListJobs returns the pending, running and recently finished jobs
+func (c *Client) ListJobs(ctx context.Context) ([]model.Job, error) {
+	var jobs []model.Job
+	err := c.do(ctx, http.MethodGet, c.endpoint("jobs"), nil, &jobs)
+	return jobs, err
+}
+
+// GetJob returns the status of a job, with the result of its call once finished
+func (c *Client) GetJob(ctx context.Context, jobID string) (*model.Job, error) {
+	var job model.Job
+	if err := c.do(ctx, http.MethodGet, c.endpoint("jobs", jobID), nil, &job); err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// CancelJob removes a job, abandoning its call if it has not finished
+func (c *Client) CancelJob(ctx context.Context, jobID string) error {
+	return c.do(ctx, http.MethodDelete, c.endpoint("jobs", jobID), nil, nil)
+}
+
+// WatchJob delivers the job from its Server-Sent Events stream once it
+// finishes. The returned channel is closed after the job is delivered, or
+// without it when ctx is done, the controller shuts down or the connection is lost.
+func (c *Client) WatchJob(ctx context.Context, jobID string) (<-chan *model.Job, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint("jobs", jobID, "events"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open job stream: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, newAPIError(resp)
+	}
+
+	jobs := make(chan *model.Job, 1)
+
+	go func() {
+		defer close(jobs)
+		defer resp.Body.Close()
+
+		scanEvents(resp.Body, func(eventType, data string) bool {
+			if eventType != "done" {
+				return eventType != "close"
+			}
+			var job model.Job
+			if err := json.Unmarshal([]byte(data), &job); err == nil {
+				jobs <- &job
+			}
+			return false
+		})
+	}()
+
+	return jobs, nil
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// Monitor delivers the messages of a bus selected by match rules, or every
+// message without rules, from the Server-Sent Events stream of the monitor.
+// The returned channel is closed when ctx is done, the controller closes the
+// monitor or the connection is lost.
+func (c *Client) Monitor(ctx context.Context, busType string, rules []string) (<-chan *model.MonitorMessage, error) {
+	endpoint := c.endpoint("buses", busType, "monitor")
+	if len(rules) > 0 {
+		endpoint += "?" + url.Values{"match": rules}.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open monitor stream: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, newAPIError(resp)
+	}
+
+	messages := make(chan *model.MonitorMessage)
+
+	go func() {
+		defer close(messages)
+		defer resp.Body.Close()
+
+		scanEvents(resp.Body, func(eventType, data string) bool {
+			if eventType == "close" {
+				return false
+			}
+			var message model.MonitorMessage
+			if data != "" && json.Unmarshal([]byte(data), &message) == nil {
+				select {
+				case messages <- &message:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		})
+	}()
+
+	return messages, nil
+}
+
+// Capture records the messages of a bus selected by match rules, or every
+// message without rules, for duration and copies the pcap file sent by the
+// controller to w as it is received. The stats are read from the trailers
+// of the response.
+func (c *Client) Capture(ctx context.Context, busType string, rules []string, duration time.Duration, w io.Writer) (*model.CaptureStats, error) {
+	query := url.Values{"duration": {duration.String()}}
+	if len(rules) > 0 {
+		query["match"] = rules
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint("buses", busType, "capture")+"?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start capture: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, newAPIError(resp)
+	}
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return nil, fmt.Errorf("failed to read capture: %w", err)
+	}
+
+	stats := &model.CaptureStats{}
+	stats.Messages, _ = strconv.Atoi(resp.Trailer.Get("Capture-Messages"))
+	stats.Dropped, _ = strconv.ParseUint(resp.Trailer.Get("Capture-Dropped"), 10, 64)
+	return stats, nil
+}
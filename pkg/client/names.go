@@ -0,0 +1,109 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// maxWait is the longest wait for a name accepted by the controller in one request
+const maxWait = 5 * time.Minute
+
+// WatchNameOwners delivers the name owner changes of a bus from their
+// Server-Sent Events stream, selected by a name pattern with shell wildcards.
+// The returned channel is closed when ctx is done, the controller shuts down
+// or the connection is lost.
+func (c *Client) WatchNameOwners(ctx context.Context, busType, pattern string, unique bool) (<-chan *model.NameOwnerEvent, error) {
+	query := url.Values{}
+	if pattern != "" {
+		query.Set("pattern", pattern)
+	}
+	if unique {
+		query.Set("unique", "true")
+	}
+	endpoint := c.endpoint("buses", busType, "names", "events")
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open name owner stream: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, newAPIError(resp)
+	}
+
+	events := make(chan *model.NameOwnerEvent)
+
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		scanEvents(resp.Body, func(eventType, data string) bool {
+			if eventType == "close" {
+				return false
+			}
+			var event model.NameOwnerEvent
+			if data != "" && json.Unmarshal([]byte(data), &event) == nil {
+				select {
+				case events <- &event:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			return true
+		})
+	}()
+
+	return events, nil
+}
+
+// WaitForName waits until a name has an owner and returns the owner. Waits
+// longer than the controller allows in one request are split in several.
+func (c *Client) WaitForName(ctx context.Context, busType, name string) (string, error) {
+	for {
+		timeout := maxWait
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+			timeout = time.Until(deadline)
+		}
+		if timeout < time.Millisecond {
+			return "", context.DeadlineExceeded
+		}
+
+		query := url.Values{"wait": {name}, "timeout": {timeout.Round(time.Millisecond).String()}}
+		err := c.do(ctx, http.MethodGet, c.endpoint("buses", busType, "services")+"?"+query.Encode(), nil, nil)
+
+		var apiErr *APIError
+		switch {
+		case err == nil:
+			queue, err := c.ListQueuedOwners(ctx, busType, name)
+			if err != nil {
+				return "", err
+			}
+			if len(queue.Owners) > 0 {
+				return queue.Owners[0], nil
+			}
+			// The name went away again; keep waiting
+		case errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusRequestTimeout:
+			if ctx.Err() != nil {
+				return "", ctx.Err()
+			}
+		default:
+			return "", err
+		}
+	}
+}
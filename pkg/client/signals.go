@@ -0,0 +1,88 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/mesbrj/dbus-controller/pkg/model"
+)
+
+// StreamSubscription delivers the signals of a subscription from its Server-Sent Events stream.
+// The returned channel is closed when ctx is done, the subscription is removed,
+// the controller shuts down or the connection is lost.
+func (c *Client) StreamSubscription(ctx context.Context, subscriptionID string) (<-chan *model.SignalEvent, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.endpoint("subscriptions", subscriptionID, "events"), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open signal stream: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, newAPIError(resp)
+	}
+
+	events := make(chan *model.SignalEvent)
+
+	go func() {
+		defer close(events)
+		defer resp.Body.Close()
+
+		scanEvents(resp.Body, func(eventType, data string) bool {
+			if eventType == "close" {
+				return false
+			}
+			if eventType == "signal" && data != "" {
+				var event model.SignalEvent
+				if err := json.Unmarshal([]byte(data), &event); err == nil {
+					select {
+					case events <- &event:
+					case <-ctx.Done():
+						return false
+					}
+				}
+			}
+			return true
+		})
+	}()
+
+	return events, nil
+}
+
+// scanEvents reads a Server-Sent Events stream and passes each event to
+// handle until handle returns false or the stream ends
+func scanEvents(r io.Reader, handle func(eventType, data string) bool) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+
+	var eventType, data string
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			// A blank line dispatches the event
+			if eventType != "" || data != "" {
+				if !handle(eventType, data) {
+					return
+				}
+			}
+			eventType, data = "", ""
+		case strings.HasPrefix(line, ":"):
+			// Comment used as keep-alive
+		case strings.HasPrefix(line, "event:"):
+			eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// BusInfo represents information about a D-Bus
+type BusInfo struct {
+	Type        string `json:"type"`
+	Description string `json:"description"`
+}
+
+// ServiceInfo represents information about a D-Bus service
+type ServiceInfo struct {
+	Name    string   `json:"name"`
+	Owner   string   `json:"owner,omitempty"`
+	Aliases []string `json:"aliases,omitempty"` // Other well-known names of the owner
+	// Not every name was looked up on a busy bus, so Aliases may be incomplete
+	AliasesTruncated bool                   `json:"aliases_truncated,omitempty"`
+	Credentials      *ConnectionCredentials `json:"credentials,omitempty"`
+	Process          *ProcessInfo           `json:"process,omitempty"`
+	Interfaces       []string               `json:"interfaces,omitempty"`
+	ObjectPaths      []string               `json:"object_paths,omitempty"`
+	Introspection    *IntrospectionResult   `json:"introspection,omitempty"`
+}
+
+// ConnectionCredentials are the credentials of a connection as reported by the bus
+type ConnectionCredentials struct {
+	UnixUserID         *uint32  `json:"unix_user_id,omitempty"`
+	UnixGroupIDs       []uint32 `json:"unix_group_ids,omitempty"`
+	ProcessID          *uint32  `json:"process_id,omitempty"`
+	LinuxSecurityLabel string   `json:"linux_security_label,omitempty"` // SELinux context or AppArmor profile
+}
+
+// ProcessInfo describes the process of a connection, read from /proc
+type ProcessInfo struct {
+	PID       uint32     `json:"pid"`
+	Cmdline   []string   `json:"cmdline,omitempty"`
+	Exe       string     `json:"exe,omitempty"`    // Unreadable for processes of other users without privileges
+	Cgroup    string     `json:"cgroup,omitempty"` // Unified hierarchy path, e.g. the systemd unit or container scope
+	StartTime *time.Time `json:"start_time,omitempty"`
+}
+
+// NameInfo is a name on a bus, owned by a running connection or started on
+// demand by the bus
+type NameInfo struct {
+	Name        string `json:"name"`
+	Running     bool   `json:"running"`
+	Activatable bool   `json:"activatable"`
+}
+
+// ServiceStart is the outcome of starting a service by name
+type ServiceStart struct {
+	Name  string `json:"name"`
+	Reply string `json:"reply"`           // success or already_running
+	Owner string `json:"owner,omitempty"` // Unique name of the started service
+}
+
+// ActivationEnvironment holds variables added to or replaced in the
+// environment of activated services
+type ActivationEnvironment struct {
+	Environment map[string]string `json:"environment"`
+}
+
+// NameFlags are the flags of a request for a well-known name
+type NameFlags struct {
+	AllowReplacement bool `json:"allow_replacement,omitempty"` // Let another connection take the name over
+	ReplaceExisting  bool `json:"replace_existing,omitempty"`  // Take the name over if its owner allows replacement
+	DoNotQueue       bool `json:"do_not_queue,omitempty"`      // Fail instead of queueing when the name is owned
+}
+
+// NameOwnership is the outcome of requesting or releasing a well-known name
+// on the connection of the controller
+type NameOwnership struct {
+	Name       string `json:"name"`
+	Reply      string `json:"reply"`           // primary_owner, in_queue, exists or already_owner; released, non_existent or not_owner
+	Owner      string `json:"owner,omitempty"` // Primary owner after the request
+	Connection string `json:"connection"`      // Unique name of the controller connection
+}
+
+// NameQueue lists the connections queued for a well-known name
+type NameQueue struct {
+	Name       string   `json:"name"`
+	Owners     []string `json:"owners"`     // Unique names, the primary owner first
+	Connection string   `json:"connection"` // Unique name of the controller connection
+}
+
+// NameOwnerEvent represents a change of the owner of a bus name. Change is
+// appeared, disappeared or replaced for the changes seen on the bus, and
+// acquired or lost for the names of the controller connection.
+type NameOwnerEvent struct {
+	Bus        string    `json:"bus"`
+	Name       string    `json:"name"`
+	Change     string    `json:"change"`
+	OldOwner   string    `json:"old_owner,omitempty"`
+	NewOwner   string    `json:"new_owner,omitempty"`
+	ReceivedAt time.Time `json:"received_at"`
+}
+
+// InterfaceInfo represents information about a D-Bus interface
+type InterfaceInfo struct {
+	Name       string         `json:"name"`
+	Methods    []MethodInfo   `json:"methods,omitempty"`
+	Properties []PropertyInfo `json:"properties,omitempty"`
+	Signals    []SignalInfo   `json:"signals,omitempty"`
+}
+
+// MethodInfo represents information about a D-Bus method
+type MethodInfo struct {
+	Name        string            `json:"name"`
+	InArgs      []ArgumentInfo    `json:"in_args,omitempty"`
+	OutArgs     []ArgumentInfo    `json:"out_args,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// ArgumentInfo represents information about a method argument
+type ArgumentInfo struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Direction string `json:"direction"` // "in" or "out"
+}
+
+// PropertyInfo represents information about a D-Bus property
+type PropertyInfo struct {
+	Name        string            `json:"name"`
+	Type        string            `json:"type"`
+	Access      string            `json:"access"` // "read", "write", "readwrite"
+	Value       interface{}       `json:"value,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// SignalInfo represents information about a D-Bus signal
+type SignalInfo struct {
+	Name        string            `json:"name"`
+	Args        []ArgumentInfo    `json:"args,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// MethodCallResult represents the result of a D-Bus method call
+type MethodCallResult struct {
+	Success      bool          `json:"success"`
+	ReturnValues []interface{} `json:"return_values,omitempty"`
+	Error        string        `json:"error,omitempty"`
+	ErrorName    string        `json:"error_name,omitempty"` // D-Bus error name of a failed call
+	NoReply      bool          `json:"no_reply,omitempty"`   // Sent flagged NoReplyExpected, without waiting for a reply
+	Timestamp    time.Time     `json:"timestamp"`
+}
+
+// CallFlags holds the header flags requested for a method call
+type CallFlags struct {
+	NoReply                       bool `json:"no_reply,omitempty"`                        // Do not wait for a reply (NoReplyExpected)
+	NoAutoStart                   bool `json:"no_auto_start,omitempty"`                   // Do not activate the service when it is not running
+	AllowInteractiveAuthorization bool `json:"allow_interactive_authorization,omitempty"` // Let the service prompt the user, e.g. through polkit
+}
+
+// Job represents a method call run in the background. Result is set once the
+// call finished; the job is kept for a while afterwards.
+type Job struct {
+	ID         string            `json:"id"`
+	Status     string            `json:"status"` // "pending", "running", "succeeded", "failed" or "abandoned"
+	BusType    string            `json:"bus_type"`
+	Service    string            `json:"service"`
+	Path       string            `json:"path"`
+	Interface  string            `json:"interface"`
+	Method     string            `json:"method"`
+	Result     *MethodCallResult `json:"result,omitempty"`
+	CreatedAt  time.Time         `json:"created_at"`
+	StartedAt  *time.Time        `json:"started_at,omitempty"`
+	FinishedAt *time.Time        `json:"finished_at,omitempty"`
+}
+
+// PropertyValue represents a D-Bus property value
+type PropertyValue struct {
+	Name      string      `json:"name"`
+	Type      string      `json:"type"`
+	Value     interface{} `json:"value"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// SignalSubscription represents a D-Bus signal subscription
+type SignalSubscription struct {
+	ID        string    `json:"id"`
+	BusType   string    `json:"bus_type"`
+	Service   string    `json:"service"`
+	Interface string    `json:"interface"`
+	Signal    string    `json:"signal"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// EmitSignalRequest represents a signal emitted from the connection of the
+// controller. Args holds JSON values, typed by Signature when given;
+// alternatively TextArgs holds the arguments as text, as in a method call request.
+type EmitSignalRequest struct {
+	Path        string        `json:"path"`
+	Interface   string        `json:"interface"`
+	Member      string        `json:"member"`
+	Destination string        `json:"destination,omitempty"` // Bus name receiving the signal; broadcast when empty
+	Signature   string        `json:"signature,omitempty"`
+	Args        []interface{} `json:"args,omitempty"`
+	Syntax      string        `json:"syntax,omitempty"`
+	TextArgs    []string      `json:"text_args,omitempty"`
+}
+
+// SignalEvent represents a signal delivered to a subscription stream
+type SignalEvent struct {
+	SubscriptionID string        `json:"subscription_id"`
+	Sender         string        `json:"sender"`
+	Path           string        `json:"path"`
+	Interface      string        `json:"interface"`
+	Signal         string        `json:"signal"`
+	Body           []interface{} `json:"body"`
+	ReceivedAt     time.Time     `json:"received_at"`
+}
+
+// MonitorMessage represents a message seen on a bus by a monitor. The body
+// is encoded like named arguments, with variants as signature and value.
+type MonitorMessage struct {
+	Type        string        `json:"type"` // method_call, method_return, error or signal
+	Serial      uint32        `json:"serial"`
+	ReplySerial uint32        `json:"reply_serial,omitempty"`
+	Sender      string        `json:"sender,omitempty"`
+	Destination string        `json:"destination,omitempty"`
+	Path        string        `json:"path,omitempty"`
+	Interface   string        `json:"interface,omitempty"`
+	Member      string        `json:"member,omitempty"`
+	ErrorName   string        `json:"error_name,omitempty"`
+	Signature   string        `json:"signature,omitempty"`
+	Body        []interface{} `json:"body,omitempty"`
+	Dropped     uint64        `json:"dropped,omitempty"` // Messages dropped before this one, the buffer of the monitor being full
+	ReceivedAt  time.Time     `json:"received_at"`
+}
+
+// CaptureStats counts the messages of a capture
+type CaptureStats struct {
+	Messages int    `json:"messages"`          // Messages written to the capture
+	Dropped  uint64 `json:"dropped,omitempty"` // Messages dropped, the buffer of the monitor being full or the message not encoding
+}
+
+// IntrospectionResult represents the result of D-Bus introspection
+type IntrospectionResult struct {
+	Service    string               `json:"service"`
+	ObjectPath string               `json:"object_path"`
+	XML        string               `json:"xml"`
+	ParsedData *ParsedIntrospection `json:"parsed_data,omitempty"`
+	Timestamp  time.Time            `json:"timestamp"`
+}
+
+// ParsedIntrospection represents parsed introspection data
+type ParsedIntrospection struct {
+	Interfaces []InterfaceInfo `json:"interfaces"`
+	Nodes      []NodeInfo      `json:"nodes,omitempty"`
+}
+
+// NodeInfo represents a D-Bus node
+type NodeInfo struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// HealthStatus represents the liveness of the controller process
+type HealthStatus struct {
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// BusHealth represents the connectivity of a D-Bus
+type BusHealth struct {
+	Type      string `json:"type"`
+	Required  bool   `json:"required"`
+	Connected bool   `json:"connected"`
+	ID        string `json:"id,omitempty"`
+	Latency   string `json:"latency,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// ReadinessStatus represents the readiness of the controller to serve D-Bus requests
+type ReadinessStatus struct {
+	Ready     bool        `json:"ready"`
+	Buses     []BusHealth `json:"buses"`
+	Timestamp time.Time   `json:"timestamp"`
+}
+
+// ErrorResponse represents an API error response
+type ErrorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+	Code    int    `json:"code,omitempty"`
+}
+
+// RPCRequest represents a JSON-RPC 2.0 request; a request without an ID is a notification
+type RPCRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+}
+
+// RPCResponse represents a JSON-RPC 2.0 response
+type RPCResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// RPCError represents the error of a JSON-RPC 2.0 response
+type RPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// RPCCallParams holds the params of the JSON-RPC "call" method: the target
+// method, its arguments and call flags, given as in a method call request
+type RPCCallParams struct {
+	Bus       string        `json:"bus"`
+	Service   string        `json:"service"`
+	Path      string        `json:"path,omitempty"` // Object path, / by default
+	Interface string        `json:"interface"`
+	Method    string        `json:"method"`
+	Args      []interface{} `json:"args,omitempty"`
+	Syntax    string        `json:"syntax,omitempty"`
+	Signature string        `json:"signature,omitempty"`
+	TextArgs  []string      `json:"text_args,omitempty"`
+	CallFlags
+}
+
+// BatchRequest represents an ordered list of D-Bus operations run by one request
+type BatchRequest struct {
+	Operations  []BatchOperation `json:"operations"`
+	StopOnError bool             `json:"stop_on_error,omitempty"` // Skip the operations following the first failure
+}
+
+// BatchOperation represents a method call ("call"), property read ("get") or
+// property write ("set") of a batch. Strings of Path, Args and Value may
+// reference the results of earlier operations as {results.ID} or {results.ID.N}.
+type BatchOperation struct {
+	ID          string        `json:"id,omitempty"` // Name of the result for later references
+	Op          string        `json:"op"`
+	Bus         string        `json:"bus"`
+	Service     string        `json:"service"`
+	Path        string        `json:"path,omitempty"` // Object path, / by default
+	Interface   string        `json:"interface"`
+	Method      string        `json:"method,omitempty"`
+	Property    string        `json:"property,omitempty"`
+	Args        []interface{} `json:"args,omitempty"`
+	Value       interface{}   `json:"value,omitempty"`
+	Signature   string        `json:"signature,omitempty"`   // Types of Args, or of Value
+	Independent bool          `json:"independent,omitempty"` // Run in parallel with the adjacent independent operations
+}
+
+// BatchResult represents the outcome of one operation of a batch
+type BatchResult struct {
+	ID        string      `json:"id,omitempty"`
+	Op        string      `json:"op"`
+	Status    string      `json:"status"` // "ok", "failed" or "skipped"
+	Result    interface{} `json:"result,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	ErrorName string      `json:"error_name,omitempty"` // D-Bus error name of a failed operation
+}
+
+// BatchResponse represents the results of a batch, in the order of its operations
+type BatchResponse struct {
+	Success bool          `json:"success"`
+	Results []BatchResult `json:"results"`
+}